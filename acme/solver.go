@@ -0,0 +1,183 @@
+// Package acme provides an ACME DNS-01 challenge solver backed by the Porkbun DNS API.
+//
+// The Solver implements the Present/CleanUp contract used by lego-style
+// challenge providers, so it can be plugged into any ACME client that
+// accepts such a provider.
+package acme
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	challengeLabel = "_acme-challenge"
+
+	// DefaultTTL is the TTL used for challenge records, which is the minimum accepted by Porkbun.
+	DefaultTTL = 600
+	// DefaultPropagationTimeout is the default time to wait for a challenge record to propagate.
+	DefaultPropagationTimeout = 10 * time.Minute
+	// DefaultPollingInterval is the default interval between propagation checks.
+	DefaultPollingInterval = 10 * time.Second
+)
+
+// SolverOptions defines the configuration options for the DNS-01 Solver.
+type SolverOptions struct {
+	Zone               string        // Porkbun domain holding the records, derived from the public suffix list if empty.
+	TTL                int           // TTL of the challenge records, defaults to DefaultTTL.
+	PropagationTimeout time.Duration // Reported by Timeout, defaults to DefaultPropagationTimeout.
+	PollingInterval    time.Duration // Reported by Timeout, defaults to DefaultPollingInterval.
+}
+
+// Solver solves ACME DNS-01 challenges by creating `_acme-challenge` TXT records through the DnsService.
+type Solver struct {
+	dns     porkbun.DNSAPI
+	options SolverOptions
+
+	mu      sync.Mutex
+	records map[challengeKey][]int64
+}
+
+// challengeKey identifies a single challenge record by its owner name and value.
+type challengeKey struct {
+	zone  string
+	name  string
+	value string
+}
+
+// NewSolver initializes a new DNS-01 Solver using the provided DNS API, usually client.Dns, and options.
+func NewSolver(dns porkbun.DNSAPI, options *SolverOptions) *Solver {
+	s := &Solver{
+		dns:     dns,
+		records: make(map[challengeKey][]int64),
+	}
+
+	if options != nil {
+		s.options = *options
+	}
+	if s.options.TTL <= 0 {
+		s.options.TTL = DefaultTTL
+	}
+	if s.options.PropagationTimeout <= 0 {
+		s.options.PropagationTimeout = DefaultPropagationTimeout
+	}
+	if s.options.PollingInterval <= 0 {
+		s.options.PollingInterval = DefaultPollingInterval
+	}
+
+	return s
+}
+
+// Present creates the TXT record that fulfils the DNS-01 challenge for the domain.
+func (s *Solver) Present(domain, token, keyAuth string) error {
+	return s.PresentContext(context.Background(), domain, token, keyAuth)
+}
+
+// PresentContext is like Present but uses the provided context for API calls.
+func (s *Solver) PresentContext(ctx context.Context, domain, token, keyAuth string) error {
+	key, err := s.challengeKey(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.dns.CreateRecord(ctx, key.zone, &porkbun.DnsRecord{
+		Name:    key.name,
+		Type:    porkbun.TXT,
+		Content: key.value,
+		TTL:     fmt.Sprint(s.options.TTL),
+	})
+	if err != nil {
+		return fmt.Errorf("acme: failed to create TXT record for %s: %w", domain, err)
+	}
+
+	s.mu.Lock()
+	s.records[key] = append(s.records[key], resp.ID)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// CleanUp deletes the TXT record created by Present for the same challenge.
+func (s *Solver) CleanUp(domain, token, keyAuth string) error {
+	return s.CleanUpContext(context.Background(), domain, token, keyAuth)
+}
+
+// CleanUpContext is like CleanUp but uses the provided context for API calls.
+func (s *Solver) CleanUpContext(ctx context.Context, domain, token, keyAuth string) error {
+	key, err := s.challengeKey(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	ids := s.records[key]
+	if len(ids) == 0 {
+		s.mu.Unlock()
+		return fmt.Errorf("acme: no TXT record found for %s", domain)
+	}
+	id := ids[len(ids)-1]
+	if len(ids) == 1 {
+		delete(s.records, key)
+	} else {
+		s.records[key] = ids[:len(ids)-1]
+	}
+	s.mu.Unlock()
+
+	if _, err := s.dns.DeleteRecord(ctx, key.zone, id); err != nil {
+		// Keep track of the record so a later CleanUp can retry the deletion
+		s.mu.Lock()
+		s.records[key] = append(s.records[key], id)
+		s.mu.Unlock()
+		return fmt.Errorf("acme: failed to delete TXT record %d for %s: %w", id, domain, err)
+	}
+
+	return nil
+}
+
+// Timeout returns the propagation timeout and polling interval for the challenge records.
+func (s *Solver) Timeout() (timeout, interval time.Duration) {
+	return s.options.PropagationTimeout, s.options.PollingInterval
+}
+
+// challengeKey resolves the zone, relative record name and record value for a challenge.
+func (s *Solver) challengeKey(domain, keyAuth string) (challengeKey, error) {
+	fqdn := porkbun.NormalizeName(strings.TrimPrefix(domain, "*."))
+	if fqdn == "" {
+		return challengeKey{}, errors.New("acme: domain must not be empty")
+	}
+
+	zone := porkbun.NormalizeName(s.options.Zone)
+	if zone == "" {
+		var err error
+		zone, err = publicsuffix.EffectiveTLDPlusOne(fqdn)
+		if err != nil {
+			return challengeKey{}, fmt.Errorf("acme: unable to determine zone for %s: %w", domain, err)
+		}
+	}
+
+	name := challengeLabel
+	switch {
+	case fqdn == zone:
+	case strings.HasSuffix(fqdn, "."+zone):
+		name += "." + strings.TrimSuffix(fqdn, "."+zone)
+	default:
+		return challengeKey{}, fmt.Errorf("acme: domain %s is not part of zone %s", domain, zone)
+	}
+
+	return challengeKey{zone: zone, name: name, value: ChallengeValue(keyAuth)}, nil
+}
+
+// ChallengeValue returns the TXT record value for the given key authorization.
+func ChallengeValue(keyAuth string) string {
+	sum := sha256.Sum256([]byte(keyAuth))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
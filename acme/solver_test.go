@@ -0,0 +1,164 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+)
+
+// setupSolver returns a Solver backed by a porkbuntest.FakeDNS managing the domains.
+func setupSolver(options *SolverOptions, domains ...string) (*Solver, *porkbuntest.FakeDNS) {
+	dns := porkbuntest.NewFakeDNS(domains...)
+	return NewSolver(dns, options), dns
+}
+
+func TestChallengeValue(t *testing.T) {
+	// base64url(sha256(keyAuth)) without padding, as defined in RFC 8555 section 8.4
+	assert.Equal(t, "61rBZ_4knHblO0MNoxFsXZ_eTFUHum0B6IVRbhvUn5I", ChallengeValue("token.thumbprint"))
+}
+
+func TestSolver_PresentAndCleanUp(t *testing.T) {
+	solver, dns := setupSolver(nil, "example.com")
+
+	err := solver.Present("www.example.com", "token", "keyAuth")
+	assert.NoError(t, err)
+
+	if records := dns.Records("example.com"); assert.Len(t, records, 1) {
+		assert.Equal(t, "_acme-challenge.www.example.com", records[0].Name)
+		assert.Equal(t, porkbun.TXT, records[0].Type)
+		assert.Equal(t, ChallengeValue("keyAuth"), records[0].Content)
+		assert.Equal(t, "600", records[0].TTL)
+	}
+
+	err = solver.CleanUp("www.example.com", "token", "keyAuth")
+	assert.NoError(t, err)
+	assert.Empty(t, dns.Records("example.com"))
+}
+
+func TestSolver_ApexAndWildcard(t *testing.T) {
+	solver, dns := setupSolver(nil, "example.co.uk")
+
+	assert.NoError(t, solver.Present("example.co.uk", "token1", "keyAuth1"))
+	assert.NoError(t, solver.Present("*.example.co.uk", "token2", "keyAuth2"))
+
+	records := dns.Records("example.co.uk")
+	assert.Len(t, records, 2)
+	for _, record := range records {
+		assert.Equal(t, "_acme-challenge.example.co.uk", record.Name)
+	}
+
+	// Cleaning up one challenge must leave the other in place
+	assert.NoError(t, solver.CleanUp("example.co.uk", "token1", "keyAuth1"))
+	if records := dns.Records("example.co.uk"); assert.Len(t, records, 1) {
+		assert.Equal(t, ChallengeValue("keyAuth2"), records[0].Content)
+	}
+}
+
+func TestSolver_Zone(t *testing.T) {
+	solver, dns := setupSolver(&SolverOptions{Zone: "dev.example.com."}, "dev.example.com")
+
+	assert.NoError(t, solver.Present("api.dev.example.com", "token", "keyAuth"))
+	if records := dns.Records("dev.example.com"); assert.Len(t, records, 1) {
+		assert.Equal(t, "_acme-challenge.api.dev.example.com", records[0].Name)
+	}
+
+	err := solver.Present("example.org", "token", "keyAuth")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not part of zone")
+}
+
+func TestSolver_ConcurrentSameName(t *testing.T) {
+	solver, dns := setupSolver(nil, "example.com")
+
+	const n = 10
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, solver.Present("example.com", "token", fmt.Sprintf("keyAuth%d", i)))
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, dns.Records("example.com"), n)
+
+	// Clean up half of the challenges and make sure exactly those values are removed
+	for i := 0; i < n; i += 2 {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, solver.CleanUp("example.com", "token", fmt.Sprintf("keyAuth%d", i)))
+		}(i)
+	}
+	wg.Wait()
+
+	remaining := map[string]bool{}
+	for _, record := range dns.Records("example.com") {
+		remaining[record.Content] = true
+	}
+	assert.Len(t, remaining, n/2)
+	for i := 1; i < n; i += 2 {
+		assert.True(t, remaining[ChallengeValue(fmt.Sprintf("keyAuth%d", i))])
+	}
+}
+
+func TestSolver_DuplicatePresent(t *testing.T) {
+	solver, dns := setupSolver(nil, "example.com")
+
+	assert.NoError(t, solver.Present("example.com", "token", "keyAuth"))
+	assert.NoError(t, solver.Present("example.com", "token", "keyAuth"))
+	assert.Len(t, dns.Records("example.com"), 2)
+
+	assert.NoError(t, solver.CleanUp("example.com", "token", "keyAuth"))
+	assert.Len(t, dns.Records("example.com"), 1)
+	assert.NoError(t, solver.CleanUp("example.com", "token", "keyAuth"))
+	assert.Empty(t, dns.Records("example.com"))
+}
+
+func TestSolver_CleanUpUnknown(t *testing.T) {
+	solver, _ := setupSolver(nil, "example.com")
+
+	err := solver.CleanUp("example.com", "token", "keyAuth")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no TXT record found")
+}
+
+func TestSolver_CreateError(t *testing.T) {
+	// The fake does not manage the domain, so the create call fails
+	solver, _ := setupSolver(nil)
+
+	err := solver.Present("example.com", "token", "keyAuth")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), porkbuntest.MsgInvalidDomain)
+}
+
+func TestSolver_DeleteErrorKeepsRecord(t *testing.T) {
+	solver, dns := setupSolver(nil, "example.com")
+
+	assert.NoError(t, solver.Present("example.com", "token", "keyAuth"))
+
+	dns.Err = errors.New("connection reset")
+	assert.Error(t, solver.CleanUp("example.com", "token", "keyAuth"))
+	dns.Err = nil
+
+	assert.NoError(t, solver.CleanUp("example.com", "token", "keyAuth"))
+	assert.Empty(t, dns.Records("example.com"))
+}
+
+func TestSolver_Timeout(t *testing.T) {
+	solver := NewSolver(nil, nil)
+	timeout, interval := solver.Timeout()
+	assert.Equal(t, DefaultPropagationTimeout, timeout)
+	assert.Equal(t, DefaultPollingInterval, interval)
+
+	solver = NewSolver(nil, &SolverOptions{PropagationTimeout: time.Minute, PollingInterval: time.Second})
+	timeout, interval = solver.Timeout()
+	assert.Equal(t, time.Minute, timeout)
+	assert.Equal(t, time.Second, interval)
+}
@@ -0,0 +1,44 @@
+package porkbun
+
+import "context"
+
+// DNSAPI is the set of DNS record operations provided by DnsService. Code that only manages records
+// can accept a DNSAPI, so it can be tested against an in-memory fake instead of the HTTP API.
+type DNSAPI interface {
+	GetRecords(ctx context.Context, domain string, recordId *int64) (*GetRecordsResponse, error)
+	GetRecordsByType(ctx context.Context, domain string, recordType DnsRecordType, subdomain *string) (*GetRecordsResponse, error)
+	CreateRecord(ctx context.Context, domain string, record *DnsRecord) (*CreateRecordResponse, error)
+	EditRecord(ctx context.Context, domain string, recordId int64, record *EditRecord) (*EditRecordResponse, error)
+	EditRecordByType(ctx context.Context, domain string, recordType DnsRecordType, subdomain *string, record *EditTypeRecord) (*EditRecordResponse, error)
+	DeleteRecord(ctx context.Context, domain string, recordId int64) (*DeleteRecordResponse, error)
+	DeleteRecordByType(ctx context.Context, domain string, recordType DnsRecordType, subdomain *string) (*DeleteRecordResponse, error)
+}
+
+// DomainsAPI is the set of domain operations provided by DomainsService.
+type DomainsAPI interface {
+	ListDomains(ctx context.Context, options *DomainListOptions) (*ListDomainsResponse, error)
+	ListAllDomains(ctx context.Context, options *DomainListOptions) ([]Domain, error)
+	GetNameServers(ctx context.Context, domain string) (*GetNameServersResponse, error)
+	UpdateNameServers(ctx context.Context, domain string, newNameservers *NameServers) (*UpdateNameServersResponse, error)
+	GetDomainURLForwarding(ctx context.Context, domain string) (*GetDomainURLForwardingResponse, error)
+	AddDomainUrlForward(ctx context.Context, domain string, forwardAttributes *UrlForward) (*AddDomainUrlForwardResponse, error)
+	DeleteDomainUrlForward(ctx context.Context, domain string, recordId string) (*DeleteDomainUrlForwardResponse, error)
+}
+
+// SSLAPI is the set of SSL operations provided by SslService.
+type SSLAPI interface {
+	Retrieve(ctx context.Context, domain string) (*SslRetrieveResponse, error)
+}
+
+// PricingAPI is the set of pricing operations provided by PricingService.
+type PricingAPI interface {
+	ListPricing(ctx context.Context) (*PricingResponse, error)
+}
+
+// Ensure the services implement their interfaces.
+var (
+	_ DNSAPI     = (*DnsService)(nil)
+	_ DomainsAPI = (*DomainsService)(nil)
+	_ SSLAPI     = (*SslService)(nil)
+	_ PricingAPI = (*PricingService)(nil)
+)
@@ -0,0 +1,250 @@
+// Package certmanager serves Porkbun SSL certificates to crypto/tls servers, refreshing them in the
+// background before they expire.
+package certmanager
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+const (
+	// DefaultRefreshBefore is how long before NotAfter a certificate is considered due for renewal.
+	DefaultRefreshBefore = 30 * 24 * time.Hour
+	// DefaultRefreshInterval is the longest time between two retrievals of a certificate.
+	DefaultRefreshInterval = 24 * time.Hour
+	// DefaultRetryInterval is the wait before retrying a failed or premature refresh.
+	DefaultRetryInterval = 15 * time.Minute
+)
+
+// ErrNoCertificate is returned by GetCertificate when no certificate matches the requested server name.
+var ErrNoCertificate = errors.New("certmanager: no certificate for server name")
+
+// Options defines the configuration options for the Manager.
+type Options struct {
+	Domains         []string      // Porkbun domains whose certificates are served.
+	RefreshBefore   time.Duration // Refresh window before NotAfter, defaults to DefaultRefreshBefore.
+	RefreshInterval time.Duration // Longest time between retrievals, defaults to DefaultRefreshInterval.
+	RetryInterval   time.Duration // Wait after a failed refresh, defaults to DefaultRetryInterval.
+	Logger          *log.Logger   // Logger for refresh results and errors, discarded if nil.
+}
+
+// entry is the cached certificate of a domain.
+type entry struct {
+	cert *tls.Certificate
+	next time.Time // When the certificate is refreshed next
+}
+
+// Manager retrieves certificates through SslService.Retrieve, caches them and serves them by SNI.
+type Manager struct {
+	ssl     porkbun.SSLAPI
+	options Options
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+
+	// now and after are replaced in tests.
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// NewManager initializes a new Manager using the provided SSL API, usually client.Ssl, and options.
+func NewManager(ssl porkbun.SSLAPI, options *Options) *Manager {
+	m := &Manager{
+		ssl:     ssl,
+		entries: make(map[string]*entry),
+		now:     time.Now,
+		after:   time.After,
+	}
+
+	if options != nil {
+		m.options = *options
+	}
+	if m.options.RefreshBefore <= 0 {
+		m.options.RefreshBefore = DefaultRefreshBefore
+	}
+	if m.options.RefreshInterval <= 0 {
+		m.options.RefreshInterval = DefaultRefreshInterval
+	}
+	if m.options.RetryInterval <= 0 {
+		m.options.RetryInterval = DefaultRetryInterval
+	}
+	if m.options.Logger == nil {
+		m.options.Logger = log.New(io.Discard, "", 0)
+	}
+
+	domains := make([]string, len(m.options.Domains))
+	for i, domain := range m.options.Domains {
+		domains[i] = porkbun.NormalizeName(domain)
+	}
+	m.options.Domains = domains
+
+	return m
+}
+
+// Load retrieves the certificates of all domains. It returns an error listing the domains
+// that could not be loaded; certificates that were loaded are served regardless.
+func (m *Manager) Load(ctx context.Context) error {
+	var errs []error
+	for _, domain := range m.options.Domains {
+		if err := m.refresh(ctx, domain); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run refreshes certificates in the background until the context is cancelled.
+// Certificates that have not been loaded yet are retrieved immediately.
+func (m *Manager) Run(ctx context.Context) error {
+	for {
+		now := m.now()
+		next := now.Add(m.options.RefreshInterval)
+
+		for _, domain := range m.options.Domains {
+			due := m.nextRefresh(domain)
+			if !due.After(now) {
+				if err := m.refresh(ctx, domain); err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					m.options.Logger.Print(err)
+				}
+				due = m.nextRefresh(domain)
+			}
+			if due.Before(next) {
+				next = due
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-m.after(next.Sub(now)):
+		}
+	}
+}
+
+// GetCertificate returns the certificate for the server name of the TLS handshake.
+// It is meant to be used as tls.Config.GetCertificate.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := porkbun.NormalizeName(hello.ServerName)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Without SNI, serve the certificate of the first domain that has one
+	if name == "" {
+		for _, domain := range m.options.Domains {
+			if e := m.entries[domain]; e != nil && e.cert != nil {
+				return e.cert, nil
+			}
+		}
+		return nil, ErrNoCertificate
+	}
+
+	if e := m.entries[name]; e != nil && e.cert != nil {
+		return e.cert, nil
+	}
+	for _, domain := range m.options.Domains {
+		if e := m.entries[domain]; e != nil && e.cert != nil && e.cert.Leaf.VerifyHostname(name) == nil {
+			return e.cert, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w %q", ErrNoCertificate, name)
+}
+
+// TLSConfig returns a tls.Config that serves the managed certificates.
+func (m *Manager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: m.GetCertificate,
+	}
+}
+
+// Certificate returns the cached certificate of the domain, if any.
+func (m *Manager) Certificate(domain string) (*tls.Certificate, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e := m.entries[porkbun.NormalizeName(domain)]
+	if e == nil || e.cert == nil {
+		return nil, false
+	}
+	return e.cert, true
+}
+
+// refresh retrieves the certificate of the domain and schedules its next refresh.
+// On failure the previous certificate is kept and the refresh is retried after RetryInterval.
+func (m *Manager) refresh(ctx context.Context, domain string) error {
+	cert, err := m.retrieve(ctx, domain)
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.entries[domain]
+	if e == nil {
+		e = &entry{}
+		m.entries[domain] = e
+	}
+
+	if err != nil {
+		e.next = now.Add(m.options.RetryInterval)
+		return fmt.Errorf("certmanager: refreshing %s: %w", domain, err)
+	}
+
+	if e.cert == nil || !e.cert.Leaf.Equal(cert.Leaf) {
+		m.options.Logger.Printf("loaded certificate for %s, valid until %s", domain, cert.Leaf.NotAfter.Format(time.RFC3339))
+	}
+	e.cert = cert
+
+	// Refresh on the regular interval, or once the certificate enters the renewal window.
+	// Inside the window Porkbun may not have renewed yet, so keep retrying.
+	e.next = now.Add(m.options.RefreshInterval)
+	if renew := cert.Leaf.NotAfter.Add(-m.options.RefreshBefore); renew.Before(e.next) {
+		e.next = renew
+	}
+	if !e.next.After(now) {
+		e.next = now.Add(m.options.RetryInterval)
+	}
+
+	return nil
+}
+
+// retrieve fetches and parses the certificate of the domain.
+func (m *Manager) retrieve(ctx context.Context, domain string) (*tls.Certificate, error) {
+	resp, err := m.ssl.Retrieve(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := resp.TLSCertificate()
+	if err != nil {
+		return nil, err
+	}
+	if !m.now().Before(cert.Leaf.NotAfter) {
+		return nil, fmt.Errorf("certificate expired at %s", cert.Leaf.NotAfter.Format(time.RFC3339))
+	}
+
+	return &cert, nil
+}
+
+// nextRefresh returns when the domain's certificate is refreshed next, the zero time if never loaded.
+func (m *Manager) nextRefresh(domain string) time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if e := m.entries[domain]; e != nil {
+		return e.next
+	}
+	return time.Time{}
+}
@@ -0,0 +1,204 @@
+package certmanager
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/internal/testcert"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+)
+
+// countingSSL counts the calls to Retrieve.
+type countingSSL struct {
+	*porkbuntest.FakeSSL
+	calls atomic.Int32
+}
+
+func (c *countingSSL) Retrieve(ctx context.Context, domain string) (*porkbun.SslRetrieveResponse, error) {
+	c.calls.Add(1)
+	return c.FakeSSL.Retrieve(ctx, domain)
+}
+
+// set makes the fake return the bundle as the certificate of the domain.
+func set(ssl *porkbuntest.FakeSSL, domain string, bundle *testcert.Bundle) {
+	ssl.SetSSL(domain, porkbun.SslRetrieveResponse{
+		Certificatechain: bundle.CertificateChain,
+		Privatekey:       bundle.PrivateKey,
+		Publickey:        bundle.PublicKey,
+	})
+}
+
+func generate(t *testing.T, domain string, notAfter time.Time) *testcert.Bundle {
+	bundle, err := testcert.Generate([]string{domain, "*." + domain}, notAfter.Add(-90*24*time.Hour), notAfter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bundle
+}
+
+func setupManager(options *Options) (*Manager, *porkbuntest.FakeSSL) {
+	ssl := porkbuntest.NewFakeSSL()
+	return NewManager(ssl, options), ssl
+}
+
+func TestManager_GetCertificate(t *testing.T) {
+	manager, ssl := setupManager(&Options{Domains: []string{"example.com", "Example.ORG."}})
+	expires := time.Now().Add(60 * 24 * time.Hour)
+	set(ssl, "example.com", generate(t, "example.com", expires))
+	set(ssl, "example.org", generate(t, "example.org", expires))
+
+	assert.NoError(t, manager.Load(context.Background()))
+
+	com, ok := manager.Certificate("example.com")
+	assert.True(t, ok)
+	org, ok := manager.Certificate("example.org")
+	assert.True(t, ok)
+
+	for name, want := range map[string]*tls.Certificate{
+		"example.com":      com,
+		"www.example.com":  com,
+		"EXAMPLE.ORG.":     org,
+		"mail.example.org": org,
+		"":                 com,
+	} {
+		cert, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: name})
+		assert.NoError(t, err, name)
+		assert.Same(t, want, cert, name)
+	}
+
+	_, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "a.b.example.com"})
+	assert.True(t, errors.Is(err, ErrNoCertificate))
+	_, err = manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.net"})
+	assert.True(t, errors.Is(err, ErrNoCertificate))
+}
+
+func TestManager_LoadErrors(t *testing.T) {
+	manager, ssl := setupManager(&Options{Domains: []string{"example.com", "example.org"}})
+	set(ssl, "example.com", generate(t, "example.com", time.Now().Add(60*24*time.Hour)))
+	set(ssl, "example.org", generate(t, "example.org", time.Now().Add(-time.Hour)))
+
+	err := manager.Load(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "refreshing example.org: certificate expired")
+
+	_, ok := manager.Certificate("example.com")
+	assert.True(t, ok)
+	_, ok = manager.Certificate("example.org")
+	assert.False(t, ok)
+
+	_, err = manager.GetCertificate(&tls.ClientHelloInfo{})
+	assert.NoError(t, err)
+}
+
+func TestManager_RefreshKeepsCertificateOnFailure(t *testing.T) {
+	manager, ssl := setupManager(&Options{Domains: []string{"example.com"}, RetryInterval: time.Minute})
+	now := time.Now()
+	manager.now = func() time.Time { return now }
+
+	set(ssl, "example.com", generate(t, "example.com", now.Add(60*24*time.Hour)))
+	assert.NoError(t, manager.Load(context.Background()))
+	before, _ := manager.Certificate("example.com")
+
+	ssl.Err = errors.New("connection reset")
+	err := manager.refresh(context.Background(), "example.com")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "connection reset")
+
+	after, ok := manager.Certificate("example.com")
+	assert.True(t, ok)
+	assert.Same(t, before, after)
+	assert.Equal(t, now.Add(time.Minute), manager.nextRefresh("example.com"))
+}
+
+func TestManager_RefreshSchedule(t *testing.T) {
+	manager, ssl := setupManager(&Options{Domains: []string{"example.com"}})
+	now := time.Now().UTC().Truncate(time.Second)
+	manager.now = func() time.Time { return now }
+
+	// Far from expiry, refresh on the regular interval
+	set(ssl, "example.com", generate(t, "example.com", now.Add(60*24*time.Hour)))
+	assert.NoError(t, manager.Load(context.Background()))
+	assert.Equal(t, now.Add(DefaultRefreshInterval), manager.nextRefresh("example.com"))
+
+	// Close to the renewal window, refresh when entering it
+	expires := now.Add(DefaultRefreshBefore + time.Hour)
+	set(ssl, "example.com", generate(t, "example.com", expires))
+	assert.NoError(t, manager.Load(context.Background()))
+	assert.Equal(t, expires.Add(-DefaultRefreshBefore), manager.nextRefresh("example.com"))
+
+	// Inside the window, keep retrying until Porkbun has renewed
+	set(ssl, "example.com", generate(t, "example.com", now.Add(24*time.Hour)))
+	assert.NoError(t, manager.Load(context.Background()))
+	assert.Equal(t, now.Add(DefaultRetryInterval), manager.nextRefresh("example.com"))
+}
+
+func TestManager_Run(t *testing.T) {
+	ssl := &countingSSL{FakeSSL: porkbuntest.NewFakeSSL()}
+	manager := NewManager(ssl, &Options{Domains: []string{"example.com"}, RefreshInterval: time.Hour})
+	first := generate(t, "example.com", time.Now().Add(60*24*time.Hour))
+	second := generate(t, "example.com", time.Now().Add(90*24*time.Hour))
+	set(ssl.FakeSSL, "example.com", first)
+
+	now := time.Now()
+	manager.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	manager.after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		switch len(waits) {
+		case 1:
+			cert, _ := manager.Certificate("example.com")
+			assert.True(t, cert.Leaf.Equal(first.Leaf))
+			set(ssl.FakeSSL, "example.com", second)
+		case 2:
+			// Never fire, so Run returns on the canceled context
+			cancel()
+			return nil
+		}
+		now = now.Add(d)
+		ch := make(chan time.Time, 1)
+		ch <- now
+		return ch
+	}
+
+	err := manager.Run(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, []time.Duration{time.Hour, time.Hour}, waits)
+	assert.Equal(t, int32(2), ssl.calls.Load())
+
+	cert, _ := manager.Certificate("example.com")
+	assert.True(t, cert.Leaf.Equal(second.Leaf))
+}
+
+func TestManager_TLSConfig(t *testing.T) {
+	manager, ssl := setupManager(&Options{Domains: []string{"example.com"}})
+	set(ssl, "example.com", generate(t, "example.com", time.Now().Add(60*24*time.Hour)))
+	assert.NoError(t, manager.Load(context.Background()))
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", manager.TLSConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{ServerName: "www.example.com", InsecureSkipVerify: true})
+	if assert.NoError(t, err) {
+		defer conn.Close()
+		assert.Equal(t, "example.com", conn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+	}
+}
@@ -0,0 +1,239 @@
+// Package certscan inspects the Porkbun SSL certificates of all domains in an account and flags
+// the ones that are about to expire.
+package certscan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+const (
+	// DefaultConcurrency is the number of certificates retrieved in parallel.
+	DefaultConcurrency = 4
+	// DefaultThreshold is the remaining validity below which a certificate is flagged.
+	DefaultThreshold = 14 * 24 * time.Hour
+)
+
+// SkipReason classifies why a domain's certificate could not be inspected.
+type SkipReason string
+
+// Constants representing the reasons a domain is skipped.
+const (
+	SkipNotActive     SkipReason = "not_active"     // The domain is not active, so no certificate is issued
+	SkipNotReady      SkipReason = "not_ready"      // Porkbun has not issued a certificate yet
+	SkipNoAPIAccess   SkipReason = "no_api_access"  // The domain is not opted in to API access
+	SkipInvalidBundle SkipReason = "invalid_bundle" // The bundle could not be parsed
+	SkipAPIError      SkipReason = "api_error"      // The API returned another error
+	SkipRequestFailed SkipReason = "request_failed" // The request did not complete
+)
+
+// Options defines the configuration options for the Scanner.
+type Options struct {
+	Domains     []string      // Domains to inspect, all domains in the account if empty.
+	Concurrency int           // Number of parallel retrievals, defaults to DefaultConcurrency.
+	Threshold   time.Duration // Flag certificates expiring within this duration, defaults to DefaultThreshold.
+}
+
+// Certificate is the inspection result for a single domain.
+type Certificate struct {
+	Domain        string     `json:"domain"`
+	NotBefore     *time.Time `json:"notBefore,omitempty"` // Nil if the domain was skipped
+	NotAfter      *time.Time `json:"notAfter,omitempty"`  // Nil if the domain was skipped
+	DNSNames      []string   `json:"dnsNames,omitempty"`
+	Issuer        string     `json:"issuer,omitempty"`
+	DaysRemaining int        `json:"daysRemaining"`
+	Expired       bool       `json:"expired"`
+	ExpiringSoon  bool       `json:"expiringSoon"` // Expires within the threshold, or already expired
+	Skipped       SkipReason `json:"skipped,omitempty"`
+	Error         string     `json:"error,omitempty"` // Details on why the domain was skipped
+}
+
+// Report holds the inspection results, in the order of the domains.
+type Report struct {
+	Certificates []Certificate `json:"certificates"`
+}
+
+// Flagged returns the certificates that are expired or expiring within the threshold.
+func (r *Report) Flagged() []Certificate {
+	var flagged []Certificate
+	for _, c := range r.Certificates {
+		if c.ExpiringSoon {
+			flagged = append(flagged, c)
+		}
+	}
+	return flagged
+}
+
+// Skipped returns the domains whose certificate could not be inspected.
+func (r *Report) Skipped() []Certificate {
+	var skipped []Certificate
+	for _, c := range r.Certificates {
+		if c.Skipped != "" {
+			skipped = append(skipped, c)
+		}
+	}
+	return skipped
+}
+
+// Scanner retrieves and inspects the certificates of Porkbun domains.
+type Scanner struct {
+	domainsAPI porkbun.DomainsAPI
+	sslAPI     porkbun.SSLAPI
+	options    Options
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// NewScanner initializes a new Scanner using the provided domains and SSL APIs, usually
+// client.Domains and client.Ssl, and options.
+func NewScanner(domains porkbun.DomainsAPI, ssl porkbun.SSLAPI, options *Options) *Scanner {
+	s := &Scanner{domainsAPI: domains, sslAPI: ssl, now: time.Now}
+
+	if options != nil {
+		s.options = *options
+	}
+	if s.options.Concurrency <= 0 {
+		s.options.Concurrency = DefaultConcurrency
+	}
+	if s.options.Threshold <= 0 {
+		s.options.Threshold = DefaultThreshold
+	}
+
+	return s
+}
+
+// Scan inspects the certificate of every domain. Domains whose certificate cannot be retrieved are
+// reported with a SkipReason; an error is only returned if the domains cannot be listed or the
+// context is cancelled.
+func (s *Scanner) Scan(ctx context.Context) (*Report, error) {
+	domains, err := s.domains(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Certificates: make([]Certificate, len(domains))}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < s.options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				report.Certificates[j] = s.inspect(ctx, domains[j])
+			}
+		}()
+	}
+
+	for i := range domains {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// domains returns the domains to inspect. Inactive domains are marked by a SkipNotActive status.
+func (s *Scanner) domains(ctx context.Context) ([]porkbun.Domain, error) {
+	domains, err := s.domainsAPI.ListAllDomains(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("certscan: listing domains: %w", err)
+	}
+
+	if len(s.options.Domains) == 0 {
+		return domains, nil
+	}
+
+	// Keep the requested domains in the requested order, inspecting unknown ones anyway
+	byName := make(map[string]porkbun.Domain, len(domains))
+	for _, d := range domains {
+		byName[strings.ToLower(d.Domain)] = d
+	}
+	selected := make([]porkbun.Domain, 0, len(s.options.Domains))
+	for _, name := range s.options.Domains {
+		d, ok := byName[strings.ToLower(name)]
+		if !ok {
+			d = porkbun.Domain{Domain: name, Status: "ACTIVE"}
+		}
+		selected = append(selected, d)
+	}
+	return selected, nil
+}
+
+// inspect retrieves and parses the certificate of a single domain.
+func (s *Scanner) inspect(ctx context.Context, domain porkbun.Domain) Certificate {
+	result := Certificate{Domain: domain.Domain}
+
+	if domain.Status != "" && !strings.EqualFold(domain.Status, "ACTIVE") {
+		result.Skipped = SkipNotActive
+		result.Error = "domain status is " + domain.Status
+		return result
+	}
+
+	resp, err := s.sslAPI.Retrieve(ctx, domain.Domain)
+	if err != nil {
+		result.Skipped = classify(err)
+		result.Error = errorMessage(err)
+		return result
+	}
+
+	certs, err := resp.Certificates()
+	if err != nil {
+		result.Skipped = SkipInvalidBundle
+		result.Error = err.Error()
+		return result
+	}
+
+	leaf := certs[0]
+	remaining := leaf.NotAfter.Sub(s.now())
+
+	notBefore, notAfter := leaf.NotBefore, leaf.NotAfter
+	result.NotBefore, result.NotAfter = &notBefore, &notAfter
+	result.DNSNames = leaf.DNSNames
+	result.Issuer = leaf.Issuer.String()
+	result.DaysRemaining = int(remaining / (24 * time.Hour))
+	result.Expired = remaining <= 0
+	result.ExpiringSoon = remaining < s.options.Threshold
+
+	return result
+}
+
+// classify maps a Retrieve error to a SkipReason.
+func classify(err error) SkipReason {
+	var apiErr *porkbun.ErrorResponse
+	if !errors.As(err, &apiErr) {
+		return SkipRequestFailed
+	}
+
+	message := strings.ToLower(apiErr.Message)
+	switch {
+	case strings.Contains(message, "not ready"):
+		return SkipNotReady
+	case strings.Contains(message, "api access"):
+		return SkipNoAPIAccess
+	}
+	return SkipAPIError
+}
+
+// errorMessage returns the API message of the error, or the error itself for other failures.
+func errorMessage(err error) string {
+	var apiErr *porkbun.ErrorResponse
+	if errors.As(err, &apiErr) && apiErr.Message != "" {
+		return apiErr.Message
+	}
+	return err.Error()
+}
@@ -0,0 +1,211 @@
+package certscan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/internal/testcert"
+)
+
+// fakeApi serves the domain list and SSL bundles, tracking the number of parallel retrievals.
+type fakeApi struct {
+	domains  []map[string]any
+	bundles  map[string]*testcert.Bundle
+	errors   map[string]string
+	active   atomic.Int32
+	mu       sync.Mutex
+	parallel int32
+}
+
+func (f *fakeApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/domain/listAll":
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "SUCCESS", "domains": f.domains})
+
+	case strings.HasPrefix(r.URL.Path, "/ssl/retrieve/"):
+		n := f.active.Add(1)
+		defer f.active.Add(-1)
+		f.mu.Lock()
+		if n > f.parallel {
+			f.parallel = n
+		}
+		f.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+
+		domain := strings.TrimPrefix(r.URL.Path, "/ssl/retrieve/")
+		if message, ok := f.errors[domain]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "ERROR", "message": message})
+			return
+		}
+		bundle, ok := f.bundles[domain]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "ERROR", "message": "Invalid domain."})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"status":           "SUCCESS",
+			"certificatechain": bundle.CertificateChain,
+			"privatekey":       bundle.PrivateKey,
+			"publickey":        bundle.PublicKey,
+		})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func domain(name, status string) map[string]any {
+	return map[string]any{
+		"domain": name, "status": status, "tld": "com",
+		"createDate": "2020-01-01 00:00:00", "expireDate": "2030-01-01 00:00:00",
+		"securityLock": "1", "whoisPrivacy": "1", "autoRenew": 1, "notLocal": 0,
+	}
+}
+
+func generate(t *testing.T, name string, notAfter time.Time) *testcert.Bundle {
+	bundle, err := testcert.Generate([]string{name, "*." + name}, notAfter.Add(-90*24*time.Hour), notAfter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bundle
+}
+
+func setupScanner(t *testing.T, options *Options) (*Scanner, *fakeApi) {
+	now := time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC)
+	fake := &fakeApi{
+		domains: []map[string]any{
+			domain("fresh.com", "ACTIVE"),
+			domain("soon.com", "ACTIVE"),
+			domain("expired.com", "ACTIVE"),
+			domain("pending.com", "ACTIVE"),
+			domain("noapi.com", "ACTIVE"),
+			domain("broken.com", "ACTIVE"),
+			domain("gone.com", "EXPIRED"),
+		},
+		bundles: map[string]*testcert.Bundle{
+			"fresh.com":   generate(t, "fresh.com", now.Add(60*24*time.Hour+time.Hour)),
+			"soon.com":    generate(t, "soon.com", now.Add(5*24*time.Hour+time.Hour)),
+			"expired.com": generate(t, "expired.com", now.Add(-2*24*time.Hour-time.Hour)),
+			"broken.com":  {CertificateChain: "garbage"},
+		},
+		errors: map[string]string{
+			"pending.com": "The SSL certificate is not ready for this domain.",
+			"noapi.com":   "Domain is not opted in to API access.",
+		},
+	}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := porkbun.NewClient(&porkbun.Options{BaseURL: server.URL})
+	scanner := NewScanner(client.Domains, client.Ssl, options)
+	scanner.now = func() time.Time { return now }
+	return scanner, fake
+}
+
+func TestScanner_Scan(t *testing.T) {
+	scanner, fake := setupScanner(t, &Options{Concurrency: 2})
+
+	report, err := scanner.Scan(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, report.Certificates, 7)
+	assert.LessOrEqual(t, fake.parallel, int32(2))
+
+	fresh := report.Certificates[0]
+	assert.Equal(t, "fresh.com", fresh.Domain)
+	assert.Equal(t, 60, fresh.DaysRemaining)
+	assert.Equal(t, []string{"fresh.com", "*.fresh.com"}, fresh.DNSNames)
+	assert.Equal(t, "CN=Test Intermediate CA", fresh.Issuer)
+	if assert.NotNil(t, fresh.NotAfter) {
+		assert.Equal(t, fake.bundles["fresh.com"].Leaf.NotAfter, *fresh.NotAfter)
+	}
+	assert.False(t, fresh.ExpiringSoon)
+	assert.Empty(t, fresh.Skipped)
+
+	assert.Equal(t, 5, report.Certificates[1].DaysRemaining)
+	assert.True(t, report.Certificates[1].ExpiringSoon)
+	assert.False(t, report.Certificates[1].Expired)
+
+	assert.Equal(t, -2, report.Certificates[2].DaysRemaining)
+	assert.True(t, report.Certificates[2].Expired)
+
+	var flagged []string
+	for _, c := range report.Flagged() {
+		flagged = append(flagged, c.Domain)
+	}
+	assert.Equal(t, []string{"soon.com", "expired.com"}, flagged)
+
+	skipped := map[string]SkipReason{}
+	for _, c := range report.Skipped() {
+		skipped[c.Domain] = c.Skipped
+		assert.NotEmpty(t, c.Error)
+	}
+	assert.Equal(t, map[string]SkipReason{
+		"pending.com": SkipNotReady,
+		"noapi.com":   SkipNoAPIAccess,
+		"broken.com":  SkipInvalidBundle,
+		"gone.com":    SkipNotActive,
+	}, skipped)
+	assert.Equal(t, "The SSL certificate is not ready for this domain.", report.Certificates[3].Error)
+
+	// Skipped domains have no validity period
+	data, err := json.Marshal(report.Certificates[3])
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "notAfter")
+}
+
+func TestScanner_ScanDomains(t *testing.T) {
+	scanner, _ := setupScanner(t, &Options{Domains: []string{"Fresh.com", "unknown.com"}})
+
+	report, err := scanner.Scan(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, report.Certificates, 2) {
+		assert.Equal(t, "fresh.com", report.Certificates[0].Domain)
+		assert.Equal(t, "unknown.com", report.Certificates[1].Domain)
+		assert.Equal(t, SkipAPIError, report.Certificates[1].Skipped)
+		assert.Equal(t, "Invalid domain.", report.Certificates[1].Error)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, SkipRequestFailed, classify(errors.New("connection refused")))
+	assert.Equal(t, SkipNotReady, classify(fmt.Errorf("wrapped: %w", &porkbun.ErrorResponse{Message: "The SSL certificate is not ready for this domain."})))
+	assert.Equal(t, SkipAPIError, classify(&porkbun.ErrorResponse{Message: "Invalid domain."}))
+}
+
+func TestScanner_ScanThreshold(t *testing.T) {
+	scanner, _ := setupScanner(t, &Options{Domains: []string{"fresh.com"}, Threshold: 90 * 24 * time.Hour})
+
+	report, err := scanner.Scan(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, report.Flagged(), 1)
+}
+
+func TestScanner_ScanErrors(t *testing.T) {
+	client := porkbun.NewClient(&porkbun.Options{BaseURL: "http://127.0.0.1:1"})
+	scanner := NewScanner(client.Domains, client.Ssl, nil)
+	_, err := scanner.Scan(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "listing domains")
+
+	scanner, _ = setupScanner(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = scanner.Scan(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,217 @@
+// Package certsync writes Porkbun SSL bundles to PEM files for servers such as nginx and haproxy.
+package certsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// Default file names, relative to Options.Dir.
+const (
+	DefaultFullchainFile = "fullchain.pem"
+	DefaultPrivkeyFile   = "privkey.pem"
+	DefaultCombinedFile  = "combined.pem"
+)
+
+// File modes of the written files. Files containing the private key are only readable by the owner.
+const (
+	CertificateMode fs.FileMode = 0o644
+	PrivateKeyMode  fs.FileMode = 0o600
+)
+
+// Options defines the configuration options for the Syncer.
+type Options struct {
+	Domain        string      // Domain whose certificate bundle is retrieved.
+	Dir           string      // Directory the files are written to, defaults to the working directory.
+	FullchainFile string      // Leaf and intermediate certificates, defaults to DefaultFullchainFile.
+	PrivkeyFile   string      // Private key, defaults to DefaultPrivkeyFile.
+	CombinedFile  string      // Certificates followed by the private key, defaults to DefaultCombinedFile.
+	PostCommand   string      // Shell command run after any file changed, e.g. "systemctl reload nginx". Retried by the next Sync if it fails.
+	Logger        *log.Logger // Logger for written files and command output, discarded if nil.
+}
+
+// Result describes the outcome of a Sync.
+type Result struct {
+	Written []string // Paths of the files whose content changed
+}
+
+// Changed reports whether any file was written.
+func (r *Result) Changed() bool {
+	return len(r.Written) > 0
+}
+
+// Syncer retrieves the certificate bundle of a domain and writes it to disk.
+type Syncer struct {
+	ssl     porkbun.SSLAPI
+	options Options
+	pending []string // Files written since the post command last succeeded
+}
+
+// NewSyncer initializes a new Syncer using the provided SSL API, usually client.Ssl, and options.
+func NewSyncer(ssl porkbun.SSLAPI, options *Options) *Syncer {
+	s := &Syncer{ssl: ssl}
+
+	if options != nil {
+		s.options = *options
+	}
+	if s.options.Dir == "" {
+		s.options.Dir = "."
+	}
+	if s.options.FullchainFile == "" {
+		s.options.FullchainFile = DefaultFullchainFile
+	}
+	if s.options.PrivkeyFile == "" {
+		s.options.PrivkeyFile = DefaultPrivkeyFile
+	}
+	if s.options.CombinedFile == "" {
+		s.options.CombinedFile = DefaultCombinedFile
+	}
+	if s.options.Logger == nil {
+		s.options.Logger = log.New(io.Discard, "", 0)
+	}
+
+	return s
+}
+
+// Sync retrieves the bundle and writes the files whose content differs from what is on disk.
+// The post command is only run when at least one file was written, or when it failed in a previous
+// Sync, until it succeeds.
+func (s *Syncer) Sync(ctx context.Context) (*Result, error) {
+	result := &Result{}
+
+	resp, err := s.ssl.Retrieve(ctx, s.options.Domain)
+	if err != nil {
+		return result, fmt.Errorf("certsync: retrieving %s: %w", s.options.Domain, err)
+	}
+
+	fullchain, privkey, err := encodeBundle(resp)
+	if err != nil {
+		return result, fmt.Errorf("certsync: invalid bundle for %s: %w", s.options.Domain, err)
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+		mode    fs.FileMode
+	}{
+		{s.options.FullchainFile, fullchain, CertificateMode},
+		{s.options.PrivkeyFile, privkey, PrivateKeyMode},
+		{s.options.CombinedFile, append(append([]byte{}, fullchain...), privkey...), PrivateKeyMode},
+	}
+
+	for _, file := range files {
+		path := filepath.Join(s.options.Dir, file.name)
+		written, err := writeFile(path, file.content, file.mode)
+		if err != nil {
+			return result, fmt.Errorf("certsync: %w", err)
+		}
+		if written {
+			s.options.Logger.Printf("wrote %s", path)
+			result.Written = append(result.Written, path)
+			if !slices.Contains(s.pending, path) {
+				s.pending = append(s.pending, path)
+			}
+		}
+	}
+
+	if len(s.pending) > 0 && s.options.PostCommand != "" {
+		if err := s.runPostCommand(ctx, s.pending); err != nil {
+			return result, fmt.Errorf("certsync: post command: %w", err)
+		}
+	}
+	s.pending = nil
+
+	return result, nil
+}
+
+// runPostCommand runs the post command through the shell, logging its combined output.
+// The domain and the written files are passed in the CERTSYNC_DOMAIN and CERTSYNC_WRITTEN variables.
+func (s *Syncer) runPostCommand(ctx context.Context, written []string) error {
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", s.options.PostCommand)
+	cmd.Env = append(os.Environ(),
+		"CERTSYNC_DOMAIN="+s.options.Domain,
+		"CERTSYNC_WRITTEN="+strings.Join(written, " "),
+	)
+
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		s.options.Logger.Printf("%s: %s", s.options.PostCommand, bytes.TrimSpace(output))
+	}
+	return err
+}
+
+// encodeBundle validates the bundle and returns the PEM encoded chain, leaf first, and private key.
+func encodeBundle(resp *porkbun.SslRetrieveResponse) ([]byte, []byte, error) {
+	certs, err := resp.Certificates()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fullchain []byte
+	for _, cert := range certs {
+		fullchain = append(fullchain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+
+	privkey := strings.TrimSpace(resp.Privatekey) + "\n"
+	return fullchain, []byte(privkey), nil
+}
+
+// writeFile atomically replaces the file with the content, unless it already holds exactly that content.
+// The content is written to a temporary file in the same directory, which is then renamed over the target.
+// An unchanged file still gets the mode, so a private key is not left readable by others.
+func writeFile(path string, content []byte, mode fs.FileMode) (bool, error) {
+	current, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(current, content) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		if info.Mode().Perm() != mode {
+			return false, os.Chmod(path, mode)
+		}
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,193 @@
+package certsync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/internal/testcert"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+)
+
+// generate returns a new certificate bundle for example.com, as retrieved from the API.
+func generate(t *testing.T) porkbun.SslRetrieveResponse {
+	bundle, err := testcert.Generate([]string{"example.com", "*.example.com"}, time.Now(), time.Now().Add(90*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return porkbun.SslRetrieveResponse{
+		Certificatechain: bundle.CertificateChain,
+		Privatekey:       bundle.PrivateKey,
+		Publickey:        bundle.PublicKey,
+	}
+}
+
+// setupSyncer returns a Syncer backed by a porkbuntest.FakeSSL holding a bundle for example.com.
+func setupSyncer(t *testing.T, options *Options) (*Syncer, *porkbuntest.FakeSSL) {
+	ssl := porkbuntest.NewFakeSSL()
+	ssl.SetSSL("example.com", generate(t))
+	return NewSyncer(ssl, options), ssl
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "reloaded")
+	syncer, ssl := setupSyncer(t, &Options{
+		Domain:      "example.com",
+		Dir:         dir,
+		PostCommand: `echo "$CERTSYNC_DOMAIN" >> ` + marker,
+	})
+	bundle := generate(t)
+	ssl.SetSSL("example.com", bundle)
+
+	result, err := syncer.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, DefaultFullchainFile),
+		filepath.Join(dir, DefaultPrivkeyFile),
+		filepath.Join(dir, DefaultCombinedFile),
+	}, result.Written)
+
+	fullchain, _ := os.ReadFile(filepath.Join(dir, DefaultFullchainFile))
+	privkey, _ := os.ReadFile(filepath.Join(dir, DefaultPrivkeyFile))
+	combined, _ := os.ReadFile(filepath.Join(dir, DefaultCombinedFile))
+	assert.Equal(t, strings.ReplaceAll(bundle.Certificatechain, "\n\n", "\n"), string(fullchain))
+	assert.Equal(t, bundle.Privatekey, string(privkey))
+	assert.Equal(t, string(fullchain)+string(privkey), string(combined))
+
+	for name, mode := range map[string]os.FileMode{
+		DefaultFullchainFile: CertificateMode,
+		DefaultPrivkeyFile:   PrivateKeyMode,
+		DefaultCombinedFile:  PrivateKeyMode,
+	} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if assert.NoError(t, err) {
+			assert.Equal(t, mode, info.Mode().Perm(), name)
+		}
+	}
+
+	// Unchanged content leaves the files alone and does not run the command
+	result, err = syncer.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, result.Changed())
+
+	// A renewed certificate is written again
+	ssl.SetSSL("example.com", generate(t))
+	result, err = syncer.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, result.Written, 3)
+
+	reloaded, _ := os.ReadFile(marker)
+	assert.Equal(t, "example.com\nexample.com\n", string(reloaded))
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 4, "temporary files are cleaned up")
+}
+
+func TestSyncer_SyncFixesMode(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "reloaded")
+	syncer, _ := setupSyncer(t, &Options{
+		Domain:      "example.com",
+		Dir:         dir,
+		PostCommand: "touch " + marker,
+	})
+
+	_, err := syncer.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(marker))
+
+	// A key left world-readable gets its mode back without being rewritten
+	privkey := filepath.Join(dir, DefaultPrivkeyFile)
+	assert.NoError(t, os.Chmod(privkey, 0o644))
+
+	result, err := syncer.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, result.Changed())
+	info, err := os.Stat(privkey)
+	if assert.NoError(t, err) {
+		assert.Equal(t, PrivateKeyMode, info.Mode().Perm())
+	}
+	assert.NoFileExists(t, marker)
+}
+
+func TestSyncer_SyncCustomFiles(t *testing.T) {
+	dir := t.TempDir()
+	syncer, _ := setupSyncer(t, &Options{
+		Domain:        "example.com",
+		Dir:           dir,
+		FullchainFile: "example.com.crt",
+		PrivkeyFile:   "example.com.key",
+		CombinedFile:  "example.com.pem",
+	})
+
+	result, err := syncer.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "example.com.crt"), result.Written[0])
+	assert.FileExists(t, filepath.Join(dir, "example.com.key"))
+	assert.FileExists(t, filepath.Join(dir, "example.com.pem"))
+}
+
+func TestSyncer_SyncErrors(t *testing.T) {
+	dir := t.TempDir()
+	syncer, ssl := setupSyncer(t, &Options{Domain: "example.org", Dir: dir})
+
+	_, err := syncer.Sync(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), porkbuntest.MsgSslNotReady)
+
+	// A key that does not belong to the certificate is never written
+	syncer.options.Domain = "example.com"
+	bundle := generate(t)
+	bundle.Privatekey = generate(t).Privatekey
+	ssl.SetSSL("example.com", bundle)
+	_, err = syncer.Sync(context.Background())
+	assert.ErrorIs(t, err, porkbun.ErrKeyMismatch)
+
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+}
+
+func TestSyncer_SyncPostCommandError(t *testing.T) {
+	syncer, _ := setupSyncer(t, &Options{Domain: "example.com", Dir: t.TempDir(), PostCommand: "exit 3"})
+
+	result, err := syncer.Sync(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "post command: exit status 3")
+	assert.True(t, result.Changed())
+}
+
+func TestSyncer_SyncRetriesPostCommand(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "fail")
+	assert.NoError(t, os.WriteFile(marker, nil, 0o600))
+	reloads := filepath.Join(dir, "reloads")
+	command := `[ -e "` + marker + `" ] && exit 1; echo "$CERTSYNC_WRITTEN" >> "` + reloads + `"`
+	syncer, _ := setupSyncer(t, &Options{Domain: "example.com", Dir: dir, PostCommand: command})
+
+	// The files are written but the reload fails
+	result, err := syncer.Sync(context.Background())
+	assert.Error(t, err)
+	assert.True(t, result.Changed())
+
+	// The next sync has nothing to write but runs the command again, with the files written before
+	assert.NoError(t, os.Remove(marker))
+	result, err = syncer.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, result.Changed())
+	data, err := os.ReadFile(reloads)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"))
+	assert.Contains(t, string(data), DefaultPrivkeyFile)
+
+	// Once it succeeded, unchanged files do not run it
+	_, err = syncer.Sync(context.Background())
+	assert.NoError(t, err)
+	data, _ = os.ReadFile(reloads)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"))
+}
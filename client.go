@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 )
 
 // HTTPClient defines an interface for making HTTP requests.
@@ -21,6 +22,7 @@ type Options struct {
 	ApiKey       string      // Public API key provided by Porkbun.
 	SecretApiKey string      // Secret API key provided by Porkbun.
 	IPv4Only     bool        // If true, use IPv4-only base URL.
+	BaseURL      string      // Custom API base URL, overrides IPv4Only if set.
 	UserAgent    string      // Custom User-Agent string, defaults to "porkbun-go/1.0.0".
 }
 
@@ -41,7 +43,9 @@ func NewClient(options *Options) *Client {
 		userAgent:  options.UserAgent,
 	}
 
-	if options.IPv4Only {
+	if options.BaseURL != "" {
+		client.baseURL = strings.TrimSuffix(options.BaseURL, "/")
+	} else if options.IPv4Only {
 		client.baseURL = ipv4OnlyBaseURL
 	} else {
 		client.baseURL = defaultBaseURL
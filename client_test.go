@@ -27,6 +27,15 @@ func TestPorkbun_NewClient(t *testing.T) {
 	assert.Equal(t, "CustomAgent/1", client.userAgent)
 }
 
+func TestPorkbun_NewClient_BaseURL(t *testing.T) {
+	client := NewClient(&Options{
+		BaseURL:  "http://localhost:8080/api/",
+		IPv4Only: true,
+	})
+
+	assert.Equal(t, "http://localhost:8080/api", client.baseURL)
+}
+
 func TestPorkbun_NewRequest(t *testing.T) {
 	client := NewClient(&Options{})
 
@@ -0,0 +1,99 @@
+// Command porkbun-certscan reports the SSL certificates of all Porkbun domains and exits with
+// status 1 if any of them expires within the threshold.
+//
+// Credentials are read from the PORKBUN_API_KEY and PORKBUN_API_SECRET environment variables.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/certscan"
+)
+
+func main() {
+	domains := flag.String("domains", "", "Comma separated list of domains to inspect, all domains if empty")
+	thresholdDays := flag.Int("threshold-days", 14, "Flag certificates expiring within this many days")
+	concurrency := flag.Int("concurrency", certscan.DefaultConcurrency, "Number of certificates retrieved in parallel")
+	jsonOutput := flag.Bool("json", false, "Print the report as JSON")
+	flag.Parse()
+
+	client := porkbun.NewClient(&porkbun.Options{
+		ApiKey:       os.Getenv("PORKBUN_API_KEY"),
+		SecretApiKey: os.Getenv("PORKBUN_API_SECRET"),
+	})
+
+	options := &certscan.Options{
+		Concurrency: *concurrency,
+		Threshold:   time.Duration(*thresholdDays) * 24 * time.Hour,
+	}
+	options.Domains = splitList(*domains)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := certscan.NewScanner(client.Domains, client.Ssl, options).Scan(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		printReport(report)
+	}
+
+	if len(report.Flagged()) > 0 {
+		os.Exit(1)
+	}
+}
+
+// printReport writes the report as a table to stdout.
+func printReport(report *certscan.Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tSTATUS\tDAYS\tNOT AFTER\tISSUER\tNAMES")
+
+	for _, c := range report.Certificates {
+		if c.Skipped != "" {
+			fmt.Fprintf(w, "%s\tskipped (%s)\t\t\t\t%s\n", c.Domain, c.Skipped, c.Error)
+			continue
+		}
+
+		status := "ok"
+		switch {
+		case c.Expired:
+			status = "EXPIRED"
+		case c.ExpiringSoon:
+			status = "EXPIRING"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", c.Domain, status, c.DaysRemaining,
+			c.NotAfter.Format(time.DateOnly), c.Issuer, strings.Join(c.DNSNames, ","))
+	}
+
+	w.Flush()
+}
+
+// splitList splits a comma separated flag value, ignoring empty entries.
+func splitList(value string) []string {
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
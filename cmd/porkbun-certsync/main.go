@@ -0,0 +1,57 @@
+// Command porkbun-certsync writes the Porkbun SSL certificate of a domain to PEM files and runs
+// a command, such as a web server reload, when they change.
+//
+// Credentials are read from the PORKBUN_API_KEY and PORKBUN_API_SECRET environment variables.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/certsync"
+)
+
+func main() {
+	domain := flag.String("domain", "", "Domain whose certificate is retrieved")
+	dir := flag.String("dir", ".", "Directory the files are written to")
+	fullchain := flag.String("fullchain", certsync.DefaultFullchainFile, "File name of the certificate chain")
+	privkey := flag.String("privkey", certsync.DefaultPrivkeyFile, "File name of the private key")
+	combined := flag.String("combined", certsync.DefaultCombinedFile, "File name of the chain followed by the private key")
+	postCommand := flag.String("post-command", "", "Shell command run when any file changed, retried on the next sync until it succeeds")
+	flag.Parse()
+
+	if *domain == "" {
+		log.Fatal("-domain is required")
+	}
+
+	client := porkbun.NewClient(&porkbun.Options{
+		ApiKey:       os.Getenv("PORKBUN_API_KEY"),
+		SecretApiKey: os.Getenv("PORKBUN_API_SECRET"),
+	})
+
+	syncer := certsync.NewSyncer(client.Ssl, &certsync.Options{
+		Domain:        *domain,
+		Dir:           *dir,
+		FullchainFile: *fullchain,
+		PrivkeyFile:   *privkey,
+		CombinedFile:  *combined,
+		PostCommand:   *postCommand,
+		Logger:        log.Default(),
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := syncer.Sync(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !result.Changed() {
+		log.Printf("certificate for %s is up to date", *domain)
+	}
+}
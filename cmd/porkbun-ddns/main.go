@@ -0,0 +1,89 @@
+// Command porkbun-ddns keeps A/AAAA records pointed at the host's public IP address.
+//
+// Credentials are read from the PORKBUN_API_KEY and PORKBUN_API_SECRET environment variables.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/ddns"
+)
+
+func main() {
+	configPath := flag.String("config", "porkbun-ddns.yaml", "Path to the configuration file")
+	once := flag.Bool("once", false, "Run a single update pass and exit")
+	flag.Parse()
+
+	config, err := ddns.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger := log.Default()
+	var updaters []*ddns.Updater
+
+	// The IPv4-only endpoint always reports an IPv4 address, the default one prefers IPv6
+	if config.UpdateIPv4() {
+		options := config.Options(porkbun.A)
+		options.Logger = logger
+		updaters = append(updaters, ddns.NewUpdater(newClient(true), options))
+	}
+	if config.IPv6 {
+		options := config.Options(porkbun.AAAA)
+		options.Logger = logger
+		updaters = append(updaters, ddns.NewUpdater(newClient(false), options))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *once {
+		failed := false
+		for _, updater := range updaters {
+			results, err := updater.Update(ctx)
+			for _, result := range results {
+				logger.Printf("%s %s %s -> %s", result.Action, result.Type, result.Record, result.IP)
+			}
+			if errors.Is(err, ddns.ErrNoAddress) {
+				// The host has no address of this family, which is not a failure
+				logger.Printf("skipping: %v", err)
+			} else if err != nil {
+				logger.Print(err)
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, updater := range updaters {
+		wg.Add(1)
+		go func(updater *ddns.Updater) {
+			defer wg.Done()
+			if err := updater.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Print(err)
+			}
+		}(updater)
+	}
+	wg.Wait()
+}
+
+// newClient creates a client from the environment credentials.
+func newClient(ipv4Only bool) *porkbun.Client {
+	return porkbun.NewClient(&porkbun.Options{
+		ApiKey:       os.Getenv("PORKBUN_API_KEY"),
+		SecretApiKey: os.Getenv("PORKBUN_API_SECRET"),
+		IPv4Only:     ipv4Only,
+	})
+}
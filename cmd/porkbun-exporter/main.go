@@ -0,0 +1,92 @@
+// Command porkbun-exporter serves Prometheus metrics on the domains, DNS records and SSL
+// certificates of a Porkbun account, and on the API requests it makes.
+//
+// Credentials are read from the configuration profile, or the PORKBUN_API_KEY and
+// PORKBUN_API_SECRET environment variables.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/config"
+	"github.com/tuzzmaniandevil/porkbun-go/exporter"
+)
+
+func main() {
+	listen := flag.String("listen", ":9849", "Address to serve the metrics on")
+	path := flag.String("path", "/metrics", "HTTP path of the metrics")
+	interval := flag.Duration("interval", exporter.DefaultRefreshInterval, "Time between refreshes of the domains, records and certificates")
+	domains := flag.String("domains", "", "Comma separated list of domains to export, all domains if empty")
+	concurrency := flag.Int("concurrency", exporter.DefaultConcurrency, "Number of domains refreshed in parallel")
+	configPath := flag.String("config", "", "Path to the configuration file")
+	profileName := flag.String("profile", "", "Configuration profile")
+	flag.Parse()
+
+	profile, err := config.LoadProfile(&config.LoadOptions{Path: *configPath, Profile: *profileName})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !profile.HasCredentials() {
+		log.Fatalf("no API credentials, set %s and %s or configure a profile", config.EnvApiKey, config.EnvSecretApiKey)
+	}
+
+	requests := exporter.NewRequestMetrics(nil)
+	httpClient := requests.Instrument(&http.Client{Timeout: 30 * time.Second})
+	clientOptions := profile.Options()
+	clientOptions.HttpClient = &httpClient
+
+	options := &exporter.Options{
+		RefreshInterval: *interval,
+		Concurrency:     *concurrency,
+		Requests:        requests,
+		Logger:          log.Default(),
+	}
+	options.Domains = splitList(*domains)
+	client := porkbun.NewClient(clientOptions)
+	e := exporter.NewExporter(client.Dns, client.Domains, client.Ssl, options)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mux := http.NewServeMux()
+	mux.Handle(*path, e)
+	server := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := e.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Print(err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("serving metrics on %s%s", *listen, *path)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// splitList splits a comma separated flag value, ignoring empty entries.
+func splitList(value string) []string {
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+// Command porkbun-external-dns runs an external-dns webhook provider backed by the Porkbun API.
+//
+// Credentials are read from the PORKBUN_API_KEY and PORKBUN_API_SECRET environment variables.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/externaldns"
+)
+
+func main() {
+	listen := flag.String("listen", "localhost:8888", "Address the webhook server listens on")
+	domainFilter := flag.String("domain-filter", "", "Comma separated list of domains to manage")
+	excludeDomains := flag.String("exclude-domains", "", "Comma separated list of domains to exclude")
+	flag.Parse()
+
+	client := porkbun.NewClient(&porkbun.Options{
+		ApiKey:       os.Getenv("PORKBUN_API_KEY"),
+		SecretApiKey: os.Getenv("PORKBUN_API_SECRET"),
+	})
+
+	provider := externaldns.NewProvider(client.Dns, client.Domains, &externaldns.ProviderOptions{
+		DomainFilter: externaldns.NewDomainFilter(splitList(*domainFilter), splitList(*excludeDomains)),
+	})
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           externaldns.NewServer(provider),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Listening on %s", *listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// splitList splits a comma separated flag value, ignoring empty entries.
+func splitList(value string) []string {
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
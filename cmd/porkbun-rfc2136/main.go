@@ -0,0 +1,87 @@
+// Command porkbun-rfc2136 runs a DNS server that applies TSIG authenticated RFC 2136 UPDATE
+// messages to Porkbun DNS records.
+//
+// Credentials are read from the PORKBUN_API_KEY and PORKBUN_API_SECRET environment variables.
+// TSIG keys are given as name:secret pairs, where the secret is base64 encoded.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/miekg/dns"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/rfc2136"
+)
+
+// keyFlag collects repeated -tsig-key flags.
+type keyFlag map[string]string
+
+func (k keyFlag) String() string {
+	return fmt.Sprint(len(k), " keys")
+}
+
+func (k keyFlag) Set(value string) error {
+	name, secret, ok := strings.Cut(value, ":")
+	if !ok || name == "" || secret == "" {
+		return fmt.Errorf("invalid TSIG key %q, expected name:secret", value)
+	}
+	k[name] = secret
+	return nil
+}
+
+func main() {
+	keys := keyFlag{}
+
+	listen := flag.String("listen", ":53", "Address the DNS server listens on (UDP and TCP)")
+	zones := flag.String("zones", "", "Comma separated list of Porkbun domains that accept updates")
+	primaryNS := flag.String("primary-ns", "", "MNAME of the SOA record returned for the zones")
+	flag.Var(keys, "tsig-key", "TSIG key as name:base64secret, may be repeated")
+	flag.Parse()
+
+	if *zones == "" || len(keys) == 0 {
+		fmt.Fprintln(os.Stderr, "at least one zone and one TSIG key are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client := porkbun.NewClient(&porkbun.Options{
+		ApiKey:       os.Getenv("PORKBUN_API_KEY"),
+		SecretApiKey: os.Getenv("PORKBUN_API_SECRET"),
+	})
+
+	server := rfc2136.NewServer(client.Dns, &rfc2136.Options{
+		Zones:     strings.Split(*zones, ","),
+		TsigKeys:  keys,
+		PrimaryNS: *primaryNS,
+	})
+
+	servers := []*dns.Server{server.DNSServer("udp"), server.DNSServer("tcp")}
+	errs := make(chan error, len(servers))
+	for _, srv := range servers {
+		srv.Addr = *listen
+		go func(srv *dns.Server) {
+			errs <- srv.ListenAndServe()
+		}(srv)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Listening on %s", *listen)
+	select {
+	case err := <-errs:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+
+	for _, srv := range servers {
+		_ = srv.Shutdown()
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Exit codes of the porkbun command.
+const (
+	exitOK    = 0 // The command succeeded
+	exitError = 1 // The command failed, e.g. the API returned an error
+	exitUsage = 2 // The command line is invalid
+)
+
+// usageError reports an invalid command line. It makes the command exit with exitUsage.
+type usageError struct {
+	message string
+}
+
+// Error implements the error interface for usageError.
+func (e *usageError) Error() string {
+	return e.message
+}
+
+// usagef returns a usageError with a formatted message.
+func usagef(format string, a ...any) error {
+	return &usageError{message: fmt.Sprintf(format, a...)}
+}
+
+// command is a node of the command tree: either a group of subcommands or a runnable command.
+type command struct {
+	name     string                                                   // Name used on the command line
+	args     string                                                   // Synopsis of the positional arguments
+	summary  string                                                   // One line description
+	minArgs  int                                                      // Minimum number of positional arguments
+	maxArgs  int                                                      // Maximum number of positional arguments, -1 for no limit
+	commands []*command                                               // Subcommands of a group
+	flags    func(fs *flag.FlagSet)                                   // Registers the command's flags
+	run      func(ctx context.Context, app *app, args []string) error // Runs the command
+
+	// Suggests the next positional argument given the previous ones, see completion.go. A first
+	// <domain> argument is completed without it.
+	complete func(ctx context.Context, app *app, args []string) []candidate
+}
+
+// execute parses args for the command, then runs it or dispatches to a subcommand.
+// path is the command line leading to the command, used in usage messages.
+func (c *command) execute(ctx context.Context, app *app, path string, args []string) error {
+	fs := c.flagSet(path)
+
+	if c.commands != nil {
+		err := fs.Parse(args)
+		if errors.Is(err, flag.ErrHelp) || (err == nil && fs.Arg(0) == "help") {
+			c.printUsage(app.stdout, path, fs)
+			return nil
+		}
+		if err != nil {
+			c.printUsage(app.stderr, path, fs)
+			return usagef("%s: %v", path, err)
+		}
+		if fs.NArg() == 0 {
+			c.printUsage(app.stderr, path, fs)
+			return usagef("%s: missing command", path)
+		}
+
+		if sub := c.subcommand(fs.Arg(0)); sub != nil {
+			return sub.execute(ctx, app, path+" "+sub.name, fs.Args()[1:])
+		}
+		c.printUsage(app.stderr, path, fs)
+		return usagef("%s: unknown command %q", path, fs.Arg(0))
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		c.printUsage(app.stdout, path, fs)
+		return nil
+	}
+	if err != nil {
+		c.printUsage(app.stderr, path, fs)
+		return usagef("%s: %v", path, err)
+	}
+	if len(positional) < c.minArgs && strings.HasPrefix(c.args, "<domain>") {
+		// The domain can be omitted if the profile has a default one
+		profile, err := app.loadProfile()
+		if err != nil {
+			return err
+		}
+		if profile.Domain != "" {
+			positional = append([]string{profile.Domain}, positional...)
+		}
+	}
+	if len(positional) < c.minArgs || (c.maxArgs >= 0 && len(positional) > c.maxArgs) {
+		c.printUsage(app.stderr, path, fs)
+		return usagef("%s: wrong number of arguments", path)
+	}
+
+	return c.run(ctx, app, positional)
+}
+
+// flagSet returns a silent flag set with the command's flags.
+func (c *command) flagSet(path string) *flag.FlagSet {
+	fs := flag.NewFlagSet(path, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	if c.flags != nil {
+		c.flags(fs)
+	}
+	return fs
+}
+
+// subcommand returns the subcommand with the name, or nil.
+func (c *command) subcommand(name string) *command {
+	for _, sub := range c.commands {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// parseInterspersed parses flags placed anywhere among the positional arguments, which the flag
+// package alone only accepts before them. Arguments after "--" are always positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+
+		// Parsing stops either at a "--" terminator, which the flag package drops, or at a positional argument
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// printUsage writes the usage of the command to w.
+func (c *command) printUsage(w io.Writer, path string, fs *flag.FlagSet) {
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+
+	synopsis := path
+	if hasFlags {
+		synopsis += " [flags]"
+	}
+	if c.commands != nil {
+		synopsis += " <command> [arguments]"
+	} else if c.args != "" {
+		synopsis += " " + c.args
+	}
+	fmt.Fprintf(w, "Usage: %s\n", synopsis)
+	if c.summary != "" {
+		fmt.Fprintf(w, "\n%s\n", c.summary)
+	}
+
+	if c.commands != nil {
+		fmt.Fprintf(w, "\nCommands:\n")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, sub := range c.commands {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.name, sub.summary)
+		}
+		tw.Flush()
+	}
+
+	if hasFlags {
+		fmt.Fprintf(w, "\nFlags:\n")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+		fs.SetOutput(io.Discard)
+	}
+
+	if c.commands != nil {
+		fmt.Fprintf(w, "\nRun '%s <command> --help' for details about a command.\n", path)
+	}
+}
+
+// subdomainArg converts a record name given on the command line, where "@" is the apex, to the
+// name used by the API.
+func subdomainArg(name string) string {
+	if name == "@" {
+		return ""
+	}
+	return name
+}
+
+// subdomainPtr converts a record name given on the command line to the subdomain argument of the
+// by-type calls, nil for the apex.
+func subdomainPtr(name string) *string {
+	if name = subdomainArg(name); name == "" {
+		return nil
+	}
+	return &name
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// completeCommand is the hidden command run by the completion scripts. Its arguments are the words
+// of the command line after the program name, the last one being the word to complete, and it
+// prints one candidate per line, followed by a tab and a description if there is one.
+const completeCommand = "__complete"
+
+const (
+	completionTTL     = 2 * time.Minute // Age after which cached domains and records are fetched again
+	completionTimeout = 5 * time.Second // Limit of the API calls, so a slow API does not hang the shell
+	descriptionLength = 60              // Maximum length of candidate descriptions
+)
+
+// recordTypes are the record types suggested for the type argument of dns create.
+var recordTypes = []porkbun.DnsRecordType{
+	porkbun.A, porkbun.AAAA, porkbun.CNAME, porkbun.ALIAS, porkbun.MX, porkbun.TXT, porkbun.NS,
+	porkbun.SRV, porkbun.TLSA, porkbun.CAA, porkbun.HTTPS, porkbun.SVCB,
+}
+
+// Completion scripts by shell. They call the completeCommand of the program being completed.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for porkbun, load with: source <(porkbun completion bash)
+_porkbun_complete() {
+    local IFS=$'\n'
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    COMPREPLY=($(compgen -W "$("${COMP_WORDS[0]}" ` + completeCommand + ` "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1)" -- "$cur"))
+}
+complete -F _porkbun_complete porkbun
+`,
+	"zsh": `#compdef porkbun
+# zsh completion for porkbun, load with: source <(porkbun completion zsh)
+_porkbun() {
+    local -a candidates
+    local line value desc
+    for line in "${(@f)$(${words[1]} ` + completeCommand + ` "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -n $line ]] || continue
+        value=${line%%$'\t'*}
+        desc=${line#*$'\t'}
+        if [[ $desc == $line ]]; then
+            candidates+=("${value//:/\\:}")
+        else
+            candidates+=("${value//:/\\:}:$desc")
+        fi
+    done
+    _describe porkbun candidates
+}
+compdef _porkbun porkbun
+`,
+	"fish": `# fish completion for porkbun, load with: porkbun completion fish | source
+function __porkbun_complete
+    set -l tokens (commandline -opc)
+    set -l current (commandline -ct)
+    set -l cmd $tokens[1]
+    set -e tokens[1]
+    $cmd ` + completeCommand + ` $tokens "$current" 2>/dev/null
+end
+complete -c porkbun -f -a '(__porkbun_complete)'
+`,
+}
+
+// completionCommand returns the completion command.
+func completionCommand() *command {
+	shells := make([]string, 0, len(completionScripts))
+	for shell := range completionScripts {
+		shells = append(shells, shell)
+	}
+	sort.Strings(shells)
+
+	return &command{
+		name:    "completion",
+		args:    "<shell>",
+		summary: "Print the completion script of a shell: " + strings.Join(shells, ", "),
+		minArgs: 1,
+		maxArgs: 1,
+		run: func(ctx context.Context, a *app, args []string) error {
+			script, ok := completionScripts[args[0]]
+			if !ok {
+				return usagef("porkbun completion: unknown shell %q, expected one of %s", args[0], strings.Join(shells, ", "))
+			}
+			fmt.Fprint(a.stdout, script)
+			return nil
+		},
+		complete: func(ctx context.Context, a *app, args []string) []candidate {
+			if len(args) > 0 {
+				return nil
+			}
+			candidates := make([]candidate, len(shells))
+			for i, shell := range shells {
+				candidates[i] = candidate{Value: shell}
+			}
+			return candidates
+		},
+	}
+}
+
+// candidate is a completion suggestion.
+type candidate struct {
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+// complete prints the candidates for the last of words, the command line after the program name.
+// Errors are ignored, as they cannot be shown while completing.
+func (a *app) complete(ctx context.Context, root *command, words []string) {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current, words := words[len(words)-1], words[:len(words)-1]
+
+	c, fs := root, root.flagSet(root.name)
+	var positional []string
+	var pending *flag.Flag // Flag whose value is being completed
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if word == "--" {
+			positional = append(positional, words[i+1:]...)
+			break
+		}
+		if strings.HasPrefix(word, "-") && len(word) > 1 {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			f := fs.Lookup(name)
+			if f == nil {
+				continue
+			}
+			if !hasValue {
+				if isBoolFlag(f) {
+					value = "true"
+				} else if i+1 < len(words) {
+					i++
+					value = words[i]
+				} else {
+					pending = f
+					break
+				}
+			}
+			// Setting the flags selects the profile and base URL used for the API calls
+			_ = fs.Set(name, value)
+			continue
+		}
+		if c.commands != nil {
+			if c = c.subcommand(word); c == nil {
+				return
+			}
+			fs = c.flagSet(c.name)
+			continue
+		}
+		positional = append(positional, word)
+	}
+
+	var candidates []candidate
+	switch {
+	case pending != nil:
+		// Flag values are free-form
+	case strings.HasPrefix(current, "-"):
+		fs.VisitAll(func(f *flag.Flag) {
+			candidates = append(candidates, candidate{Value: "-" + f.Name, Description: f.Usage})
+		})
+	case c.commands != nil:
+		for _, sub := range c.commands {
+			candidates = append(candidates, candidate{Value: sub.name, Description: sub.summary})
+		}
+	default:
+		ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+		defer cancel()
+
+		if tokens := strings.Fields(c.args); len(positional) == 0 && len(tokens) > 0 && tokens[0] == "<domain>" {
+			candidates = a.completeDomains(ctx)
+		} else if c.complete != nil {
+			candidates = c.complete(ctx, a, positional)
+		}
+	}
+
+	for _, cand := range candidates {
+		if !strings.HasPrefix(cand.Value, current) {
+			continue
+		}
+		if cand.Description == "" {
+			fmt.Fprintln(a.stdout, cand.Value)
+		} else {
+			fmt.Fprintf(a.stdout, "%s\t%s\n", cand.Value, cand.Description)
+		}
+	}
+}
+
+// isBoolFlag reports whether the flag takes no value.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// completeDomains suggests the domains of the account.
+func (a *app) completeDomains(ctx context.Context) []candidate {
+	return a.cached("domains", func(client *porkbun.Client) ([]candidate, error) {
+		domains, err := client.Domains.ListAllDomains(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := make([]candidate, len(domains))
+		for i, d := range domains {
+			candidates[i] = candidate{Value: d.Domain, Description: d.Status}
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].Value < candidates[j].Value })
+		return candidates, nil
+	})
+}
+
+// completeRecordIDs suggests the record IDs of the domain given as first argument, described by
+// their name, type and content.
+func completeRecordIDs(ctx context.Context, a *app, args []string) []candidate {
+	if len(args) != 1 {
+		return nil
+	}
+	domain := strings.ToLower(args[0])
+
+	return a.cached("records "+domain, func(client *porkbun.Client) ([]candidate, error) {
+		resp, err := client.Dns.GetRecords(ctx, domain, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		records := resp.Records
+		sort.Slice(records, func(i, j int) bool { return recordID(records[i]) < recordID(records[j]) })
+
+		candidates := make([]candidate, 0, len(records))
+		for _, r := range records {
+			if r.ID == nil {
+				continue
+			}
+			name := porkbun.RelativeName(r.Name, domain)
+			if name == "" {
+				name = "@"
+			}
+			desc := fmt.Sprintf("%s %s %s", name, r.Type, r.Content)
+			candidates = append(candidates, candidate{Value: strconv.FormatInt(*r.ID, 10), Description: truncate(desc)})
+		}
+		return candidates, nil
+	})
+}
+
+// completeRecordTypes suggests the record types for the second argument of dns create.
+func completeRecordTypes(ctx context.Context, a *app, args []string) []candidate {
+	if len(args) != 1 {
+		return nil
+	}
+	candidates := make([]candidate, len(recordTypes))
+	for i, t := range recordTypes {
+		candidates[i] = candidate{Value: string(t)}
+	}
+	return candidates
+}
+
+// recordID returns the ID of a record, 0 if it has none.
+func recordID(r porkbun.DnsRecord) int64 {
+	if r.ID == nil {
+		return 0
+	}
+	return *r.ID
+}
+
+// truncate shortens a description to descriptionLength and keeps it on one line.
+func truncate(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > descriptionLength {
+		return string(r[:descriptionLength-1]) + "…"
+	}
+	return s
+}
+
+// cacheEntry is the content of a completion cache file.
+type cacheEntry struct {
+	Time       time.Time   `json:"time"`
+	Candidates []candidate `json:"candidates"`
+}
+
+// cacheDir returns the directory of the completion cache, $PORKBUN_CACHE_DIR or porkbun in the
+// user cache directory.
+func (a *app) cacheDir() (string, error) {
+	if dir := a.getenv("PORKBUN_CACHE_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "porkbun"), nil
+}
+
+// cached returns the candidates stored under key if they are younger than completionTTL, or else
+// loads and stores them. Entries are per account and API, so profiles do not see each other's data.
+func (a *app) cached(key string, load func(client *porkbun.Client) ([]candidate, error)) []candidate {
+	profile, err := a.loadProfile()
+	if err != nil || !profile.HasCredentials() {
+		return nil
+	}
+
+	var path string
+	if dir, err := a.cacheDir(); err == nil {
+		sum := sha256.Sum256([]byte(profile.ApiKey + "\n" + a.baseURL + "\n" + key))
+		path = filepath.Join(dir, "completion-"+hex.EncodeToString(sum[:12])+".json")
+
+		var entry cacheEntry
+		if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &entry) == nil &&
+			time.Since(entry.Time) >= 0 && time.Since(entry.Time) < completionTTL {
+			return entry.Candidates
+		}
+	}
+
+	client, err := a.client(true)
+	if err != nil {
+		return nil
+	}
+	candidates, err := load(client)
+	if err != nil {
+		return nil
+	}
+
+	if path != "" {
+		writeCache(path, &cacheEntry{Time: time.Now(), Candidates: candidates})
+	}
+	return candidates
+}
+
+// writeCache stores the entry, replacing the file atomically. Failures only cost a later API call.
+func writeCache(path string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".completion-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(tmp.Name(), path) != nil {
+		os.Remove(tmp.Name())
+	}
+}
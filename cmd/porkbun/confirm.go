@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/output"
+	"github.com/tuzzmaniandevil/porkbun-go/safeguard"
+)
+
+// Errors returned when a destructive command does not go ahead.
+var (
+	errAborted   = errors.New("aborted, nothing was changed")
+	errProtected = errors.New("refusing to change protected records without -force")
+)
+
+// confirmFlags holds the -yes and -force flags of destructive commands.
+type confirmFlags struct {
+	yes   bool // Do not ask for confirmation
+	force bool // Allow changing protected records
+}
+
+// register adds the flags to fs.
+func (c *confirmFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&c.yes, "yes", false, "Do not ask for confirmation")
+	fs.BoolVar(&c.force, "force", false, "Allow changing records matching a protected pattern")
+}
+
+// guard creates a safeguard for the client's DNS records, with the protected patterns of the profile.
+func (a *app) guard(client *porkbun.Client) (*safeguard.Guard, error) {
+	profile, err := a.loadProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	options := &safeguard.Options{}
+	if profile.Protected != nil {
+		rules, err := safeguard.ParseRules(profile.Protected)
+		if err != nil {
+			return nil, err
+		}
+		options.Rules = rules
+	}
+	return safeguard.New(client.Dns, options), nil
+}
+
+// confirmRecords shows the records about to be changed and asks for confirmation. Protected
+// records are refused unless -force is set.
+func (a *app) confirmRecords(flags *confirmFlags, action string, preview *safeguard.Preview) error {
+	fmt.Fprintf(a.stderr, "The following records of %s will be %s:\n\n", preview.Domain, action)
+	if err := output.Records(a.stderr, preview.Records, &output.Options{Domain: preview.Domain}); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.stderr)
+
+	if len(preview.Protected) > 0 {
+		fmt.Fprintf(a.stderr, "%d of them match a protected pattern.\n", len(preview.Protected))
+		if !flags.force {
+			return errProtected
+		}
+	}
+	return a.confirm(flags, preview.Domain)
+}
+
+// confirmEdit shows the record about to be edited before and after the change and asks for
+// confirmation. Editing a protected record, or into one as reported by protected, is refused
+// unless -force is set.
+func (a *app) confirmEdit(flags *confirmFlags, preview *safeguard.Preview, edited porkbun.DnsRecord, protected bool) error {
+	options := &output.Options{Domain: preview.Domain}
+	fmt.Fprintf(a.stderr, "The following record of %s will be edited:\n\n", preview.Domain)
+	if err := output.Records(a.stderr, preview.Records, options); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "\ninto:\n\n")
+	if err := output.Records(a.stderr, []porkbun.DnsRecord{edited}, options); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.stderr)
+
+	if len(preview.Protected) > 0 || protected {
+		fmt.Fprintln(a.stderr, "The record matches a protected pattern.")
+		if !flags.force {
+			return errProtected
+		}
+	}
+	return a.confirm(flags, preview.Domain)
+}
+
+// confirm asks the user to type the domain to go ahead, unless -yes is set.
+func (a *app) confirm(flags *confirmFlags, domain string) error {
+	if flags.yes {
+		return nil
+	}
+
+	fmt.Fprintf(a.stderr, "Type %q to confirm: ", domain)
+	line, err := bufio.NewReader(a.stdin).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(a.stderr)
+		return errAborted
+	}
+	if !strings.EqualFold(strings.TrimSpace(line), domain) {
+		return errAborted
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/output"
+)
+
+// dnsCommand returns the dns command group.
+func dnsCommand() *command {
+	return &command{
+		name:    "dns",
+		summary: "Manage DNS records",
+		commands: []*command{
+			dnsListCommand(),
+			dnsCreateCommand(),
+			dnsEditCommand(),
+			dnsDeleteCommand(),
+		},
+	}
+}
+
+// dnsListCommand returns the dns list command.
+func dnsListCommand() *command {
+	var recordType, name string
+	var id int64
+	var out outputFlags
+
+	return &command{
+		name:    "list",
+		args:    "<domain>",
+		summary: "List the DNS records of a domain, optionally filtered by type and name or by ID",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&recordType, "type", "", "Only list records of this type")
+			fs.StringVar(&name, "name", "", "Only list records with this name, \"@\" for the apex (requires -type)")
+			fs.Int64Var(&id, "id", 0, "Only list the record with this ID")
+			out.register(fs, output.RecordColumns())
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			domain := args[0]
+			if name != "" && recordType == "" {
+				return usagef("porkbun dns list: -name requires -type")
+			}
+			if id != 0 && recordType != "" {
+				return usagef("porkbun dns list: -id cannot be combined with -type")
+			}
+			options, err := out.options(domain)
+			if err != nil {
+				return err
+			}
+
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			var resp *porkbun.GetRecordsResponse
+			switch {
+			case recordType != "":
+				resp, err = client.Dns.GetRecordsByType(ctx, domain, parseRecordType(recordType), subdomainPtr(name))
+			case id != 0:
+				resp, err = client.Dns.GetRecords(ctx, domain, &id)
+			default:
+				resp, err = client.Dns.GetRecords(ctx, domain, nil)
+			}
+			if err != nil {
+				return err
+			}
+
+			return writeOutput(output.Records(a.stdout, resp.Records, options))
+		},
+	}
+}
+
+// dnsCreateCommand returns the dns create command.
+func dnsCreateCommand() *command {
+	var ttl, prio, notes string
+
+	return &command{
+		name:     "create",
+		args:     "<domain> <type> <name> <content>",
+		summary:  "Create a DNS record, the name being relative to the domain or \"@\" for the apex",
+		minArgs:  4,
+		maxArgs:  4,
+		complete: completeRecordTypes,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&ttl, "ttl", "", "Time to live in seconds, the API default if empty")
+			fs.StringVar(&prio, "prio", "", "Priority, for MX and SRV records")
+			fs.StringVar(&notes, "notes", "", "Notes stored with the record")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Dns.CreateRecord(ctx, args[0], &porkbun.DnsRecord{
+				Name:    subdomainArg(args[2]),
+				Type:    parseRecordType(args[1]),
+				Content: args[3],
+				TTL:     ttl,
+				Prio:    prio,
+				Notes:   notes,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Created record %d\n", resp.ID)
+			return nil
+		},
+	}
+}
+
+// dnsEditCommand returns the dns edit command.
+func dnsEditCommand() *command {
+	var recordType, name, content, ttl, prio string
+	var confirm confirmFlags
+
+	return &command{
+		name:     "edit",
+		args:     "<domain> <id>",
+		summary:  "Edit a DNS record, keeping the current value of every field not given as a flag, after confirmation",
+		minArgs:  2,
+		maxArgs:  2,
+		complete: completeRecordIDs,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&recordType, "type", "", "New record type")
+			fs.StringVar(&name, "name", "", "New name relative to the domain, \"@\" for the apex")
+			fs.StringVar(&content, "content", "", "New content")
+			fs.StringVar(&ttl, "ttl", "", "New time to live in seconds")
+			fs.StringVar(&prio, "prio", "", "New priority")
+			confirm.register(fs)
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			domain := args[0]
+			id, err := parseID(args[1])
+			if err != nil {
+				return err
+			}
+
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			guard, err := a.guard(client)
+			if err != nil {
+				return err
+			}
+			preview, err := guard.PreviewRecord(ctx, domain, id)
+			if err != nil {
+				return err
+			}
+			if len(preview.Records) == 0 {
+				return fmt.Errorf("record %d not found in %s", id, domain)
+			}
+			current := preview.Records[0]
+
+			edit := &porkbun.EditRecord{
+				Name:    porkbun.RelativeName(current.Name, domain),
+				Type:    current.Type,
+				Content: current.Content,
+				TTL:     current.TTL,
+				Prio:    current.Prio,
+			}
+			if recordType != "" {
+				edit.Type = parseRecordType(recordType)
+			}
+			if name != "" {
+				edit.Name = subdomainArg(name)
+			}
+			if content != "" {
+				edit.Content = content
+			}
+			if ttl != "" {
+				edit.TTL = ttl
+			}
+			if prio != "" {
+				edit.Prio = prio
+			}
+
+			// The edited record is checked too, so a record cannot be turned into a protected one
+			// unnoticed
+			edited := porkbun.DnsRecord{
+				ID:      current.ID,
+				Name:    domain,
+				Type:    edit.Type,
+				Content: edit.Content,
+				TTL:     edit.TTL,
+				Prio:    edit.Prio,
+			}
+			if edit.Name != "" {
+				edited.Name = edit.Name + "." + domain
+			}
+			if err := a.confirmEdit(&confirm, preview, edited, guard.Protected(edited, domain)); err != nil {
+				return err
+			}
+
+			if _, err := client.Dns.EditRecord(ctx, domain, id, edit); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Updated record %d\n", id)
+			return nil
+		},
+	}
+}
+
+// dnsDeleteCommand returns the dns delete command.
+func dnsDeleteCommand() *command {
+	var recordType, name string
+	var confirm confirmFlags
+
+	return &command{
+		name:     "delete",
+		args:     "<domain> [<id>]",
+		summary:  "Delete a DNS record by ID, or all records of a type and name with -type, after confirmation",
+		minArgs:  1,
+		maxArgs:  2,
+		complete: completeRecordIDs,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&recordType, "type", "", "Delete all records of this type, instead of a single record")
+			fs.StringVar(&name, "name", "", "Name of the records deleted with -type, \"@\" or empty for the apex")
+			confirm.register(fs)
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			domain := args[0]
+			if (len(args) == 2) == (recordType != "") {
+				return usagef("porkbun dns delete: give either a record ID or -type")
+			}
+			if name != "" && recordType == "" {
+				return usagef("porkbun dns delete: -name requires -type")
+			}
+
+			var id int64
+			if len(args) == 2 {
+				var err error
+				if id, err = parseID(args[1]); err != nil {
+					return err
+				}
+			}
+
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			guard, err := a.guard(client)
+			if err != nil {
+				return err
+			}
+
+			if recordType != "" {
+				t := parseRecordType(recordType)
+				preview, err := guard.PreviewByType(ctx, domain, t, subdomainPtr(name))
+				if err != nil {
+					return err
+				}
+				if len(preview.Records) == 0 {
+					fmt.Fprintf(a.stdout, "No %s records of %s to delete\n", t, displayName(subdomainArg(name)))
+					return nil
+				}
+				if err := a.confirmRecords(&confirm, "deleted", preview); err != nil {
+					return err
+				}
+
+				if _, err := client.Dns.DeleteRecordByType(ctx, domain, t, subdomainPtr(name)); err != nil {
+					return err
+				}
+				fmt.Fprintf(a.stdout, "Deleted %s records of %s\n", t, displayName(subdomainArg(name)))
+				return nil
+			}
+
+			preview, err := guard.PreviewRecord(ctx, domain, id)
+			if err != nil {
+				return err
+			}
+			if len(preview.Records) == 0 {
+				return fmt.Errorf("record %d not found in %s", id, domain)
+			}
+			if err := a.confirmRecords(&confirm, "deleted", preview); err != nil {
+				return err
+			}
+
+			if _, err := client.Dns.DeleteRecord(ctx, domain, id); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stdout, "Deleted record %d\n", id)
+			return nil
+		},
+	}
+}
+
+// parseRecordType converts a record type given on the command line, in any case.
+func parseRecordType(s string) porkbun.DnsRecordType {
+	return porkbun.DnsRecordType(strings.ToUpper(s))
+}
+
+// parseID parses a record ID argument.
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, usagef("invalid record ID %q", s)
+	}
+	return id, nil
+}
+
+// displayName shows the apex as "@".
+func displayName(name string) string {
+	if name == "" {
+		return "@"
+	}
+	return name
+}
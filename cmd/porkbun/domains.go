@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/output"
+)
+
+// domainsCommand returns the domains command group.
+func domainsCommand() *command {
+	return &command{
+		name:    "domains",
+		summary: "Manage the domains of the account",
+		commands: []*command{
+			domainsListCommand(),
+		},
+	}
+}
+
+// domainsListCommand returns the domains list command.
+func domainsListCommand() *command {
+	var out outputFlags
+
+	return &command{
+		name:    "list",
+		summary: "List all domains of the account, with their labels if the labels column is selected",
+		flags: func(fs *flag.FlagSet) {
+			out.register(fs, output.DomainColumns())
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			options, err := out.options("")
+			if err != nil {
+				return err
+			}
+
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			var listOptions porkbun.DomainListOptions
+			if out.hasColumn("labels") {
+				listOptions.IncludeLabels = porkbun.String("yes")
+			}
+			domains, err := client.Domains.ListAllDomains(ctx, &listOptions)
+			if err != nil {
+				return err
+			}
+			return writeOutput(output.Domains(a.stdout, domains, options))
+		},
+	}
+}
+
+// nsCommand returns the ns command group.
+func nsCommand() *command {
+	return &command{
+		name:    "ns",
+		summary: "Manage the name servers of a domain",
+		commands: []*command{
+			nsGetCommand(),
+			nsSetCommand(),
+		},
+	}
+}
+
+// nsGetCommand returns the ns get command.
+func nsGetCommand() *command {
+	var out outputFlags
+
+	return &command{
+		name:    "get",
+		args:    "<domain>",
+		summary: "Show the name servers of a domain",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(fs *flag.FlagSet) {
+			out.register(fs, output.NameServerColumns())
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			options, err := out.options("")
+			if err != nil {
+				return err
+			}
+
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Domains.GetNameServers(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return writeOutput(output.NameServers(a.stdout, resp.NS, options))
+		},
+	}
+}
+
+// nsSetCommand returns the ns set command.
+func nsSetCommand() *command {
+	var confirm confirmFlags
+
+	return &command{
+		name:    "set",
+		args:    "<domain> <nameserver>...",
+		summary: "Replace the name servers of a domain, after confirmation",
+		minArgs: 2,
+		maxArgs: -1,
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&confirm.yes, "yes", false, "Do not ask for confirmation")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			domain := args[0]
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			current, err := client.Domains.GetNameServers(ctx, domain)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stderr, "The name servers of %s will be replaced:\n\n", domain)
+			fmt.Fprintf(a.stderr, "  current: %s\n", strings.Join(current.NS, ", "))
+			fmt.Fprintf(a.stderr, "  new:     %s\n\n", strings.Join(args[1:], ", "))
+			if err := a.confirm(&confirm, domain); err != nil {
+				return err
+			}
+
+			ns := porkbun.NameServers(args[1:])
+			if _, err := client.Domains.UpdateNameServers(ctx, domain, &ns); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stdout, "Updated the name servers of %s\n", domain)
+			return nil
+		},
+	}
+}
+
+// forwardCommand returns the forward command group.
+func forwardCommand() *command {
+	return &command{
+		name:    "forward",
+		summary: "Manage the URL forwards of a domain",
+		commands: []*command{
+			forwardListCommand(),
+			forwardAddCommand(),
+			forwardDeleteCommand(),
+		},
+	}
+}
+
+// forwardListCommand returns the forward list command.
+func forwardListCommand() *command {
+	var out outputFlags
+
+	return &command{
+		name:    "list",
+		args:    "<domain>",
+		summary: "List the URL forwards of a domain",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(fs *flag.FlagSet) {
+			out.register(fs, output.ForwardColumns())
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			options, err := out.options("")
+			if err != nil {
+				return err
+			}
+
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Domains.GetDomainURLForwarding(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return writeOutput(output.Forwards(a.stdout, resp.Forwards, options))
+		},
+	}
+}
+
+// forwardAddCommand returns the forward add command.
+func forwardAddCommand() *command {
+	var subdomain, forwardType string
+	var includePath, wildcard bool
+
+	return &command{
+		name:    "add",
+		args:    "<domain> <location>",
+		summary: "Forward a domain or subdomain to a URL",
+		minArgs: 2,
+		maxArgs: 2,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&subdomain, "subdomain", "", "Subdomain to forward, the domain itself if empty")
+			fs.StringVar(&forwardType, "type", string(porkbun.Temporary), "Forward type: temporary or permanent")
+			fs.BoolVar(&includePath, "include-path", false, "Append the requested path to the location")
+			fs.BoolVar(&wildcard, "wildcard", false, "Also forward all subdomains")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			t := porkbun.ForwardType(strings.ToLower(forwardType))
+			if t != porkbun.Temporary && t != porkbun.Permanent {
+				return usagef("porkbun forward add: -type must be temporary or permanent")
+			}
+
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.Domains.AddDomainUrlForward(ctx, args[0], &porkbun.UrlForward{
+				Subdomain:   subdomainArg(subdomain),
+				Location:    args[1],
+				Type:        t,
+				IncludePath: yesNo(includePath),
+				Wildcard:    yesNo(wildcard),
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stdout, "Added URL forward to %s\n", args[1])
+			return nil
+		},
+	}
+}
+
+// forwardDeleteCommand returns the forward delete command.
+func forwardDeleteCommand() *command {
+	var confirm confirmFlags
+
+	return &command{
+		name:    "delete",
+		args:    "<domain> <id>",
+		summary: "Delete a URL forward, after confirmation",
+		minArgs: 2,
+		maxArgs: 2,
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&confirm.yes, "yes", false, "Do not ask for confirmation")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			domain, id := args[0], args[1]
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Domains.GetDomainURLForwarding(ctx, domain)
+			if err != nil {
+				return err
+			}
+			var matched []porkbun.UrlForwardData
+			for _, forward := range resp.Forwards {
+				if forward.Id == id {
+					matched = append(matched, forward)
+				}
+			}
+			if len(matched) == 0 {
+				return fmt.Errorf("URL forward %s not found in %s", id, domain)
+			}
+
+			fmt.Fprintf(a.stderr, "The following URL forward of %s will be deleted:\n\n", domain)
+			if err := output.Forwards(a.stderr, matched, nil); err != nil {
+				return err
+			}
+			fmt.Fprintln(a.stderr)
+			if err := a.confirm(&confirm, domain); err != nil {
+				return err
+			}
+
+			if _, err := client.Domains.DeleteDomainUrlForward(ctx, domain, id); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stdout, "Deleted URL forward %s\n", id)
+			return nil
+		},
+	}
+}
+
+// yesNo formats a boolean as "yes" or "no", the values used by URL forwards.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,157 @@
+// Command porkbun manages Porkbun domains, DNS records, name servers, URL forwards and SSL bundles
+// from the command line.
+//
+//	porkbun [flags] <command> [arguments]
+//
+// The commands are ping, domains list, dns list/create/edit/delete, ns get/set,
+// forward list/add/delete, ssl get, pricing, snapshot create/restore/diff, watch and completion.
+// Every command accepts --help.
+//
+// The snapshot commands back up the records, name servers and URL forwards of all domains to a JSON
+// file, and restore them after showing the changes. Restoring only adds and modifies entries unless
+// -prune is given. Snapshot diff compares two snapshot files, or a snapshot with the live
+// configuration, in a readable or JSON form.
+//
+// The watch command polls the account and prints every change, such as records edited in the
+// Porkbun web UI, until it is interrupted. With -webhook it also posts them as JSON, signed with
+// PORKBUN_WEBHOOK_SECRET if set, and -expiry-days adds daily reminders of expiring domains.
+//
+// The completion command prints a bash, zsh or fish script completing commands, flags, domains and
+// record IDs. Domains and records are cached for two minutes in the user cache directory, or in
+// PORKBUN_CACHE_DIR if set, so completion stays fast.
+//
+// Credentials are read from a named profile of the configuration file, by default
+// ~/.config/porkbun/config.yaml, selected with -profile or PORKBUN_PROFILE. The PORKBUN_API_KEY and
+// PORKBUN_API_SECRET environment variables override the profile, or replace it when there is no
+// configuration file. If the profile has a default domain, commands taking a domain as their first
+// argument can omit it.
+//
+// Destructive commands show the records they would change and ask to type the domain name to go
+// ahead, unless -yes is given. Records matching a protected pattern, by default the apex MX records
+// and all NS records, additionally require -force. The patterns can be set per profile.
+//
+// The exit status is 0 on success, 1 if the command failed, for example because the API returned
+// an error, and 2 if the command line is invalid.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/config"
+)
+
+// errMissingCredentials is returned by commands that need credentials when none are set.
+var errMissingCredentials = errors.New("no credentials: set PORKBUN_API_KEY and PORKBUN_API_SECRET, or select a profile with -profile")
+
+// app holds the state shared by all commands.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	ipv4Only    bool   // Use the IPv4-only API endpoint
+	baseURL     string // Custom API base URL
+	configPath  string // Configuration file, empty for the default
+	profileName string // Profile of the configuration file, empty for the default
+
+	profile *config.Profile // Loaded on first use
+}
+
+// loadProfile loads the selected profile with the environment overrides applied.
+func (a *app) loadProfile() (*config.Profile, error) {
+	if a.profile == nil {
+		profile, err := config.LoadProfile(&config.LoadOptions{
+			Path:    a.configPath,
+			Profile: a.profileName,
+			Getenv:  a.getenv,
+		})
+		if err != nil {
+			return nil, err
+		}
+		a.profile = profile
+	}
+	return a.profile, nil
+}
+
+// client creates an API client. If auth is true, credentials must be set.
+func (a *app) client(auth bool) (*porkbun.Client, error) {
+	profile, err := a.loadProfile()
+	if err != nil {
+		return nil, err
+	}
+	if auth && !profile.HasCredentials() {
+		return nil, errMissingCredentials
+	}
+
+	options := profile.Options()
+	options.IPv4Only = options.IPv4Only || a.ipv4Only
+	options.BaseURL = a.baseURL
+	return porkbun.NewClient(options), nil
+}
+
+// rootCommand returns the command tree, with the global flags stored in a.
+func rootCommand(a *app) *command {
+	return &command{
+		name:    "porkbun",
+		summary: "Manage Porkbun domains, DNS records, name servers, URL forwards and SSL bundles.",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&a.ipv4Only, "ipv4-only", false, "Use the IPv4-only API endpoint")
+			fs.StringVar(&a.baseURL, "base-url", "", "Custom API base URL")
+			fs.StringVar(&a.configPath, "config", "", "Configuration file, defaults to $PORKBUN_CONFIG or ~/.config/porkbun/config.yaml")
+			fs.StringVar(&a.profileName, "profile", "", "Profile of the configuration file, defaults to $PORKBUN_PROFILE or the default profile")
+		},
+		commands: []*command{
+			pingCommand(),
+			domainsCommand(),
+			dnsCommand(),
+			nsCommand(),
+			forwardCommand(),
+			sslCommand(),
+			pricingCommand(),
+			snapshotCommand(),
+			watchCommand(),
+			completionCommand(),
+		},
+	}
+}
+
+// run runs the command line args and returns the exit status.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr, getenv: getenv}
+
+	root := rootCommand(a)
+	if len(args) > 0 && args[0] == completeCommand {
+		a.complete(ctx, root, args[1:])
+		return exitOK
+	}
+
+	err := root.execute(ctx, a, "porkbun", args)
+
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "porkbun: %v\n", err)
+		return exitError
+	}
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv)
+	stop()
+	os.Exit(code)
+}
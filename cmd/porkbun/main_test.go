@@ -0,0 +1,716 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/internal/testcert"
+	"github.com/tuzzmaniandevil/porkbun-go/notify"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+)
+
+func setupServer(t *testing.T) *porkbuntest.Server {
+	server := porkbuntest.NewServer(nil)
+	t.Cleanup(server.Close)
+	server.AddDomain(porkbun.Domain{Domain: "example.com"})
+	return server
+}
+
+// noConfig returns an environment without credentials, whose configuration file does not exist
+// and whose cache directory is empty.
+func noConfig(t *testing.T) map[string]string {
+	return map[string]string{
+		"PORKBUN_CONFIG":    filepath.Join(t.TempDir(), "config.yaml"),
+		"PORKBUN_CACHE_DIR": t.TempDir(),
+	}
+}
+
+// runCLI runs the command line against the server, returning the exit status and output.
+func runCLI(t *testing.T, server *porkbuntest.Server, args ...string) (int, string, string) {
+	env := noConfig(t)
+	env["PORKBUN_API_KEY"] = porkbuntest.DefaultApiKey
+	env["PORKBUN_API_SECRET"] = porkbuntest.DefaultSecretApiKey
+	return runEnv(server, env, "", args...)
+}
+
+// runEnv runs the command line against the server with the environment and standard input.
+func runEnv(server *porkbuntest.Server, env map[string]string, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), append([]string{"-base-url", server.URL}, args...), strings.NewReader(stdin), &stdout, &stderr,
+		func(key string) string { return env[key] })
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Usage(t *testing.T) {
+	server := setupServer(t)
+
+	code, stdout, _ := runCLI(t, server, "--help")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "Usage: porkbun [flags] <command>")
+	assert.Contains(t, stdout, "forward")
+
+	// Every command has a help text
+	for _, args := range [][]string{
+		{"ping"}, {"domains"}, {"domains", "list"}, {"dns"}, {"dns", "list"}, {"dns", "create"}, {"dns", "edit"},
+		{"dns", "delete"}, {"ns", "get"}, {"ns", "set"}, {"forward", "list"}, {"forward", "add"},
+		{"forward", "delete"}, {"ssl", "get"}, {"pricing"}, {"snapshot", "create"}, {"snapshot", "restore"},
+		{"snapshot", "diff"}, {"watch"},
+		{"completion"},
+	} {
+		code, stdout, _ = runCLI(t, server, append(args, "--help")...)
+		assert.Equal(t, exitOK, code, args)
+		assert.Contains(t, stdout, "Usage: porkbun "+strings.Join(args, " "), args)
+	}
+
+	code, _, stderr := runCLI(t, server, "bogus")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, `unknown command "bogus"`)
+
+	code, _, stderr = runCLI(t, server, "dns", "create", "example.com", "A")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "wrong number of arguments")
+
+	code, _, _ = runCLI(t, server, "dns", "list", "-nope", "example.com")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runCLI(t, server, "dns", "delete", "example.com")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestRun_Credentials(t *testing.T) {
+	server := setupServer(t)
+
+	code, _, stderr := runEnv(server, noConfig(t), "", "ping")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "PORKBUN_API_KEY")
+
+	// Pricing does not need credentials
+	code, stdout, _ := runEnv(server, noConfig(t), "", "pricing", "-tld", "com")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "9.68")
+}
+
+func TestRun_Profile(t *testing.T) {
+	server := setupServer(t)
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1"})
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+profiles:
+  production:
+    api_key: `+porkbuntest.DefaultApiKey+`
+    secret_api_key: `+porkbuntest.DefaultSecretApiKey+`
+    domain: example.com
+  staging:
+    api_key: pk1_wrong
+    secret_api_key: sk1_wrong
+`), 0o600))
+	env := map[string]string{"PORKBUN_CONFIG": path}
+
+	code, _, _ := runEnv(server, env, "", "-profile", "production", "ping")
+	assert.Equal(t, exitOK, code)
+
+	// The default domain fills in a missing domain argument
+	code, stdout, _ := runEnv(server, env, "", "-profile", "production", "dns", "list", "-o", "csv", "-columns", "name,content")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "NAME,CONTENT\nwww,192.0.2.1\n", stdout)
+
+	code, _, stderr := runEnv(server, env, "", "-profile", "staging", "ping")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, porkbuntest.MsgInvalidApiKey)
+
+	// Environment variables override the profile
+	env["PORKBUN_PROFILE"] = "staging"
+	env["PORKBUN_API_KEY"] = porkbuntest.DefaultApiKey
+	env["PORKBUN_API_SECRET"] = porkbuntest.DefaultSecretApiKey
+	code, _, _ = runEnv(server, env, "", "ping")
+	assert.Equal(t, exitOK, code)
+
+	code, _, stderr = runEnv(server, env, "", "-profile", "client", "ping")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "unknown profile")
+
+	// Without a default domain the argument is still required
+	code, _, _ = runEnv(server, env, "", "dns", "list")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestRun_Ping(t *testing.T) {
+	code, stdout, _ := runCLI(t, setupServer(t), "ping")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Your IP: 127.0.0.1\n", stdout)
+}
+
+func TestRun_Domains(t *testing.T) {
+	server := setupServer(t)
+	server.AddDomain(porkbun.Domain{Domain: "example.org", Labels: []porkbun.Label{{ID: "1", Title: "prod"}}})
+
+	code, stdout, _ := runCLI(t, server, "domains", "list", "-columns", "domain,labels")
+	assert.Equal(t, exitOK, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Contains(t, lines[0], "LABELS")
+		assert.True(t, strings.HasPrefix(lines[1], "example.com "))
+		assert.Contains(t, lines[2], "prod")
+	}
+}
+
+func TestRun_Output(t *testing.T) {
+	server := setupServer(t)
+
+	code, _, _ := runCLI(t, server, "dns", "create", "example.com", "A", "www", "192.0.2.1")
+	assert.Equal(t, exitOK, code)
+
+	code, stdout, _ := runCLI(t, server, "dns", "list", "example.com", "-o", "csv", "-columns", "name,type,content")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "NAME,TYPE,CONTENT\nwww,A,192.0.2.1\n", stdout)
+
+	code, stdout, _ = runCLI(t, server, "dns", "list", "example.com", "-output", "json", "-columns", "name")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "[\n  {\n    \"name\": \"www\"\n  }\n]\n", stdout)
+
+	code, stdout, _ = runCLI(t, server, "pricing", "-tld", "com", "-o", "yaml", "-columns", "tld,registration")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "- tld: com\n  registration: \"9.68\"\n", stdout)
+
+	code, _, stderr := runCLI(t, server, "dns", "list", "example.com", "-o", "xml")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "unknown format")
+
+	code, _, stderr = runCLI(t, server, "ns", "get", "example.com", "-columns", "owner")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "unknown column")
+}
+
+func TestRun_DNS(t *testing.T) {
+	server := setupServer(t)
+
+	code, stdout, _ := runCLI(t, server, "dns", "create", "example.com", "a", "www", "192.0.2.1", "-ttl", "3600")
+	assert.Equal(t, exitOK, code)
+	assert.True(t, strings.HasPrefix(stdout, "Created record "))
+	id := strings.TrimSpace(strings.TrimPrefix(stdout, "Created record "))
+
+	code, _, _ = runCLI(t, server, "dns", "create", "example.com", "MX", "@", "mail.example.com", "-prio", "10")
+	assert.Equal(t, exitOK, code)
+
+	code, stdout, _ = runCLI(t, server, "dns", "list", "example.com")
+	assert.Equal(t, exitOK, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Regexp(t, `^\d+\s+@\s+MX\s+mail\.example\.com\s+600\s+10`, lines[1])
+		assert.Regexp(t, `^`+id+`\s+www\s+A\s+192\.0\.2\.1\s+3600`, lines[2])
+	}
+
+	// Edits show the record before and after, and need a confirmation
+	code, _, stderr := runCLI(t, server, "dns", "edit", "example.com", id, "-content", "192.0.2.2")
+	assert.Equal(t, exitError, code)
+	assert.Regexp(t, `(?s)will be edited:.*www\s+A\s+192\.0\.2\.1\s+3600.*into:.*www\s+A\s+192\.0\.2\.2\s+3600`, stderr)
+	assert.Contains(t, stderr, errAborted.Error())
+
+	// Edits keep the fields that are not given
+	code, _, _ = runCLI(t, server, "dns", "edit", "example.com", id, "-content", "192.0.2.2", "-yes")
+	assert.Equal(t, exitOK, code)
+	code, stdout, _ = runCLI(t, server, "dns", "list", "example.com", "-type", "A", "-name", "www")
+	assert.Equal(t, exitOK, code)
+	assert.Regexp(t, `www\s+A\s+192\.0\.2\.2\s+3600`, stdout)
+
+	code, _, stderr = runCLI(t, server, "dns", "edit", "example.com", "1", "-content", "192.0.2.2", "-yes")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "record 1 not found")
+
+	code, _, stderr = runCLI(t, server, "dns", "create", "example.com", "A", "www", "not-an-ip")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, porkbuntest.MsgInvalidContent)
+
+	code, stdout, _ = runCLI(t, server, "dns", "delete", "example.com", id, "-yes")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Deleted record "+id+"\n", stdout)
+
+	code, _, _ = runCLI(t, server, "dns", "delete", "example.com", "-type", "MX", "-yes", "-force")
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, server.Records("example.com"))
+}
+
+func TestRun_Confirmation(t *testing.T) {
+	server := setupServer(t)
+	www, _ := server.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.MX, Content: "mx.example.net", Prio: "10"})
+	env := noConfig(t)
+	env["PORKBUN_API_KEY"] = porkbuntest.DefaultApiKey
+	env["PORKBUN_API_SECRET"] = porkbuntest.DefaultSecretApiKey
+	id := strconv.FormatInt(www, 10)
+
+	// The preview is shown and a wrong or missing answer changes nothing
+	code, _, stderr := runEnv(server, env, "example.org\n", "dns", "delete", "example.com", id)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "The following records of example.com will be deleted")
+	assert.Regexp(t, `www\s+A\s+192\.0\.2\.1`, stderr)
+	assert.Contains(t, stderr, `Type "example.com" to confirm`)
+	assert.Contains(t, stderr, errAborted.Error())
+
+	code, _, _ = runEnv(server, env, "", "dns", "delete", "example.com", id)
+	assert.Equal(t, exitError, code)
+	assert.Len(t, server.Records("example.com"), 2)
+
+	code, stdout, _ := runEnv(server, env, "example.com\n", "dns", "delete", "example.com", id)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Deleted record "+id+"\n", stdout)
+
+	// Protected records need -force, even with -yes
+	code, _, stderr = runEnv(server, env, "", "dns", "delete", "example.com", "-type", "MX", "-yes")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "1 of them match a protected pattern")
+	assert.Contains(t, stderr, errProtected.Error())
+	assert.Len(t, server.Records("example.com"), 1)
+
+	code, stdout, _ = runEnv(server, env, "", "dns", "delete", "example.com", "-type", "TXT", "-yes")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "No TXT records of @ to delete\n", stdout)
+
+	// Name server changes are confirmed too
+	code, _, stderr = runEnv(server, env, "no\n", "ns", "set", "example.com", "ns1.example.net")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "new:     ns1.example.net")
+
+	code, _, _ = runEnv(server, env, "example.com\n", "ns", "set", "example.com", "ns1.example.net")
+	assert.Equal(t, exitOK, code)
+}
+
+func TestRun_ProtectedPatterns(t *testing.T) {
+	server := setupServer(t)
+	id, _ := server.AddRecord("example.com", porkbun.DnsRecord{Name: "vpn", Type: porkbun.A, Content: "192.0.2.1"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.MX, Content: "mx.example.net", Prio: "10"})
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+profiles:
+  default:
+    api_key: `+porkbuntest.DefaultApiKey+`
+    secret_api_key: `+porkbuntest.DefaultSecretApiKey+`
+    protected: ["A:vpn"]
+`), 0o600))
+	env := map[string]string{"PORKBUN_CONFIG": path}
+
+	// Editing a protected record needs -force and a confirmation
+	code, _, stderr := runEnv(server, env, "", "dns", "edit", "example.com", strconv.FormatInt(id, 10), "-content", "192.0.2.2")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, errProtected.Error())
+
+	code, _, _ = runEnv(server, env, "example.com\n", "dns", "edit", "example.com", strconv.FormatInt(id, 10), "-content", "192.0.2.2", "-force")
+	assert.Equal(t, exitOK, code)
+
+	// The profile patterns replace the defaults
+	code, _, _ = runEnv(server, env, "", "dns", "delete", "example.com", "-type", "MX", "-yes")
+	assert.Equal(t, exitOK, code)
+}
+
+func TestRun_EditIntoProtected(t *testing.T) {
+	server := setupServer(t)
+	id, _ := server.AddRecord("example.com", porkbun.DnsRecord{Name: "mail", Type: porkbun.A, Content: "192.0.2.1"})
+	args := []string{"dns", "edit", "example.com", strconv.FormatInt(id, 10), "-type", "MX", "-name", "@", "-content", "mx.example.net", "-yes"}
+
+	// Turning an unprotected record into an apex MX record matches the default MX:@ pattern
+	code, _, stderr := runCLI(t, server, args...)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, errProtected.Error())
+	assert.Equal(t, porkbun.A, server.Records("example.com")[0].Type)
+
+	code, _, _ = runCLI(t, server, append(args, "-force")...)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, porkbun.MX, server.Records("example.com")[0].Type)
+}
+
+func TestRun_CompletionScripts(t *testing.T) {
+	server := setupServer(t)
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		code, stdout, _ := runCLI(t, server, "completion", shell)
+		assert.Equal(t, exitOK, code, shell)
+		assert.Contains(t, stdout, completeCommand, shell)
+	}
+
+	code, _, stderr := runCLI(t, server, "completion", "tcsh")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "bash, fish, zsh")
+}
+
+func TestRun_Complete(t *testing.T) {
+	server := setupServer(t)
+	id, _ := server.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1"})
+	env := noConfig(t)
+	env["PORKBUN_API_KEY"] = porkbuntest.DefaultApiKey
+	env["PORKBUN_API_SECRET"] = porkbuntest.DefaultSecretApiKey
+	complete := func(words ...string) []string {
+		// The base URL is a global flag among the completed words
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), append([]string{completeCommand, "-base-url", server.URL}, words...),
+			strings.NewReader(""), &stdout, &stderr, func(key string) string { return env[key] })
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, stderr.String())
+		return strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	}
+
+	assert.Contains(t, complete(""), "dns\tManage DNS records")
+	assert.Equal(t, []string{"delete\tDelete a DNS record by ID, or all records of a type and name with -type, after confirmation"},
+		complete("dns", "del"))
+	assert.Contains(t, complete("dns", "delete", "-"), "-yes\tDo not ask for confirmation")
+	assert.Equal(t, []string{"A", "AAAA", "ALIAS"}, complete("dns", "create", "example.com", "A"))
+	assert.Equal(t, []string{""}, complete("-profile", ""))
+	assert.Equal(t, []string{""}, complete("bogus", ""))
+
+	// Domains and record IDs come from the API, and global flags are honored
+	assert.Equal(t, []string{"example.com\tACTIVE"}, complete("-ipv4-only", "dns", "delete", ""))
+	assert.Equal(t, []string{strconv.FormatInt(id, 10) + "\twww A 192.0.2.1"},
+		complete("dns", "delete", "-yes", "example.com", ""))
+	assert.Equal(t, []string{""}, complete("dns", "delete", "example.com", "1", ""))
+
+	// Results are cached until they expire
+	server.AddDomain(porkbun.Domain{Domain: "example.org"})
+	assert.Equal(t, []string{"example.com\tACTIVE"}, complete("ns", "get", ""))
+
+	files, err := filepath.Glob(filepath.Join(env["PORKBUN_CACHE_DIR"], "completion-*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	for _, file := range files {
+		data, err := json.Marshal(&cacheEntry{Time: time.Now().Add(-completionTTL)})
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(file, data, 0o600))
+	}
+	assert.Equal(t, []string{"example.com\tACTIVE", "example.org\tACTIVE"}, complete("ns", "get", ""))
+
+	// Without credentials nothing is suggested
+	delete(env, "PORKBUN_API_KEY")
+	assert.Equal(t, []string{""}, complete("ns", "get", ""))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "a b", truncate("a\tb\n"))
+	assert.Equal(t, strings.Repeat("x", descriptionLength-1)+"…", truncate(strings.Repeat("x", 100)))
+}
+
+func TestRun_NameServers(t *testing.T) {
+	server := setupServer(t)
+
+	code, _, _ := runCLI(t, server, "ns", "set", "-yes", "example.com", "ns1.example.net", "ns2.example.net")
+	assert.Equal(t, exitOK, code)
+
+	code, stdout, _ := runCLI(t, server, "ns", "get", "example.com")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "NAMESERVER\nns1.example.net\nns2.example.net\n", stdout)
+}
+
+func TestRun_Forwards(t *testing.T) {
+	server := setupServer(t)
+
+	code, _, _ := runCLI(t, server, "forward", "add", "example.com", "https://example.org", "-subdomain", "www", "-type", "permanent", "-wildcard")
+	assert.Equal(t, exitOK, code)
+
+	forwards := server.Forwards("example.com")
+	if assert.Len(t, forwards, 1) {
+		assert.Equal(t, porkbun.UrlForward{Subdomain: "www", Location: "https://example.org", Type: porkbun.Permanent,
+			IncludePath: "no", Wildcard: "yes"}, forwards[0].UrlForward)
+
+		code, stdout, _ := runCLI(t, server, "forward", "list", "example.com")
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, forwards[0].Id)
+
+		code, _, _ = runCLI(t, server, "forward", "delete", "example.com", forwards[0].Id, "-yes")
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, server.Forwards("example.com"))
+	}
+
+	code, _, _ = runCLI(t, server, "forward", "add", "example.com", "https://example.org", "-type", "sideways")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestRun_SSL(t *testing.T) {
+	server := setupServer(t)
+
+	code, _, stderr := runCLI(t, server, "ssl", "get", "example.com")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, porkbuntest.MsgSslNotReady)
+
+	bundle, err := testcert.Generate([]string{"example.com", "www.example.com"}, time.Now(), time.Now().Add(time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	server.SetSSL("example.com", porkbun.SslRetrieveResponse{
+		Certificatechain: bundle.CertificateChain,
+		Privatekey:       bundle.PrivateKey,
+		Publickey:        bundle.PublicKey,
+	})
+
+	code, stdout, _ := runCLI(t, server, "ssl", "get", "example.com")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "example.com,www.example.com")
+
+	code, stdout, _ = runCLI(t, server, "ssl", "get", "example.com", "-part", "bundle")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "-----BEGIN CERTIFICATE-----")
+	assert.Contains(t, stdout, "PRIVATE KEY-----")
+}
+
+func TestRun_Snapshot(t *testing.T) {
+	server := setupServer(t)
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1", TTL: "600"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.MX, Content: "mx.example.net", TTL: "600", Prio: "10"})
+	file := filepath.Join(t.TempDir(), "backup.json")
+
+	code, stdout, stderr := runCLI(t, server, "snapshot", "create", "-file", file)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "Saved 1 domains to "+file+"\n", stdout)
+
+	code, stdout, _ = runCLI(t, server, "snapshot", "create", "-domains", "example.com")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"content": "mx.example.net"`)
+
+	code, _, stderr = runCLI(t, server, "snapshot", "create", "-domains", "example.com,example.net")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "example.net:")
+	assert.Contains(t, stderr, "1 of 2 domains could not be captured")
+
+	code, stdout, _ = runCLI(t, server, "snapshot", "restore", file)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Nothing to restore\n", stdout)
+
+	// Break the configuration
+	for _, r := range server.Records("example.com") {
+		if r.Type == porkbun.A || r.Type == porkbun.MX {
+			code, _, stderr = runCLI(t, server, "dns", "delete", "example.com", strconv.FormatInt(*r.ID, 10), "-yes", "-force")
+			assert.Equal(t, exitOK, code, stderr)
+		}
+	}
+	code, _, stderr = runCLI(t, server, "dns", "create", "example.com", "TXT", "extra", "stray")
+	assert.Equal(t, exitOK, code, stderr)
+
+	code, stdout, _ = runCLI(t, server, "snapshot", "restore", file, "-dry-run", "-prune")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `- example.com record extra TXT "stray"`)
+	assert.Contains(t, stdout, `+ example.com record www A "192.0.2.1" ttl=600`)
+	assert.Contains(t, stdout, `+ example.com record @ MX "mx.example.net" ttl=600 prio=10`)
+	assert.Len(t, server.Records("example.com"), 1)
+
+	code, _, stderr = runCLI(t, server, "snapshot", "restore", file, "-domains", "example.org")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "domain not in snapshot")
+
+	env := noConfig(t)
+	env["PORKBUN_API_KEY"] = porkbuntest.DefaultApiKey
+	env["PORKBUN_API_SECRET"] = porkbuntest.DefaultSecretApiKey
+	code, _, stderr = runEnv(server, env, "nope\n", "snapshot", "restore", file)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, `Type "example.com" to confirm`)
+	assert.Len(t, server.Records("example.com"), 1)
+
+	code, stdout, _ = runEnv(server, env, "example.com\n", "snapshot", "restore", file)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Applied 2 changes\n", stdout)
+	assert.Len(t, server.Records("example.com"), 3)
+
+	code, stdout, _ = runCLI(t, server, "snapshot", "restore", file, "-prune", "-yes")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Applied 1 changes\n", stdout)
+
+	// Pruning protected records requires -force
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.MX, Content: "mx2.example.net", Prio: "20"})
+	code, _, stderr = runCLI(t, server, "snapshot", "restore", file, "-prune", "-yes")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "protected pattern")
+	assert.Len(t, server.Records("example.com"), 3)
+
+	code, _, _ = runCLI(t, server, "snapshot", "restore", file, "-prune", "-yes", "-force")
+	assert.Equal(t, exitOK, code)
+	assert.Len(t, server.Records("example.com"), 2)
+}
+
+func TestRun_SnapshotDiff(t *testing.T) {
+	server := setupServer(t)
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1", TTL: "600"})
+	dir := t.TempDir()
+	before, after := filepath.Join(dir, "before.json"), filepath.Join(dir, "after.json")
+
+	code, _, stderr := runCLI(t, server, "snapshot", "create", "-file", before)
+	assert.Equal(t, exitOK, code, stderr)
+
+	code, stdout, _ := runCLI(t, server, "snapshot", "diff", before)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "No changes\n", stdout)
+
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "new", Type: porkbun.TXT, Content: "hello", TTL: "600"})
+	server.AddDomain(porkbun.Domain{Domain: "example.org"})
+
+	code, stdout, _ = runCLI(t, server, "snapshot", "diff", before)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, `example.com
+  + record new TXT "hello" ttl=600
+
+example.org
+  + domain with 0 records and 0 forwards
+
+2 changes in 2 domains
+`, stdout)
+
+	code, _, stderr = runCLI(t, server, "snapshot", "create", "-file", after)
+	assert.Equal(t, exitOK, code, stderr)
+
+	code, stdout, _ = runCLI(t, server, "snapshot", "diff", "-domains", "example.com", "-o", "json", before, after)
+	assert.Equal(t, exitOK, code)
+	var report struct {
+		Changes []struct {
+			Domain string `json:"domain"`
+			Kind   string `json:"kind"`
+			Action string `json:"action"`
+		} `json:"changes"`
+	}
+	if assert.NoError(t, json.Unmarshal([]byte(stdout), &report)) && assert.Len(t, report.Changes, 1) {
+		assert.Equal(t, "example.com", report.Changes[0].Domain)
+		assert.Equal(t, "record", report.Changes[0].Kind)
+		assert.Equal(t, "added", report.Changes[0].Action)
+	}
+
+	code, _, _ = runCLI(t, server, "snapshot", "diff", "-o", "csv", before, after)
+	assert.Equal(t, exitUsage, code)
+
+	code, _, stderr = runCLI(t, server, "snapshot", "diff", "-domains", "example.org", before, after)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "domain not in snapshot")
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use, to read the output of a running command.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRun_Watch(t *testing.T) {
+	server := setupServer(t)
+	env := noConfig(t)
+	env["PORKBUN_API_KEY"] = porkbuntest.DefaultApiKey
+	env["PORKBUN_API_SECRET"] = porkbuntest.DefaultSecretApiKey
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stdout, stderr syncBuffer
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"-base-url", server.URL, "watch", "-interval", "10ms", "-o", "json"}, strings.NewReader(""),
+			&stdout, &stderr, func(key string) string { return env[key] })
+	}()
+
+	// Records added before the first poll are part of the initial state, so keep adding until one is reported
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; !strings.Contains(stdout.String(), "record_added") && time.Now().Before(deadline); i++ {
+		server.AddRecord("example.com", porkbun.DnsRecord{Name: "r" + strconv.Itoa(i), Type: porkbun.A, Content: "192.0.2.1"})
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	assert.Equal(t, exitOK, <-done, stderr.String())
+
+	line, _, _ := strings.Cut(stdout.String(), "\n")
+	var event struct {
+		Type   string `json:"type"`
+		Domain string `json:"domain"`
+		New    struct {
+			Type    string `json:"type"`
+			Content string `json:"content"`
+		} `json:"new"`
+	}
+	if assert.NoError(t, json.Unmarshal([]byte(line), &event)) {
+		assert.Equal(t, "record_added", event.Type)
+		assert.Equal(t, "example.com", event.Domain)
+		assert.Equal(t, "192.0.2.1", event.New.Content)
+	}
+
+	code, _, _ := runCLI(t, server, "watch", "-o", "csv")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runCLI(t, server, "watch", "-expiry-days", "30")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestRun_WatchWebhook(t *testing.T) {
+	server := setupServer(t)
+	env := noConfig(t)
+	env["PORKBUN_API_KEY"] = porkbuntest.DefaultApiKey
+	env["PORKBUN_API_SECRET"] = porkbuntest.DefaultSecretApiKey
+	env["PORKBUN_WEBHOOK_SECRET"] = "s3cret"
+
+	var mu sync.Mutex
+	var bodies []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if notify.Verify("s3cret", r.Header.Get(notify.TimestampHeader), r.Header.Get(notify.SignatureHeader), body, time.Minute) {
+			mu.Lock()
+			bodies = append(bodies, string(body))
+			mu.Unlock()
+		}
+	}))
+	t.Cleanup(hook.Close)
+	received := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), bodies...)
+	}
+
+	template := filepath.Join(t.TempDir(), "slack.tmpl")
+	assert.NoError(t, os.WriteFile(template, []byte(`{"text": {{json .Text}}}`), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stdout, stderr syncBuffer
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"-base-url", server.URL, "watch", "-interval", "10ms", "-webhook", hook.URL, "-webhook-template", template},
+			strings.NewReader(""), &stdout, &stderr, func(key string) string { return env[key] })
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; len(received()) == 0 && time.Now().Before(deadline); i++ {
+		server.AddRecord("example.com", porkbun.DnsRecord{Name: "r" + strconv.Itoa(i), Type: porkbun.A, Content: "192.0.2.1"})
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	assert.Equal(t, exitOK, <-done, stderr.String())
+
+	if bodies := received(); assert.NotEmpty(t, bodies) {
+		assert.Regexp(t, `^\{"text": "\+ example\.com record r\d+ A \\"192\.0\.2\.1\\" ttl=600"\}$`, bodies[0])
+	}
+	assert.Contains(t, stdout.String(), "+ example.com record r")
+}
+
+func TestParseInterspersed(t *testing.T) {
+	cmd := dnsCreateCommand()
+	var stdout, stderr bytes.Buffer
+	a := &app{stdout: &stdout, stderr: &stderr}
+
+	var got []string
+	cmd.run = func(ctx context.Context, a *app, args []string) error {
+		got = args
+		return nil
+	}
+	assert.NoError(t, cmd.execute(context.Background(), a, "create", []string{"-ttl", "60", "example.com", "TXT", "--", "-x", "-y"}))
+	assert.Equal(t, []string{"example.com", "TXT", "-x", "-y"}, got)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tuzzmaniandevil/porkbun-go/output"
+)
+
+// table writes aligned columns with a header row.
+type table struct {
+	w *tabwriter.Writer
+}
+
+// newTable creates a table writing to w and writes the header row.
+func newTable(w io.Writer, header ...string) *table {
+	t := &table{w: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+	t.row(header...)
+	return t
+}
+
+// row writes a row of cells.
+func (t *table) row(cells ...string) {
+	fmt.Fprintln(t.w, strings.Join(cells, "\t"))
+}
+
+// flush writes the buffered rows.
+func (t *table) flush() error {
+	return t.w.Flush()
+}
+
+// outputFlags holds the -output and -columns flags of commands printing results.
+type outputFlags struct {
+	format  string
+	columns string
+}
+
+// register adds the flags to fs. available lists the columns that can be selected.
+func (o *outputFlags) register(fs *flag.FlagSet, available []string) {
+	formats := make([]string, len(output.Formats))
+	for i, f := range output.Formats {
+		formats[i] = string(f)
+	}
+
+	usage := "Output format: " + strings.Join(formats, ", ")
+	fs.StringVar(&o.format, "output", string(output.FormatTable), usage)
+	fs.StringVar(&o.format, "o", string(output.FormatTable), usage)
+	fs.StringVar(&o.columns, "columns", "", "Comma separated columns to show: "+strings.Join(available, ", "))
+}
+
+// options returns the output options for the flags, with record names relative to domain.
+func (o *outputFlags) options(domain string) (*output.Options, error) {
+	format, err := output.ParseFormat(o.format)
+	if err != nil {
+		return nil, &usageError{message: err.Error()}
+	}
+
+	options := &output.Options{Format: format, Domain: domain}
+	if o.columns != "" {
+		options.Columns = strings.Split(o.columns, ",")
+	}
+	return options, nil
+}
+
+// hasColumn reports whether the column was selected with -columns.
+func (o *outputFlags) hasColumn(name string) bool {
+	for _, c := range strings.Split(o.columns, ",") {
+		if strings.EqualFold(strings.TrimSpace(c), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeOutput converts errors about the selected columns to usage errors.
+func writeOutput(err error) error {
+	if errors.Is(err, output.ErrUnknownColumn) {
+		return &usageError{message: err.Error()}
+	}
+	return err
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// pingCommand returns the ping command.
+func pingCommand() *command {
+	return &command{
+		name:    "ping",
+		summary: "Check the credentials and show the public IP address seen by the API",
+		run: func(ctx context.Context, a *app, args []string) error {
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Ping(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stdout, "Your IP: %s\n", resp.YourIP)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/output"
+)
+
+// pricingCommand returns the pricing command.
+func pricingCommand() *command {
+	var tlds string
+	var out outputFlags
+
+	return &command{
+		name:    "pricing",
+		summary: "Show the registration, renewal and transfer prices of TLDs, no credentials needed",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&tlds, "tld", "", "Comma separated list of TLDs to show, all if empty")
+			out.register(fs, output.PricingColumns())
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			options, err := out.options("")
+			if err != nil {
+				return err
+			}
+
+			client, err := a.client(false)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Pricing.ListPricing(ctx)
+			if err != nil {
+				return err
+			}
+
+			pricing := resp.Pricing
+			if tlds != "" {
+				pricing = make(map[string]porkbun.Pricing)
+				for _, tld := range strings.Split(tlds, ",") {
+					tld = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(tld)), ".")
+					p, ok := resp.Pricing[tld]
+					if !ok {
+						return fmt.Errorf("no pricing for TLD %q", tld)
+					}
+					pricing[tld] = p
+				}
+			}
+			return writeOutput(output.Pricing(a.stdout, pricing, options))
+		},
+	}
+}
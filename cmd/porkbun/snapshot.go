@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/output"
+	"github.com/tuzzmaniandevil/porkbun-go/snapshot"
+)
+
+// snapshotCommand returns the snapshot command group.
+func snapshotCommand() *command {
+	return &command{
+		name:    "snapshot",
+		summary: "Back up, restore and compare the DNS records, name servers and URL forwards of the account",
+		commands: []*command{
+			snapshotCreateCommand(),
+			snapshotRestoreCommand(),
+			snapshotDiffCommand(),
+		},
+	}
+}
+
+// snapshotCreateCommand returns the snapshot create command.
+func snapshotCreateCommand() *command {
+	var file, domains string
+
+	return &command{
+		name:    "create",
+		summary: "Write a snapshot of the account as JSON",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&file, "file", "", "File to write, standard output if empty")
+			fs.StringVar(&domains, "domains", "", "Comma separated domains to capture, all domains if empty")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			snap, err := snapshot.Take(ctx, client.Dns, client.Domains, &snapshot.Options{Domains: splitList(domains)})
+			if err != nil {
+				return err
+			}
+
+			if file == "" {
+				err = snap.Write(a.stdout)
+			} else {
+				err = snap.Save(file)
+			}
+			if err != nil {
+				return err
+			}
+
+			// The snapshot is written anyway, so the other domains are backed up
+			failed := snap.Failed()
+			for _, d := range failed {
+				fmt.Fprintf(a.stderr, "porkbun: %s: %s\n", d.Domain, d.Error)
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("%d of %d domains could not be captured", len(failed), len(snap.Domains))
+			}
+			if file != "" {
+				fmt.Fprintf(a.stdout, "Saved %d domains to %s\n", len(snap.Domains), file)
+			}
+			return nil
+		},
+	}
+}
+
+// snapshotRestoreCommand returns the snapshot restore command.
+func snapshotRestoreCommand() *command {
+	var domains string
+	var dryRun, prune bool
+	var confirm confirmFlags
+
+	return &command{
+		name:    "restore",
+		args:    "<file>",
+		summary: "Restore a snapshot, after showing the changes and confirmation",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&domains, "domains", "", "Comma separated domains to restore, all domains of the snapshot if empty")
+			fs.BoolVar(&dryRun, "dry-run", false, "Only show the changes")
+			fs.BoolVar(&prune, "prune", false, "Also delete the records and URL forwards that are not in the snapshot")
+			confirm.register(fs)
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			snap, err := snapshot.Load(args[0])
+			if err != nil {
+				return err
+			}
+			selected, err := snap.Select(splitList(domains))
+			if errors.Is(err, snapshot.ErrUnknownDomain) {
+				return usagef("porkbun snapshot restore: %v", err)
+			}
+			if err != nil {
+				return err
+			}
+
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			for _, d := range selected.Failed() {
+				fmt.Fprintf(a.stderr, "Skipping %s, which could not be captured: %s\n", d.Domain, d.Error)
+			}
+			changes, err := snapshot.Plan(ctx, client.Dns, client.Domains, selected, &snapshot.RestoreOptions{Prune: prune})
+			if err != nil {
+				return err
+			}
+			if len(changes) == 0 {
+				fmt.Fprintln(a.stdout, "Nothing to restore")
+				return nil
+			}
+
+			w := a.stderr
+			if dryRun {
+				w = a.stdout
+			}
+			fmt.Fprintf(w, "The following changes will be made:\n\n")
+			for _, change := range changes {
+				fmt.Fprintf(w, "  %s\n", change)
+			}
+			fmt.Fprintln(w)
+			if dryRun {
+				return nil
+			}
+
+			if err := a.confirmRestore(client, &confirm, changes); err != nil {
+				return err
+			}
+
+			n, err := snapshot.Apply(ctx, client.Dns, client.Domains, changes)
+			if err != nil {
+				return fmt.Errorf("%w (%d of %d changes applied)", err, n, len(changes))
+			}
+			fmt.Fprintf(a.stdout, "Applied %d changes\n", n)
+			return nil
+		},
+	}
+}
+
+// snapshotDiffCommand returns the snapshot diff command.
+func snapshotDiffCommand() *command {
+	var domains, format string
+
+	return &command{
+		name:    "diff",
+		args:    "<from> [<to>]",
+		summary: "Show the changes between two snapshots, or since a snapshot if only one is given",
+		minArgs: 1,
+		maxArgs: 2,
+		flags: func(fs *flag.FlagSet) {
+			usage := "Output format: table or json"
+			fs.StringVar(&format, "output", string(output.FormatTable), usage)
+			fs.StringVar(&format, "o", string(output.FormatTable), usage)
+			fs.StringVar(&domains, "domains", "", "Comma separated domains to compare, all domains if empty")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			f, err := output.ParseFormat(format)
+			if err != nil {
+				return usagef("%v", err)
+			}
+			if f != output.FormatTable && f != output.FormatJSON {
+				return usagef("porkbun snapshot diff: -output must be table or json")
+			}
+
+			from, err := snapshot.Load(args[0])
+			if err != nil {
+				return err
+			}
+
+			var report *snapshot.Report
+			if len(args) == 2 {
+				to, err := snapshot.Load(args[1])
+				if err != nil {
+					return err
+				}
+				selected := splitList(domains)
+				if from, err = from.Select(selected); err == nil {
+					to, err = to.Select(selected)
+				}
+				if err != nil {
+					return usagef("porkbun snapshot diff: %v", err)
+				}
+				report = snapshot.Diff(from, to)
+			} else {
+				client, err := a.client(true)
+				if err != nil {
+					return err
+				}
+				report, err = snapshot.DiffLive(ctx, client.Dns, client.Domains, from, &snapshot.Options{Domains: splitList(domains)})
+				if errors.Is(err, snapshot.ErrUnknownDomain) {
+					return usagef("porkbun snapshot diff: %v", err)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			if f == output.FormatJSON {
+				return report.WriteJSON(a.stdout)
+			}
+			return report.WriteText(a.stdout)
+		},
+	}
+}
+
+// confirmRestore asks for confirmation of the changes, typing the domain if there is only one or
+// else "restore". Deleting or modifying protected records requires -force.
+func (a *app) confirmRestore(client *porkbun.Client, flags *confirmFlags, changes []snapshot.Change) error {
+	guard, err := a.guard(client)
+	if err != nil {
+		return err
+	}
+
+	protected := 0
+	domains := make(map[string]bool)
+	for _, change := range changes {
+		domains[change.Domain] = true
+		if r, ok := change.Old.(*snapshot.Record); ok && guard.Protected(porkbun.DnsRecord{Name: r.Name, Type: r.Type}, change.Domain) {
+			protected++
+		}
+	}
+	if protected > 0 {
+		fmt.Fprintf(a.stderr, "%d of them change records matching a protected pattern.\n", protected)
+		if !flags.force {
+			return errProtected
+		}
+	}
+
+	word := "restore"
+	if len(domains) == 1 {
+		word = changes[0].Domain
+	}
+	return a.confirm(flags, word)
+}
+
+// splitList splits a comma separated flag value, ignoring empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SSL bundle parts printed by ssl get.
+const (
+	partSummary = "summary"
+	partChain   = "chain"
+	partKey     = "key"
+	partPublic  = "public"
+	partBundle  = "bundle"
+)
+
+// sslCommand returns the ssl command group.
+func sslCommand() *command {
+	return &command{
+		name:    "ssl",
+		summary: "Retrieve SSL certificate bundles",
+		commands: []*command{
+			sslGetCommand(),
+		},
+	}
+}
+
+// sslGetCommand returns the ssl get command.
+func sslGetCommand() *command {
+	var part string
+
+	return &command{
+		name:    "get",
+		args:    "<domain>",
+		summary: "Show the SSL certificate of a domain, or print parts of its bundle as PEM",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&part, "part", partSummary, "What to print: summary, chain, key, public or bundle (chain and key)")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			switch part {
+			case partSummary, partChain, partKey, partPublic, partBundle:
+			default:
+				return usagef("porkbun ssl get: unknown part %q", part)
+			}
+
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Ssl.Retrieve(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			switch part {
+			case partChain:
+				fmt.Fprint(a.stdout, withNewline(resp.Certificatechain))
+			case partKey:
+				fmt.Fprint(a.stdout, withNewline(resp.Privatekey))
+			case partPublic:
+				fmt.Fprint(a.stdout, withNewline(resp.Publickey))
+			case partBundle:
+				fmt.Fprint(a.stdout, withNewline(resp.Certificatechain)+withNewline(resp.Privatekey))
+			default:
+				leaf, err := resp.Leaf()
+				if err != nil {
+					return err
+				}
+				t := newTable(a.stdout, "FIELD", "VALUE")
+				t.row("Subject", leaf.Subject.CommonName)
+				t.row("Names", strings.Join(leaf.DNSNames, ","))
+				t.row("Issuer", leaf.Issuer.CommonName)
+				t.row("Not before", leaf.NotBefore.UTC().Format(time.RFC3339))
+				t.row("Not after", leaf.NotAfter.UTC().Format(time.RFC3339))
+				t.row("Serial", leaf.SerialNumber.String())
+				return t.flush()
+			}
+			return nil
+		},
+	}
+}
+
+// withNewline makes sure a PEM block ends with a newline.
+func withNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
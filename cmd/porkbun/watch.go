@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/notify"
+	"github.com/tuzzmaniandevil/porkbun-go/output"
+	"github.com/tuzzmaniandevil/porkbun-go/watch"
+)
+
+// envWebhookSecret holds the key signing webhook requests, kept out of the command line.
+const envWebhookSecret = "PORKBUN_WEBHOOK_SECRET"
+
+// watchCommand returns the watch command.
+func watchCommand() *command {
+	var domains, format, templateFile string
+	var interval time.Duration
+	var webhooks stringList
+	var expiryDays int
+
+	return &command{
+		name:    "watch",
+		summary: "Print the changes to domains, DNS records, name servers and URL forwards as they happen",
+		flags: func(fs *flag.FlagSet) {
+			usage := "Output format: table or json, one object per line"
+			fs.StringVar(&format, "output", string(output.FormatTable), usage)
+			fs.StringVar(&format, "o", string(output.FormatTable), usage)
+			fs.StringVar(&domains, "domains", "", "Comma separated domains to watch, all domains if empty")
+			fs.DurationVar(&interval, "interval", watch.DefaultInterval, "Time between two polls")
+			fs.Var(&webhooks, "webhook", "URL to post the changes to, can be repeated; requests are signed with $"+envWebhookSecret+" if set")
+			fs.StringVar(&templateFile, "webhook-template", "", "File with the text/template of the webhook body, the JSON message if empty")
+			fs.IntVar(&expiryDays, "expiry-days", 0, "Also post a daily webhook for the domains expiring within this many days")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			f, err := output.ParseFormat(format)
+			if err != nil {
+				return usagef("%v", err)
+			}
+			if f != output.FormatTable && f != output.FormatJSON {
+				return usagef("porkbun watch: -output must be table or json")
+			}
+
+			client, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			logger := log.New(a.stderr, "porkbun: ", 0)
+			notifier, err := a.notifier(client, webhooks, templateFile, expiryDays, logger)
+			if err != nil {
+				return err
+			}
+
+			w := watch.NewWatcher(client.Dns, client.Domains, &watch.Options{
+				Domains:  splitList(domains),
+				Interval: interval,
+				Logger:   logger,
+			})
+			encoder := json.NewEncoder(a.stdout)
+			encoder.SetEscapeHTML(false)
+
+			printEvent := func(e watch.Event) {
+				if f == output.FormatJSON {
+					_ = encoder.Encode(e)
+				} else {
+					fmt.Fprintf(a.stdout, "%s %s\n", e.Time.Local().Format(time.DateTime), e)
+				}
+			}
+			if notifier != nil {
+				err = notifier.Run(ctx, w, printEvent)
+			} else {
+				err = w.Run(ctx, printEvent)
+			}
+			// Interrupting the command is the normal way to stop it
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		},
+	}
+}
+
+// notifier creates the notifier posting to the webhooks, or returns nil if there are none.
+func (a *app) notifier(client *porkbun.Client, webhooks []string, templateFile string, expiryDays int, logger *log.Logger) (*notify.Notifier, error) {
+	if len(webhooks) == 0 {
+		if templateFile != "" || expiryDays > 0 {
+			return nil, usagef("porkbun watch: -webhook-template and -expiry-days require -webhook")
+		}
+		return nil, nil
+	}
+
+	var tmpl string
+	if templateFile != "" {
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, err
+		}
+		tmpl = string(data)
+	}
+
+	targets := make([]notify.Target, len(webhooks))
+	for i, url := range webhooks {
+		targets[i] = notify.Target{URL: url, Secret: a.getenv(envWebhookSecret), Template: tmpl}
+	}
+	return notify.NewNotifier(client.Domains, &notify.Options{Targets: targets, ExpiryDays: expiryDays, Logger: logger})
+}
+
+// stringList is a flag that can be repeated, collecting its values.
+type stringList []string
+
+// String implements flag.Value.
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set implements flag.Value.
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
@@ -0,0 +1,243 @@
+// Package config loads named credential profiles from a YAML configuration file, so several
+// Porkbun accounts can be used without juggling environment variables.
+//
+//	default: production
+//	profiles:
+//	  production:
+//	    api_key: pk1_...
+//	    secret_api_key: sk1_...
+//	    domain: example.com
+//	    protected: ["MX:@", "NS", "TXT:@"]
+//	  staging:
+//	    api_key: pk1_...
+//	    secret_api_key: sk1_...
+//	    ipv4_only: true
+//	    user_agent: deploy-bot/1.0
+//
+// The file holds secrets and should only be readable by its owner. Environment variables override
+// the values of the selected profile:
+//
+//	profile, err := config.LoadProfile(&config.LoadOptions{Profile: "staging"})
+//	if err != nil {
+//		return err
+//	}
+//	client := profile.NewClient()
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by ApplyEnv and LoadProfile.
+const (
+	EnvApiKey       = "PORKBUN_API_KEY"    // Overrides Profile.ApiKey
+	EnvSecretApiKey = "PORKBUN_API_SECRET" // Overrides Profile.SecretApiKey
+	EnvIPv4Only     = "PORKBUN_IPV4_ONLY"  // Overrides Profile.IPv4Only, a boolean such as "true" or "0"
+	EnvUserAgent    = "PORKBUN_USER_AGENT" // Overrides Profile.UserAgent
+	EnvDomain       = "PORKBUN_DOMAIN"     // Overrides Profile.Domain
+	EnvProfile      = "PORKBUN_PROFILE"    // Name of the profile, if none is given
+	EnvConfig       = "PORKBUN_CONFIG"     // Path of the configuration file, if none is given
+)
+
+// ErrUnknownProfile is returned when the requested profile is not in the configuration file.
+var ErrUnknownProfile = errors.New("config: unknown profile")
+
+// File is the configuration file format. JSON is accepted as well, as it is a subset of YAML.
+type File struct {
+	Default  string              `yaml:"default"`  // Profile used when none is requested.
+	Profiles map[string]*Profile `yaml:"profiles"` // Profiles by name.
+}
+
+// Profile holds the credentials and settings of one Porkbun account.
+type Profile struct {
+	Name         string   `yaml:"-"`              // Name of the profile, empty if it only comes from the environment.
+	ApiKey       string   `yaml:"api_key"`        // Public API key provided by Porkbun.
+	SecretApiKey string   `yaml:"secret_api_key"` // Secret API key provided by Porkbun.
+	IPv4Only     bool     `yaml:"ipv4_only"`      // Use the IPv4-only API endpoint.
+	UserAgent    string   `yaml:"user_agent"`     // Custom User-Agent string.
+	Domain       string   `yaml:"domain"`         // Domain used when a command is not given one.
+	Protected    []string `yaml:"protected"`      // Protected record patterns such as "MX:@", see package safeguard.
+}
+
+// DefaultPath returns the default location of the configuration file, porkbun/config.yaml in the
+// user configuration directory, e.g. ~/.config/porkbun/config.yaml on Linux.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "porkbun", "config.yaml"), nil
+}
+
+// Load reads and validates a configuration file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// Parse parses and validates a YAML or JSON configuration.
+func Parse(data []byte) (*File, error) {
+	file := &File{}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+
+	for name, profile := range file.Profiles {
+		if name == "" {
+			return nil, errors.New("config: profile name must not be empty")
+		}
+		if profile == nil {
+			profile = &Profile{}
+			file.Profiles[name] = profile
+		}
+		profile.Name = name
+		profile.Domain = porkbun.NormalizeName(profile.Domain)
+	}
+	if file.Default != "" && file.Profiles[file.Default] == nil {
+		return nil, fmt.Errorf("%w %q set as default", ErrUnknownProfile, file.Default)
+	}
+
+	return file, nil
+}
+
+// Names returns the sorted profile names.
+func (f *File) Names() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns a copy of the named profile. An empty name selects the default profile, or the
+// one named "default", or else an empty profile to be filled from the environment.
+func (f *File) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = f.Default
+		if name == "" {
+			if _, ok := f.Profiles["default"]; !ok {
+				return &Profile{}, nil
+			}
+			name = "default"
+		}
+	}
+
+	profile, ok := f.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, expected one of %s", ErrUnknownProfile, name, strings.Join(f.Names(), ", "))
+	}
+	p := *profile
+	return &p, nil
+}
+
+// ApplyEnv overrides the profile with the environment variables that are set, as returned by getenv.
+func (p *Profile) ApplyEnv(getenv func(string) string) error {
+	if v := getenv(EnvApiKey); v != "" {
+		p.ApiKey = v
+	}
+	if v := getenv(EnvSecretApiKey); v != "" {
+		p.SecretApiKey = v
+	}
+	if v := getenv(EnvIPv4Only); v != "" {
+		ipv4Only, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("config: invalid %s %q", EnvIPv4Only, v)
+		}
+		p.IPv4Only = ipv4Only
+	}
+	if v := getenv(EnvUserAgent); v != "" {
+		p.UserAgent = v
+	}
+	if v := getenv(EnvDomain); v != "" {
+		p.Domain = porkbun.NormalizeName(v)
+	}
+	return nil
+}
+
+// HasCredentials reports whether both API keys are set.
+func (p *Profile) HasCredentials() bool {
+	return p.ApiKey != "" && p.SecretApiKey != ""
+}
+
+// Options returns the client options of the profile.
+func (p *Profile) Options() *porkbun.Options {
+	return &porkbun.Options{
+		ApiKey:       p.ApiKey,
+		SecretApiKey: p.SecretApiKey,
+		IPv4Only:     p.IPv4Only,
+		UserAgent:    p.UserAgent,
+	}
+}
+
+// NewClient initializes a Porkbun API client with the profile's options.
+func (p *Profile) NewClient() *porkbun.Client {
+	return porkbun.NewClient(p.Options())
+}
+
+// LoadOptions defines the configuration options for LoadProfile.
+type LoadOptions struct {
+	Path    string              // Configuration file, defaults to $PORKBUN_CONFIG or DefaultPath.
+	Profile string              // Profile name, defaults to $PORKBUN_PROFILE or the default profile.
+	Getenv  func(string) string // Reads environment variables, defaults to os.Getenv.
+}
+
+// LoadProfile loads the requested profile and applies the environment overrides. A missing
+// configuration file is treated as empty, so the environment alone can provide the credentials,
+// unless a profile is explicitly requested.
+func LoadProfile(options *LoadOptions) (*Profile, error) {
+	var opts LoadOptions
+	if options != nil {
+		opts = *options
+	}
+	if opts.Getenv == nil {
+		opts.Getenv = os.Getenv
+	}
+	if opts.Path == "" {
+		opts.Path = opts.Getenv(EnvConfig)
+	}
+	if opts.Profile == "" {
+		opts.Profile = opts.Getenv(EnvProfile)
+	}
+
+	file := &File{}
+	if opts.Path == "" {
+		path, err := DefaultPath()
+		if err != nil && opts.Profile != "" {
+			return nil, err
+		}
+		opts.Path = path
+	}
+	if opts.Path != "" {
+		loaded, err := Load(opts.Path)
+		switch {
+		case err == nil:
+			file = loaded
+		case !errors.Is(err, fs.ErrNotExist) || opts.Profile != "":
+			return nil, fmt.Errorf("loading %s: %w", opts.Path, err)
+		}
+	}
+
+	profile, err := file.Profile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := profile.ApplyEnv(opts.Getenv); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+const testConfig = `
+default: production
+profiles:
+  production:
+    api_key: pk1_prod
+    secret_api_key: sk1_prod
+    domain: Example.com.
+    protected: ["MX:@", "NS"]
+  staging:
+    api_key: pk1_staging
+    secret_api_key: sk1_staging
+    ipv4_only: true
+    user_agent: deploy-bot/1.0
+  empty:
+`
+
+func env(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func writeConfig(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func TestParse(t *testing.T) {
+	file, err := Parse([]byte(testConfig))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"empty", "production", "staging"}, file.Names())
+
+	profile, err := file.Profile("")
+	assert.NoError(t, err)
+	assert.Equal(t, &Profile{Name: "production", ApiKey: "pk1_prod", SecretApiKey: "sk1_prod", Domain: "example.com",
+		Protected: []string{"MX:@", "NS"}}, profile)
+
+	profile, err = file.Profile("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, &porkbun.Options{ApiKey: "pk1_staging", SecretApiKey: "sk1_staging", IPv4Only: true,
+		UserAgent: "deploy-bot/1.0"}, profile.Options())
+
+	// Profiles are copies
+	profile.ApiKey = "changed"
+	profile, _ = file.Profile("staging")
+	assert.Equal(t, "pk1_staging", profile.ApiKey)
+
+	profile, err = file.Profile("empty")
+	assert.NoError(t, err)
+	assert.Equal(t, "empty", profile.Name)
+	assert.False(t, profile.HasCredentials())
+
+	_, err = file.Profile("client")
+	assert.ErrorIs(t, err, ErrUnknownProfile)
+	assert.Contains(t, err.Error(), "empty, production, staging")
+}
+
+func TestParse_DefaultProfile(t *testing.T) {
+	file, err := Parse([]byte(`{"profiles": {"default": {"api_key": "pk1"}, "other": {}}}`))
+	assert.NoError(t, err)
+	profile, err := file.Profile("")
+	assert.NoError(t, err)
+	assert.Equal(t, "pk1", profile.ApiKey)
+
+	// Without a default, the environment alone is used
+	file, err = Parse([]byte("profiles: {other: {api_key: pk1}}"))
+	assert.NoError(t, err)
+	profile, err = file.Profile("")
+	assert.NoError(t, err)
+	assert.Equal(t, &Profile{}, profile)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"syntax":          "profiles: [",
+		"unknown default": "default: prod\nprofiles: {staging: {}}",
+		"empty name":      `profiles: {"": {api_key: pk1}}`,
+	}
+
+	for name, data := range tests {
+		_, err := Parse([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	profile := &Profile{ApiKey: "pk1_file", SecretApiKey: "sk1_file", IPv4Only: true, Domain: "example.com"}
+	assert.NoError(t, profile.ApplyEnv(env(map[string]string{
+		EnvApiKey:    "pk1_env",
+		EnvIPv4Only:  "false",
+		EnvUserAgent: "agent/2",
+		EnvDomain:    "EXAMPLE.org",
+	})))
+	assert.Equal(t, &Profile{ApiKey: "pk1_env", SecretApiKey: "sk1_file", UserAgent: "agent/2", Domain: "example.org"}, profile)
+
+	assert.Error(t, profile.ApplyEnv(env(map[string]string{EnvIPv4Only: "sometimes"})))
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := writeConfig(t, testConfig)
+
+	profile, err := LoadProfile(&LoadOptions{Path: path, Profile: "staging", Getenv: env(nil)})
+	assert.NoError(t, err)
+	assert.Equal(t, "pk1_staging", profile.ApiKey)
+
+	// The file and profile can come from the environment, whose credentials override the file
+	profile, err = LoadProfile(&LoadOptions{Getenv: env(map[string]string{
+		EnvConfig:       path,
+		EnvProfile:      "staging",
+		EnvSecretApiKey: "sk1_env",
+	})})
+	assert.NoError(t, err)
+	assert.Equal(t, "pk1_staging", profile.ApiKey)
+	assert.Equal(t, "sk1_env", profile.SecretApiKey)
+
+	_, err = LoadProfile(&LoadOptions{Path: path, Profile: "client", Getenv: env(nil)})
+	assert.ErrorIs(t, err, ErrUnknownProfile)
+
+	_, err = LoadProfile(&LoadOptions{Path: writeConfig(t, "profiles: ["), Getenv: env(nil)})
+	assert.Error(t, err)
+}
+
+func TestLoadProfile_MissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+
+	// The environment alone is enough
+	profile, err := LoadProfile(&LoadOptions{Path: missing, Getenv: env(map[string]string{EnvApiKey: "pk1", EnvSecretApiKey: "sk1"})})
+	assert.NoError(t, err)
+	assert.True(t, profile.HasCredentials())
+	assert.NotNil(t, profile.NewClient())
+
+	// But an explicitly requested profile must exist
+	_, err = LoadProfile(&LoadOptions{Path: missing, Profile: "production", Getenv: env(nil)})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
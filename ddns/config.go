@@ -0,0 +1,106 @@
+package ddns
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration file format of the dynamic DNS updater. JSON is accepted as well,
+// as it is a subset of YAML.
+//
+//	interval: 5m
+//	ipv4: true
+//	ipv6: true
+//	domains:
+//	  - domain: example.com
+//	    subdomains: ["", "vpn"]
+type Config struct {
+	Interval   time.Duration  `yaml:"interval"`    // Time between successful passes.
+	MinBackoff time.Duration  `yaml:"min_backoff"` // First wait after a failure.
+	MaxBackoff time.Duration  `yaml:"max_backoff"` // Upper limit of the wait after failures.
+	TTL        int            `yaml:"ttl"`         // TTL of created or edited records.
+	IPv4       *bool          `yaml:"ipv4"`        // Update A records, defaults to true.
+	IPv6       bool           `yaml:"ipv6"`        // Update AAAA records.
+	Domains    []DomainConfig `yaml:"domains"`     // Domains and subdomains to keep updated.
+}
+
+// DomainConfig lists the subdomains of a domain to keep updated.
+type DomainConfig struct {
+	Domain     string   `yaml:"domain"`     // The Porkbun domain.
+	Subdomains []string `yaml:"subdomains"` // Subdomains to update, "" or "@" for the apex. Defaults to the apex.
+}
+
+// LoadConfig reads and validates a configuration file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates a YAML or JSON configuration.
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+
+	if len(config.Domains) == 0 {
+		return nil, errors.New("config: at least one domain is required")
+	}
+	for _, d := range config.Domains {
+		if d.Domain == "" {
+			return nil, errors.New("config: domain name must not be empty")
+		}
+	}
+	if !config.UpdateIPv4() && !config.IPv6 {
+		return nil, errors.New("config: at least one of ipv4 and ipv6 must be enabled")
+	}
+
+	return config, nil
+}
+
+// UpdateIPv4 reports whether A records should be updated.
+func (c *Config) UpdateIPv4() bool {
+	return c.IPv4 == nil || *c.IPv4
+}
+
+// Records returns the records listed in the configuration.
+func (c *Config) Records() []Record {
+	var records []Record
+	for _, d := range c.Domains {
+		domain := porkbun.NormalizeName(d.Domain)
+		if len(d.Subdomains) == 0 {
+			records = append(records, Record{Domain: domain})
+			continue
+		}
+
+		for _, sub := range d.Subdomains {
+			if sub == "@" {
+				sub = ""
+			}
+			records = append(records, Record{Domain: domain, Subdomain: strings.ToLower(sub)})
+		}
+	}
+	return records
+}
+
+// Options returns the Updater options for the record type.
+func (c *Config) Options(recordType porkbun.DnsRecordType) *Options {
+	return &Options{
+		Records:    c.Records(),
+		Type:       recordType,
+		TTL:        c.TTL,
+		Interval:   c.Interval,
+		MinBackoff: c.MinBackoff,
+		MaxBackoff: c.MaxBackoff,
+	}
+}
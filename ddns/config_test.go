@@ -0,0 +1,80 @@
+package ddns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+interval: 10m
+max_backoff: 1h
+ttl: 900
+ipv6: true
+domains:
+  - domain: Example.com.
+    subdomains: ["@", "VPN"]
+  - domain: example.org
+`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 10*time.Minute, config.Interval)
+	assert.True(t, config.UpdateIPv4())
+	assert.True(t, config.IPv6)
+	assert.Equal(t, []Record{
+		{Domain: "example.com"},
+		{Domain: "example.com", Subdomain: "vpn"},
+		{Domain: "example.org"},
+	}, config.Records())
+
+	options := config.Options(porkbun.AAAA)
+	assert.Equal(t, porkbun.AAAA, options.Type)
+	assert.Equal(t, 900, options.TTL)
+	assert.Equal(t, time.Hour, options.MaxBackoff)
+	assert.Len(t, options.Records, 3)
+}
+
+func TestParseConfig_JSON(t *testing.T) {
+	config, err := ParseConfig([]byte(`{"ipv4": false, "ipv6": true, "domains": [{"domain": "example.com", "subdomains": ["home"]}]}`))
+	assert.NoError(t, err)
+	assert.False(t, config.UpdateIPv4())
+	assert.Equal(t, []Record{{Domain: "example.com", Subdomain: "home"}}, config.Records())
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"syntax":      "domains: [",
+		"no domains":  "interval: 5m",
+		"empty name":  "domains: [{subdomains: [www]}]",
+		"no families": "ipv4: false\ndomains: [{domain: example.com}]",
+		"duration":    "interval: often\ndomains: [{domain: example.com}]",
+	}
+
+	for name, data := range tests {
+		_, err := ParseConfig([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("domains: [{domain: example.com}]"), 0o600))
+
+	config, err := LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{Domain: "example.com"}}, config.Records())
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_Example(t *testing.T) {
+	config, err := LoadConfig("../cmd/porkbun-ddns/porkbun-ddns.example.yaml")
+	assert.NoError(t, err)
+	assert.Len(t, config.Records(), 3)
+}
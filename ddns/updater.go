@@ -0,0 +1,264 @@
+// Package ddns implements a dynamic DNS updater that keeps A/AAAA records pointed at the
+// public IP address reported by the Porkbun Ping API.
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+const (
+	// DefaultInterval is the default time between two successful update passes.
+	DefaultInterval = 5 * time.Minute
+	// DefaultMinBackoff is the default wait after the first failed update pass.
+	DefaultMinBackoff = 30 * time.Second
+	// DefaultMaxBackoff is the default upper limit of the wait between failed update passes.
+	DefaultMaxBackoff = 30 * time.Minute
+)
+
+// ErrNoAddress is returned by Update when the API reports an address of the other family than
+// Options.Type, as for AAAA records on a host without IPv6 connectivity.
+var ErrNoAddress = errors.New("no address of this family")
+
+// Record identifies a DNS name to keep updated.
+type Record struct {
+	Domain    string // The Porkbun domain
+	Subdomain string // The subdomain, empty for the domain apex
+}
+
+// String returns the fully qualified name of the record.
+func (r Record) String() string {
+	if r.Subdomain == "" {
+		return r.Domain
+	}
+	return r.Subdomain + "." + r.Domain
+}
+
+// Action describes what an update pass did to a record.
+type Action string
+
+// Constants representing the possible update actions.
+const (
+	Unchanged Action = "unchanged"
+	Created   Action = "created"
+	Updated   Action = "updated"
+)
+
+// Result reports the outcome of an update pass for a single record.
+type Result struct {
+	Record Record
+	Type   porkbun.DnsRecordType
+	IP     string
+	Action Action
+}
+
+// Options defines the configuration options for the Updater.
+type Options struct {
+	Records    []Record              // Records to keep updated.
+	Type       porkbun.DnsRecordType // A or AAAA, derived from the reported IP if empty.
+	TTL        int                   // TTL of created or edited records, the API default if zero.
+	Interval   time.Duration         // Time between successful passes, defaults to DefaultInterval.
+	MinBackoff time.Duration         // First wait after a failure, defaults to DefaultMinBackoff.
+	MaxBackoff time.Duration         // Upper limit of the wait after failures, defaults to DefaultMaxBackoff.
+	Logger     *log.Logger           // Logger for update results and errors, discarded if nil.
+}
+
+// Updater keeps A/AAAA records in sync with the public IP address reported by Client.Ping.
+type Updater struct {
+	client  *porkbun.Client
+	options Options
+
+	// after is used to wait between passes and is replaced in tests.
+	after func(time.Duration) <-chan time.Time
+}
+
+// NewUpdater initializes a new Updater using the provided client and options.
+// Use a client created with Options.IPv4Only to update A records on dual stack hosts.
+func NewUpdater(client *porkbun.Client, options *Options) *Updater {
+	u := &Updater{
+		client: client,
+		after:  time.After,
+	}
+
+	if options != nil {
+		u.options = *options
+	}
+	if u.options.Interval <= 0 {
+		u.options.Interval = DefaultInterval
+	}
+	if u.options.MinBackoff <= 0 {
+		u.options.MinBackoff = DefaultMinBackoff
+	}
+	if u.options.MaxBackoff < u.options.MinBackoff {
+		u.options.MaxBackoff = DefaultMaxBackoff
+		if u.options.MaxBackoff < u.options.MinBackoff {
+			u.options.MaxBackoff = u.options.MinBackoff
+		}
+	}
+	if u.options.Logger == nil {
+		u.options.Logger = log.New(io.Discard, "", 0)
+	}
+
+	return u
+}
+
+// Update runs a single update pass. It pings the API to learn the current IP address and
+// creates or edits each configured record whose content differs from it.
+func (u *Updater) Update(ctx context.Context) ([]Result, error) {
+	ping, err := u.client.Ping(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ping: %w", err)
+	}
+
+	recordType, err := addressType(ping.YourIP)
+	if err != nil {
+		return nil, err
+	}
+	if u.options.Type != "" && u.options.Type != recordType {
+		return nil, fmt.Errorf("%w: expected an %s address but the API reported %s", ErrNoAddress, u.options.Type, ping.YourIP)
+	}
+
+	results := make([]Result, 0, len(u.options.Records))
+	var errs []error
+
+	for _, record := range u.options.Records {
+		action, err := u.updateRecord(ctx, record, recordType, ping.YourIP)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", recordType, record, err))
+			continue
+		}
+		results = append(results, Result{Record: record, Type: recordType, IP: ping.YourIP, Action: action})
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// updateRecord converges a single record to the IP address.
+func (u *Updater) updateRecord(ctx context.Context, record Record, recordType porkbun.DnsRecordType, ip string) (Action, error) {
+	var subdomain *string
+	if record.Subdomain != "" {
+		subdomain = porkbun.String(record.Subdomain)
+	}
+
+	existing, err := u.client.Dns.GetRecordsByType(ctx, record.Domain, recordType, subdomain)
+	if err != nil {
+		return "", err
+	}
+
+	var ttl string
+	if u.options.TTL > 0 {
+		ttl = strconv.Itoa(u.options.TTL)
+	}
+
+	if len(existing.Records) == 0 {
+		_, err := u.client.Dns.CreateRecord(ctx, record.Domain, &porkbun.DnsRecord{
+			Name:    record.Subdomain,
+			Type:    recordType,
+			Content: ip,
+			TTL:     ttl,
+		})
+		if err != nil {
+			return "", err
+		}
+		return Created, nil
+	}
+
+	changed := false
+	for _, r := range existing.Records {
+		if r.Content != ip || (ttl != "" && r.TTL != ttl) {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return Unchanged, nil
+	}
+
+	_, err = u.client.Dns.EditRecordByType(ctx, record.Domain, recordType, subdomain, &porkbun.EditTypeRecord{
+		Content: ip,
+		TTL:     ttl,
+	})
+	if err != nil {
+		return "", err
+	}
+	return Updated, nil
+}
+
+// Run performs update passes until the context is cancelled. Successful passes are repeated
+// after Interval, failed passes are retried with an exponential backoff. While the host has no
+// address of Options.Type the records are skipped and checked again after Interval, logging only
+// when this starts.
+func (u *Updater) Run(ctx context.Context) error {
+	failures := 0
+	skipping := false
+
+	for {
+		results, err := u.Update(ctx)
+		for _, result := range results {
+			if result.Action != Unchanged {
+				u.options.Logger.Printf("%s %s %s -> %s", result.Action, result.Type, result.Record, result.IP)
+			}
+		}
+
+		wait := u.options.Interval
+		switch {
+		case errors.Is(err, ErrNoAddress):
+			// Not transient, retrying sooner would only flood the log
+			if !skipping {
+				u.options.Logger.Printf("skipping %s records, checking again every %s: %v", u.options.Type, wait, err)
+			}
+			skipping = true
+			failures = 0
+		case err != nil:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			wait = u.backoff(failures)
+			failures++
+			skipping = false
+			u.options.Logger.Printf("update failed, retrying in %s: %v", wait, err)
+		default:
+			failures = 0
+			skipping = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-u.after(wait):
+		}
+	}
+}
+
+// backoff returns the wait after the given number of consecutive previous failures.
+func (u *Updater) backoff(failures int) time.Duration {
+	wait := u.options.MinBackoff
+	for i := 0; i < failures; i++ {
+		wait *= 2
+		if wait >= u.options.MaxBackoff {
+			return u.options.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// addressType returns the record type matching the IP address.
+func addressType(ip string) (porkbun.DnsRecordType, error) {
+	parsed := net.ParseIP(ip)
+	switch {
+	case parsed == nil:
+		return "", fmt.Errorf("invalid IP address %q", ip)
+	case parsed.To4() != nil:
+		return porkbun.A, nil
+	default:
+		return porkbun.AAAA, nil
+	}
+}
@@ -0,0 +1,215 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+)
+
+// fakeApi serves the Porkbun API from a porkbuntest.Server, recording the endpoints called and
+// failing pings on demand.
+type fakeApi struct {
+	*porkbuntest.Server
+
+	mu    sync.Mutex
+	calls []string
+	fail  int // Number of upcoming ping requests that fail
+}
+
+func (f *fakeApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	f.calls = append(f.calls, strings.Join(parts[:min(len(parts), 2)], "/"))
+	fail := parts[0] == "ping" && f.fail > 0
+	if fail {
+		f.fail--
+	}
+	f.mu.Unlock()
+
+	if fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	f.Server.ServeHTTP(w, r)
+}
+
+// contents returns the contents of the records of example.com with the fully qualified name and type.
+func (f *fakeApi) contents(name string, recordType porkbun.DnsRecordType) []string {
+	var contents []string
+	for _, record := range f.Records("example.com") {
+		if record.Name == name && record.Type == recordType {
+			contents = append(contents, record.Content)
+		}
+	}
+	return contents
+}
+
+func setupUpdater(t *testing.T, options *Options) (*Updater, *fakeApi) {
+	fake := &fakeApi{Server: porkbuntest.NewUnstartedServer(&porkbuntest.Options{YourIP: "203.0.113.7"})}
+	fake.AddDomain(porkbun.Domain{Domain: "example.com"})
+	fake.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.A, Content: "203.0.113.7"})
+	fake.AddRecord("example.com", porkbun.DnsRecord{Name: "vpn", Type: porkbun.A, Content: "198.51.100.1"})
+	fake.AddRecord("example.com", porkbun.DnsRecord{Name: "vpn", Type: porkbun.A, Content: "198.51.100.2"})
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := porkbun.NewClient(&porkbun.Options{
+		ApiKey:       porkbuntest.DefaultApiKey,
+		SecretApiKey: porkbuntest.DefaultSecretApiKey,
+		BaseURL:      server.URL,
+	})
+	return NewUpdater(client, options), fake
+}
+
+func TestUpdater_Update(t *testing.T) {
+	updater, fake := setupUpdater(t, &Options{
+		Records: []Record{
+			{Domain: "example.com"},
+			{Domain: "example.com", Subdomain: "vpn"},
+			{Domain: "example.com", Subdomain: "office"},
+		},
+	})
+
+	results, err := updater.Update(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Result{
+		{Record: Record{Domain: "example.com"}, Type: porkbun.A, IP: "203.0.113.7", Action: Unchanged},
+		{Record: Record{Domain: "example.com", Subdomain: "vpn"}, Type: porkbun.A, IP: "203.0.113.7", Action: Updated},
+		{Record: Record{Domain: "example.com", Subdomain: "office"}, Type: porkbun.A, IP: "203.0.113.7", Action: Created},
+	}, results)
+
+	assert.Equal(t, []string{"203.0.113.7", "203.0.113.7"}, fake.contents("vpn.example.com", porkbun.A))
+	assert.Equal(t, []string{"203.0.113.7"}, fake.contents("office.example.com", porkbun.A))
+
+	// A second pass does not change anything
+	fake.calls = nil
+	results, err = updater.Update(context.Background())
+	assert.NoError(t, err)
+	for _, result := range results {
+		assert.Equal(t, Unchanged, result.Action)
+	}
+	assert.Equal(t, []string{"ping", "dns/retrieveByNameType", "dns/retrieveByNameType", "dns/retrieveByNameType"}, fake.calls)
+}
+
+func TestUpdater_UpdateIPv6(t *testing.T) {
+	updater, fake := setupUpdater(t, &Options{Records: []Record{{Domain: "example.com"}}, TTL: 900})
+	fake.SetYourIP("2001:db8::7")
+
+	results, err := updater.Update(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, porkbun.AAAA, results[0].Type)
+		assert.Equal(t, Created, results[0].Action)
+	}
+	assert.Equal(t, []string{"2001:db8::7"}, fake.contents("example.com", porkbun.AAAA))
+}
+
+func TestUpdater_UpdateWrongFamily(t *testing.T) {
+	updater, _ := setupUpdater(t, &Options{Records: []Record{{Domain: "example.com"}}, Type: porkbun.AAAA})
+
+	_, err := updater.Update(context.Background())
+	assert.ErrorIs(t, err, ErrNoAddress)
+	assert.Contains(t, err.Error(), "expected an AAAA address")
+}
+
+func TestUpdater_UpdatePingError(t *testing.T) {
+	updater, fake := setupUpdater(t, &Options{Records: []Record{{Domain: "example.com"}}})
+	fake.fail = 1
+
+	_, err := updater.Update(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ping")
+}
+
+func TestUpdater_Backoff(t *testing.T) {
+	updater := NewUpdater(nil, &Options{MinBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	assert.Equal(t, time.Second, updater.backoff(0))
+	assert.Equal(t, 2*time.Second, updater.backoff(1))
+	assert.Equal(t, 8*time.Second, updater.backoff(3))
+	assert.Equal(t, 10*time.Second, updater.backoff(4))
+	assert.Equal(t, 10*time.Second, updater.backoff(100))
+
+	updater = NewUpdater(nil, &Options{MinBackoff: time.Hour})
+	assert.Equal(t, time.Hour, updater.backoff(5))
+}
+
+func TestUpdater_Run(t *testing.T) {
+	updater, fake := setupUpdater(t, &Options{
+		Records:    []Record{{Domain: "example.com", Subdomain: "vpn"}},
+		Interval:   time.Minute,
+		MinBackoff: time.Second,
+		MaxBackoff: 4 * time.Second,
+	})
+	fake.fail = 3
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	updater.after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		if len(waits) == 6 {
+			cancel()
+		}
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+
+	err := updater.Run(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// Three failures back off exponentially, then successes wait for the interval
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, time.Minute, time.Minute, time.Minute}, waits)
+	assert.Equal(t, []string{"203.0.113.7", "203.0.113.7"}, fake.contents("vpn.example.com", porkbun.A))
+}
+
+func TestUpdater_RunNoAddress(t *testing.T) {
+	var logs strings.Builder
+	updater, fake := setupUpdater(t, &Options{
+		Records:    []Record{{Domain: "example.com"}},
+		Type:       porkbun.AAAA,
+		Interval:   time.Minute,
+		MinBackoff: time.Second,
+		Logger:     log.New(&logs, "", 0),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	updater.after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		switch len(waits) {
+		case 3:
+			fake.SetYourIP("2001:db8::7")
+		case 4:
+			cancel()
+		}
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+
+	err := updater.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Without an IPv6 address the records are skipped at the interval and logged once
+	assert.Equal(t, []time.Duration{time.Minute, time.Minute, time.Minute, time.Minute}, waits)
+	assert.Equal(t, 1, strings.Count(logs.String(), "skipping AAAA records"))
+	assert.NotContains(t, logs.String(), "update failed")
+	assert.Equal(t, []string{"2001:db8::7"}, fake.contents("example.com", porkbun.AAAA))
+}
+
+func TestRecord_String(t *testing.T) {
+	assert.Equal(t, "example.com", Record{Domain: "example.com"}.String())
+	assert.Equal(t, "vpn.example.com", Record{Domain: "example.com", Subdomain: "vpn"}.String())
+}
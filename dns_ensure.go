@@ -0,0 +1,168 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// RecordAction describes what an ensure operation did to a DNS record.
+type RecordAction string
+
+// Constants representing the possible outcomes for a DNS record.
+const (
+	RecordCreated   RecordAction = "created"
+	RecordUpdated   RecordAction = "updated"
+	RecordDeleted   RecordAction = "deleted"
+	RecordUnchanged RecordAction = "unchanged"
+)
+
+// RecordChange represents the outcome of an ensure operation for a single DNS record. The record
+// name is fully qualified, as returned by GetRecords.
+type RecordChange struct {
+	Action RecordAction // What happened to the record
+	Record DnsRecord    // The resulting record, or the removed record for RecordDeleted
+}
+
+// EnsureRecordResponse represents the result of converging a name and type to the desired records.
+type EnsureRecordResponse struct {
+	Changes []RecordChange // One entry per desired or removed record
+}
+
+// Changed reports whether any record was created, updated or deleted.
+func (r *EnsureRecordResponse) Changed() bool {
+	for _, c := range r.Changes {
+		if c.Action != RecordUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureRecord makes sure the subdomain has exactly one record of the given type with the provided
+// content, TTL and priority. Existing records are reused where possible and any others are deleted.
+// The record must be non-nil, use EnsureRecordSet with an empty set to delete all records.
+func (s *DnsService) EnsureRecord(ctx context.Context, domain string, recordType DnsRecordType, subdomain *string, record *EditTypeRecord) (*EnsureRecordResponse, error) {
+	if record == nil {
+		return &EnsureRecordResponse{}, errors.New("record must be non-nil")
+	}
+	return s.EnsureRecordSet(ctx, domain, recordType, subdomain, []EditTypeRecord{*record})
+}
+
+// EnsureRecordSet converges the records of the given type and subdomain to the provided set of values.
+// Records whose content is already present are kept, and updated only if their TTL or priority differs.
+// Remaining records are edited to hold the missing values, and surplus records are deleted.
+// An empty TTL or priority in a desired value matches any existing TTL or priority. Desired values
+// must have distinct content.
+func (s *DnsService) EnsureRecordSet(ctx context.Context, domain string, recordType DnsRecordType, subdomain *string, records []EditTypeRecord) (*EnsureRecordResponse, error) {
+	response := &EnsureRecordResponse{}
+
+	seen := make(map[string]bool, len(records))
+	for _, want := range records {
+		if seen[want.Content] {
+			return response, fmt.Errorf("duplicate %s record %q", recordType, want.Content)
+		}
+		seen[want.Content] = true
+	}
+
+	existingResp, err := s.GetRecordsByType(ctx, domain, recordType, subdomain)
+	if err != nil {
+		return response, err
+	}
+	existing := existingResp.Records
+
+	// Pair each desired value with an existing record holding the same content
+	matched := make([]*DnsRecord, len(records))
+	used := make([]bool, len(existing))
+	for i, want := range records {
+		for j := range existing {
+			if !used[j] && existing[j].Content == want.Content {
+				matched[i] = &existing[j]
+				used[j] = true
+				break
+			}
+		}
+	}
+
+	// Records that are not needed as they are can be reused for the missing values
+	var spare []DnsRecord
+	for j := range existing {
+		if !used[j] {
+			spare = append(spare, existing[j])
+		}
+	}
+
+	name, fqdn := "", NormalizeName(domain)
+	if subdomain != nil && *subdomain != "" {
+		name = *subdomain
+		fqdn = NormalizeName(name) + "." + fqdn
+	}
+
+	for i, want := range records {
+		current := matched[i]
+
+		if current == nil && len(spare) > 0 {
+			current = &spare[0]
+			spare = spare[1:]
+		}
+
+		if current == nil {
+			record := DnsRecord{Name: name, Type: recordType, Content: want.Content, TTL: want.TTL, Prio: want.Prio}
+			resp, err := s.CreateRecord(ctx, domain, &record)
+			if err != nil {
+				return response, fmt.Errorf("creating %s record %q: %w", recordType, want.Content, err)
+			}
+			record.ID, record.Name = &resp.ID, fqdn
+			response.Changes = append(response.Changes, RecordChange{Action: RecordCreated, Record: record})
+			continue
+		}
+
+		if matchesRecord(current, &want) {
+			response.Changes = append(response.Changes, RecordChange{Action: RecordUnchanged, Record: *current})
+			continue
+		}
+
+		updated := *current
+		updated.Content = want.Content
+		if want.TTL != "" {
+			updated.TTL = want.TTL
+		}
+		if want.Prio != "" {
+			updated.Prio = want.Prio
+		}
+
+		if current.ID == nil {
+			return response, fmt.Errorf("%s record %q has no ID", recordType, current.Content)
+		}
+		_, err := s.EditRecord(ctx, domain, *current.ID, &EditRecord{
+			Name:    name,
+			Type:    recordType,
+			Content: updated.Content,
+			TTL:     updated.TTL,
+			Prio:    updated.Prio,
+		})
+		if err != nil {
+			return response, fmt.Errorf("updating %s record %d: %w", recordType, *current.ID, err)
+		}
+		response.Changes = append(response.Changes, RecordChange{Action: RecordUpdated, Record: updated})
+	}
+
+	for _, record := range spare {
+		if record.ID == nil {
+			return response, fmt.Errorf("%s record %q has no ID", recordType, record.Content)
+		}
+		if _, err := s.DeleteRecord(ctx, domain, *record.ID); err != nil {
+			return response, fmt.Errorf("deleting %s record %d: %w", recordType, *record.ID, err)
+		}
+		response.Changes = append(response.Changes, RecordChange{Action: RecordDeleted, Record: record})
+	}
+
+	return response, nil
+}
+
+// matchesRecord reports whether the existing record already holds the desired value.
+func matchesRecord(current *DnsRecord, want *EditTypeRecord) bool {
+	return current.Content == want.Content &&
+		(want.TTL == "" || current.TTL == want.TTL) &&
+		(want.Prio == "" || current.Prio == want.Prio)
+}
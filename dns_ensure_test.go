@@ -0,0 +1,184 @@
+package porkbun
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupEnsureMux registers handlers for the endpoints used by EnsureRecordSet and records the calls made.
+func setupEnsureMux(t *testing.T, existing string) *[]string {
+	calls := &[]string{}
+
+	mux.HandleFunc("/dns/retrieveByNameType/example.com/A/www", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testCredentials(t, r)
+		fmt.Fprintf(w, `{"status":"SUCCESS","records":[%s]}`, existing)
+	})
+
+	mux.HandleFunc("/dns/create/example.com", func(w http.ResponseWriter, r *http.Request) {
+		data, err := getRequestJSON(r)
+		assert.NoError(t, err)
+		*calls = append(*calls, fmt.Sprintf("create %v %v %v", data["name"], data["content"], data["ttl"]))
+		fmt.Fprint(w, `{"status":"SUCCESS","id":99}`)
+	})
+
+	mux.HandleFunc("/dns/edit/example.com/", func(w http.ResponseWriter, r *http.Request) {
+		data, err := getRequestJSON(r)
+		assert.NoError(t, err)
+		id := strings.TrimPrefix(r.URL.Path, "/dns/edit/example.com/")
+		*calls = append(*calls, fmt.Sprintf("edit %v %v %v %v", id, data["name"], data["content"], data["ttl"]))
+		fmt.Fprint(w, `{"status":"SUCCESS"}`)
+	})
+
+	mux.HandleFunc("/dns/delete/example.com/", func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, "delete "+strings.TrimPrefix(r.URL.Path, "/dns/delete/example.com/"))
+		fmt.Fprint(w, `{"status":"SUCCESS"}`)
+	})
+
+	return calls
+}
+
+func actions(resp *EnsureRecordResponse) []RecordAction {
+	var result []RecordAction
+	for _, c := range resp.Changes {
+		result = append(result, c.Action)
+	}
+	return result
+}
+
+func TestDnsService_EnsureRecordCreate(t *testing.T) {
+	setupMockServer(true)
+	defer teardownMockServer()
+
+	calls := setupEnsureMux(t, "")
+
+	resp, err := client.Dns.EnsureRecord(context.Background(), "example.com", A, String("www"), &EditTypeRecord{Content: "1.1.1.1", TTL: "600"})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Changed())
+	assert.Equal(t, []RecordAction{RecordCreated}, actions(resp))
+	assert.Equal(t, int64(99), *resp.Changes[0].Record.ID)
+	assert.Equal(t, "www.example.com", resp.Changes[0].Record.Name)
+	assert.Equal(t, []string{"create www 1.1.1.1 600"}, *calls)
+}
+
+func TestDnsService_EnsureRecordUnchanged(t *testing.T) {
+	setupMockServer(true)
+	defer teardownMockServer()
+
+	calls := setupEnsureMux(t, `{"id":"1","name":"www.example.com","type":"A","content":"1.1.1.1","ttl":"600"}`)
+
+	resp, err := client.Dns.EnsureRecord(context.Background(), "example.com", A, String("www"), &EditTypeRecord{Content: "1.1.1.1"})
+
+	assert.NoError(t, err)
+	assert.False(t, resp.Changed())
+	assert.Equal(t, []RecordAction{RecordUnchanged}, actions(resp))
+	assert.Empty(t, *calls)
+}
+
+func TestDnsService_EnsureRecordUpdateAndDeleteDuplicates(t *testing.T) {
+	setupMockServer(true)
+	defer teardownMockServer()
+
+	calls := setupEnsureMux(t, `{"id":"1","name":"www.example.com","type":"A","content":"1.1.1.1","ttl":"600"},`+
+		`{"id":"2","name":"www.example.com","type":"A","content":"2.2.2.2","ttl":"600"},`+
+		`{"id":"3","name":"www.example.com","type":"A","content":"2.2.2.2","ttl":"600"}`)
+
+	resp, err := client.Dns.EnsureRecord(context.Background(), "example.com", A, String("www"), &EditTypeRecord{Content: "2.2.2.2", TTL: "3600"})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Changed())
+	assert.Equal(t, []RecordAction{RecordUpdated, RecordDeleted, RecordDeleted}, actions(resp))
+	assert.Equal(t, "3600", resp.Changes[0].Record.TTL)
+	assert.Equal(t, int64(2), *resp.Changes[0].Record.ID)
+	assert.Equal(t, []string{"edit 2 www 2.2.2.2 3600", "delete 1", "delete 3"}, *calls)
+}
+
+func TestDnsService_EnsureRecordSet(t *testing.T) {
+	setupMockServer(true)
+	defer teardownMockServer()
+
+	calls := setupEnsureMux(t, `{"id":"1","name":"www.example.com","type":"A","content":"1.1.1.1","ttl":"600"},`+
+		`{"id":"2","name":"www.example.com","type":"A","content":"2.2.2.2","ttl":"600"}`)
+
+	resp, err := client.Dns.EnsureRecordSet(context.Background(), "example.com", A, String("www"), []EditTypeRecord{
+		{Content: "2.2.2.2"},
+		{Content: "3.3.3.3"},
+		{Content: "4.4.4.4"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []RecordAction{RecordUnchanged, RecordUpdated, RecordCreated}, actions(resp))
+	for _, c := range resp.Changes {
+		// Created records have the same fully qualified name as the ones returned by the API
+		assert.Equal(t, "www.example.com", c.Record.Name)
+	}
+	assert.Equal(t, "3.3.3.3", resp.Changes[1].Record.Content)
+	assert.Equal(t, int64(1), *resp.Changes[1].Record.ID)
+	assert.Equal(t, []string{"edit 1 www 3.3.3.3 600", "create www 4.4.4.4 <nil>"}, *calls)
+}
+
+func TestDnsService_EnsureRecordSetEmpty(t *testing.T) {
+	setupMockServer(true)
+	defer teardownMockServer()
+
+	calls := setupEnsureMux(t, `{"id":"1","name":"www.example.com","type":"A","content":"1.1.1.1","ttl":"600"}`)
+
+	resp, err := client.Dns.EnsureRecordSet(context.Background(), "example.com", A, String("www"), nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []RecordAction{RecordDeleted}, actions(resp))
+	assert.Equal(t, []string{"delete 1"}, *calls)
+}
+
+func TestDnsService_EnsureRecordErrors(t *testing.T) {
+	setupMockServer(true)
+	defer teardownMockServer()
+
+	mux.HandleFunc("/dns/retrieveByNameType/example.com/A", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":"SUCCESS","records":[]}`)
+	})
+	mux.HandleFunc("/dns/create/example.com", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"status":"ERROR","message":"Invalid content."}`)
+	})
+
+	_, err := client.Dns.EnsureRecord(context.Background(), "example.com", A, nil, &EditTypeRecord{Content: "invalid"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid content.")
+
+	_, err = client.Dns.EnsureRecord(context.Background(), "example.org", A, nil, &EditTypeRecord{Content: "1.1.1.1"})
+	assert.Error(t, err)
+}
+
+func TestDnsService_EnsureRecordNil(t *testing.T) {
+	setupMockServer(true)
+	defer teardownMockServer()
+
+	calls := setupEnsureMux(t, `{"id":"1","name":"www.example.com","type":"A","content":"1.1.1.1","ttl":"600"}`)
+
+	resp, err := client.Dns.EnsureRecord(context.Background(), "example.com", A, String("www"), nil)
+	assert.EqualError(t, err, "record must be non-nil")
+	assert.NotNil(t, resp)
+	assert.Empty(t, *calls)
+}
+
+func TestDnsService_EnsureRecordSetDuplicates(t *testing.T) {
+	setupMockServer(true)
+	defer teardownMockServer()
+
+	calls := setupEnsureMux(t, "")
+
+	resp, err := client.Dns.EnsureRecordSet(context.Background(), "example.com", A, String("www"), []EditTypeRecord{
+		{Content: "1.1.1.1"},
+		{Content: "1.1.1.1", TTL: "600"},
+	})
+	assert.EqualError(t, err, `duplicate A record "1.1.1.1"`)
+	assert.Empty(t, resp.Changes)
+	assert.Empty(t, *calls)
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 )
 
+// domainsPageSize is the number of domains returned per ListDomains page.
+const domainsPageSize = 1000
+
 // DomainsService provides methods to interact with the domain management API.
 type DomainsService struct {
 	client *Client // Client used to communicate with the API
@@ -111,6 +115,28 @@ func (s *DomainsService) ListDomains(ctx context.Context, options *DomainListOpt
 	return response, err
 }
 
+// ListAllDomains retrieves every page of domains, using ListDomains until a page is not full. The
+// Start option is ignored.
+func (s *DomainsService) ListAllDomains(ctx context.Context, options *DomainListOptions) ([]Domain, error) {
+	var domains []Domain
+	for {
+		page := &DomainListOptions{Start: String(strconv.Itoa(len(domains)))}
+		if options != nil {
+			page.IncludeLabels = options.IncludeLabels
+		}
+
+		resp, err := s.ListDomains(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, resp.Domains...)
+
+		if len(resp.Domains) < domainsPageSize {
+			return domains, nil
+		}
+	}
+}
+
 // Interface guards to ensure that the required interfaces are implemented.
 var (
 	_ json.Unmarshaler = (*Domain)(nil)
@@ -49,6 +49,38 @@ func TestDomainsService_ListDomains(t *testing.T) {
 	assert.False(t, bool(testDomain.NotLocal))
 }
 
+func TestDomainsService_ListAllDomains(t *testing.T) {
+	setupMockServer(true)
+	defer teardownMockServer()
+
+	var starts []string
+	mux.HandleFunc("/domain/listAll", func(w http.ResponseWriter, r *http.Request) {
+		var request ListDomainsRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if assert.NotNil(t, request.Start) && assert.NotNil(t, request.IncludeLabels) {
+			starts = append(starts, *request.Start)
+			assert.Equal(t, "yes", *request.IncludeLabels)
+		}
+
+		// A full page followed by a partial one
+		n := domainsPageSize
+		if *request.Start != "0" {
+			n = 2
+		}
+		domains := make([]map[string]any, n)
+		for i := range domains {
+			domains[i] = map[string]any{"domain": fmt.Sprintf("example%d.com", i), "createDate": "2024-01-01 00:00:00", "expireDate": "2025-01-01 00:00:00"}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"status": "SUCCESS", "domains": domains}))
+	})
+
+	domains, err := client.Domains.ListAllDomains(context.Background(), &DomainListOptions{Start: String("5"), IncludeLabels: String("yes")})
+
+	assert.NoError(t, err)
+	assert.Len(t, domains, domainsPageSize+2)
+	assert.Equal(t, []string{"0", "1000"}, starts)
+}
+
 func TestDomainsService_ListDomains_InvalidTime(t *testing.T) {
 	setupMockServer(true)
 	defer teardownMockServer()
@@ -0,0 +1,293 @@
+// Package exporter exposes the health of the domains of a Porkbun account, and of the API calls
+// made to it, as Prometheus metrics.
+//
+//	requests := exporter.NewRequestMetrics(nil)
+//	httpClient := requests.Instrument(nil)
+//	client := porkbun.NewClient(&porkbun.Options{ApiKey: key, SecretApiKey: secret, HttpClient: &httpClient})
+//
+//	e := exporter.NewExporter(client.Dns, client.Domains, client.Ssl, &exporter.Options{Requests: requests})
+//	go e.Run(ctx)
+//	http.Handle("/metrics", e)
+//
+// The domains, records and certificates are retrieved in the background every RefreshInterval and
+// scrapes are served from that cache, so they never wait for the API. Days remaining are computed
+// at scrape time.
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+const (
+	// DefaultRefreshInterval is the default time between two refreshes of the cache.
+	DefaultRefreshInterval = 5 * time.Minute
+	// DefaultConcurrency is the default number of domains refreshed in parallel.
+	DefaultConcurrency = 4
+
+	// contentType is the media type of the Prometheus text format.
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// Options defines the configuration options for the Exporter.
+type Options struct {
+	Domains         []string        // Domains to export, all domains in the account if empty.
+	RefreshInterval time.Duration   // Time between refreshes in Run, defaults to DefaultRefreshInterval.
+	Concurrency     int             // Number of domains refreshed in parallel, defaults to DefaultConcurrency.
+	Requests        *RequestMetrics // Request metrics of the client to expose as well, if not nil.
+	Logger          *log.Logger     // Logger for refresh errors, discarded if nil.
+}
+
+// Exporter serves the cached metrics of the account over HTTP. It is safe for concurrent use.
+type Exporter struct {
+	dns     porkbun.DNSAPI
+	domains porkbun.DomainsAPI
+	ssl     porkbun.SSLAPI
+	options Options
+
+	mu    sync.RWMutex
+	cache cache
+
+	// now and after are replaced in tests.
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// cache holds the result of the last refreshes.
+type cache struct {
+	domains     []domainState // Domains of the last successful listing, in order
+	refreshed   time.Time     // End of the last refresh, zero before the first one
+	duration    time.Duration // Duration of the last refresh
+	succeeded   bool          // Whether the last refresh succeeded entirely
+	lastSuccess time.Time     // End of the last successful refresh
+}
+
+// domainState holds what is known about a domain.
+type domainState struct {
+	domain      porkbun.Domain
+	records     map[string]int // Number of records by type, nil if they could not be retrieved
+	certExpiry  time.Time      // Expiry of the SSL certificate, zero if not available
+	recordsOK   bool           // Whether the records were retrieved
+	certificate bool           // Whether the certificate was retrieved
+}
+
+// NewExporter initializes a new Exporter using the provided DNS, domains and SSL APIs, usually the
+// services of a client, and options.
+func NewExporter(dns porkbun.DNSAPI, domains porkbun.DomainsAPI, ssl porkbun.SSLAPI, options *Options) *Exporter {
+	e := &Exporter{dns: dns, domains: domains, ssl: ssl, now: time.Now, after: time.After}
+
+	if options != nil {
+		e.options = *options
+	}
+	if e.options.RefreshInterval <= 0 {
+		e.options.RefreshInterval = DefaultRefreshInterval
+	}
+	if e.options.Concurrency <= 0 {
+		e.options.Concurrency = DefaultConcurrency
+	}
+	if e.options.Logger == nil {
+		e.options.Logger = log.New(io.Discard, "", 0)
+	}
+
+	return e
+}
+
+// Refresh retrieves the domains, their records and certificates, and replaces the cache. If the
+// domains cannot be listed the previous cache is kept. Domains whose records cannot be retrieved
+// are exported without record counts. Missing certificates are not errors, as many domains have
+// none.
+func (e *Exporter) Refresh(ctx context.Context) error {
+	start := e.now()
+	domains, err := e.listDomains(ctx)
+	if err != nil {
+		e.mu.Lock()
+		e.cache.refreshed, e.cache.duration, e.cache.succeeded = e.now(), e.now().Sub(start), false
+		e.mu.Unlock()
+		return err
+	}
+
+	states := make([]domainState, len(domains))
+	errs := make([]error, len(domains))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < e.options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				states[j], errs[j] = e.inspect(ctx, domains[j])
+			}
+		}()
+	}
+	for i := range domains {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err = errors.Join(errs...)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cache.domains = states
+	e.cache.refreshed, e.cache.duration, e.cache.succeeded = e.now(), e.now().Sub(start), err == nil
+	if err == nil {
+		e.cache.lastSuccess = e.cache.refreshed
+	}
+	return err
+}
+
+// inspect retrieves the records and certificate of a domain.
+func (e *Exporter) inspect(ctx context.Context, d porkbun.Domain) (domainState, error) {
+	state := domainState{domain: d}
+
+	var err error
+	resp, recordsErr := e.dns.GetRecords(ctx, d.Domain, nil)
+	if recordsErr == nil {
+		state.recordsOK = true
+		state.records = make(map[string]int)
+		for _, r := range resp.Records {
+			state.records[string(r.Type)]++
+		}
+	} else {
+		err = fmt.Errorf("%s: retrieving records: %w", d.Domain, recordsErr)
+	}
+
+	if bundle, sslErr := e.ssl.Retrieve(ctx, d.Domain); sslErr == nil {
+		if leaf, leafErr := bundle.Leaf(); leafErr == nil {
+			state.certificate = true
+			state.certExpiry = leaf.NotAfter
+		}
+	}
+
+	return state, err
+}
+
+// listDomains returns the exported domains of the account, in order.
+func (e *Exporter) listDomains(ctx context.Context) ([]porkbun.Domain, error) {
+	selected := make(map[string]bool, len(e.options.Domains))
+	for _, name := range e.options.Domains {
+		selected[porkbun.NormalizeName(name)] = true
+	}
+
+	all, err := e.domains.ListAllDomains(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing domains: %w", err)
+	}
+
+	var domains []porkbun.Domain
+	for _, d := range all {
+		d.Domain = strings.ToLower(d.Domain)
+		if len(selected) == 0 || selected[d.Domain] {
+			domains = append(domains, d)
+		}
+	}
+	return domains, nil
+}
+
+// Run refreshes the cache at once, then every RefreshInterval until the context is cancelled.
+// Failed refreshes are logged and the previous data keeps being served.
+func (e *Exporter) Run(ctx context.Context) error {
+	for {
+		if err := e.Refresh(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			e.options.Logger.Printf("refresh failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.after(e.options.RefreshInterval):
+		}
+	}
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	if err := e.WriteMetrics(w); err != nil {
+		e.options.Logger.Printf("writing metrics: %v", err)
+	}
+}
+
+// WriteMetrics writes the metrics in the Prometheus text format.
+func (e *Exporter) WriteMetrics(w io.Writer) error {
+	e.mu.RLock()
+	c := e.cache
+	e.mu.RUnlock()
+
+	return write(w, e.families(c))
+}
+
+// families returns the metrics of the cache.
+func (e *Exporter) families(c cache) []*family {
+	now := e.now()
+
+	expiryDays := &family{name: "porkbun_domain_expiry_days", help: "Days until the domain expires, negative once expired.", typ: typeGauge}
+	expiry := &family{name: "porkbun_domain_expiry_timestamp_seconds", help: "Unix time the domain expires.", typ: typeGauge}
+	autoRenew := &family{name: "porkbun_domain_auto_renew", help: "Whether the domain renews automatically.", typ: typeGauge}
+	securityLock := &family{name: "porkbun_domain_security_lock", help: "Whether the domain has a security lock.", typ: typeGauge}
+	records := &family{name: "porkbun_domain_records", help: "Number of DNS records of the domain by type.", typ: typeGauge}
+	certDays := &family{name: "porkbun_certificate_expiry_days", help: "Days until the SSL certificate of the domain expires, negative once expired.", typ: typeGauge}
+	domainUp := &family{name: "porkbun_domain_refresh_success", help: "Whether the records of the domain were retrieved by the last refresh.", typ: typeGauge}
+
+	for _, d := range c.domains {
+		name := label{"domain", d.domain.Domain}
+		if !d.domain.ExpireDate.IsZero() {
+			expiryDays.add(days(d.domain.ExpireDate.Sub(now)), name)
+			expiry.add(float64(d.domain.ExpireDate.Unix()), name)
+		}
+		autoRenew.add(boolValue(bool(d.domain.AutoRenew)), name)
+		securityLock.add(boolValue(bool(d.domain.SecurityLock)), name)
+		for _, t := range sortedKeys(d.records) {
+			records.add(float64(d.records[t]), name, label{"type", t})
+		}
+		if d.certificate {
+			certDays.add(days(d.certExpiry.Sub(now)), name)
+		}
+		domainUp.add(boolValue(d.recordsOK), name)
+	}
+
+	families := []*family{expiryDays, expiry, autoRenew, securityLock, records, certDays, domainUp}
+
+	if !c.refreshed.IsZero() {
+		success := &family{name: "porkbun_exporter_refresh_success", help: "Whether the last refresh of the cache succeeded.", typ: typeGauge}
+		success.add(boolValue(c.succeeded))
+		duration := &family{name: "porkbun_exporter_refresh_duration_seconds", help: "Duration of the last refresh of the cache.", typ: typeGauge}
+		duration.add(c.duration.Seconds())
+		families = append(families, success, duration)
+	}
+	if !c.lastSuccess.IsZero() {
+		last := &family{name: "porkbun_exporter_last_success_timestamp_seconds", help: "Unix time of the last successful refresh of the cache.", typ: typeGauge}
+		last.add(float64(c.lastSuccess.Unix()))
+		families = append(families, last)
+	}
+
+	if e.options.Requests != nil {
+		families = append(families, e.options.Requests.families()...)
+	}
+	return families
+}
+
+// days converts a duration to days.
+func days(d time.Duration) float64 {
+	return d.Hours() / 24
+}
@@ -0,0 +1,218 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/internal/testcert"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+)
+
+var testNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func setupServer(t *testing.T) *porkbuntest.Server {
+	server := porkbuntest.NewServer(nil)
+	t.Cleanup(server.Close)
+
+	server.AddDomain(porkbun.Domain{
+		Domain:       "example.com",
+		ExpireDate:   testNow.Add(30 * 24 * time.Hour),
+		AutoRenew:    true,
+		SecurityLock: true,
+	})
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "api", Type: porkbun.A, Content: "192.0.2.2"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.TXT, Content: "hello"})
+	server.AddDomain(porkbun.Domain{Domain: "example.org", ExpireDate: testNow.Add(-12 * time.Hour)})
+
+	bundle, err := testcert.Generate([]string{"example.com"}, testNow.Add(-80*24*time.Hour), testNow.Add(10*24*time.Hour))
+	assert.NoError(t, err)
+	server.SetSSL("example.com", porkbun.SslRetrieveResponse{
+		Certificatechain: bundle.CertificateChain,
+		Privatekey:       bundle.PrivateKey,
+		Publickey:        bundle.PublicKey,
+	})
+	return server
+}
+
+// newTestExporter creates an exporter whose clock is stopped at testNow.
+func newTestExporter(client *porkbun.Client, options *Options) *Exporter {
+	e := NewExporter(client.Dns, client.Domains, client.Ssl, options)
+	e.now = func() time.Time { return testNow }
+	return e
+}
+
+// metrics returns the lines of the exported metrics, without the comments.
+func metrics(t *testing.T, e *Exporter) []string {
+	var buf bytes.Buffer
+	assert.NoError(t, e.WriteMetrics(&buf))
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestExporter_Refresh(t *testing.T) {
+	server := setupServer(t)
+	e := newTestExporter(server.Client(), nil)
+
+	// Nothing is exported before the first refresh
+	assert.Empty(t, metrics(t, e))
+
+	assert.NoError(t, e.Refresh(context.Background()))
+	assert.Equal(t, []string{
+		`porkbun_domain_expiry_days{domain="example.com"} 30`,
+		`porkbun_domain_expiry_days{domain="example.org"} -0.5`,
+		`porkbun_domain_expiry_timestamp_seconds{domain="example.com"} 1.7382816e+09`,
+		`porkbun_domain_expiry_timestamp_seconds{domain="example.org"} 1.7356464e+09`,
+		`porkbun_domain_auto_renew{domain="example.com"} 1`,
+		`porkbun_domain_auto_renew{domain="example.org"} 0`,
+		`porkbun_domain_security_lock{domain="example.com"} 1`,
+		`porkbun_domain_security_lock{domain="example.org"} 0`,
+		`porkbun_domain_records{domain="example.com",type="A"} 2`,
+		`porkbun_domain_records{domain="example.com",type="TXT"} 1`,
+		`porkbun_certificate_expiry_days{domain="example.com"} 10`,
+		`porkbun_domain_refresh_success{domain="example.com"} 1`,
+		`porkbun_domain_refresh_success{domain="example.org"} 1`,
+		`porkbun_exporter_refresh_success 1`,
+		`porkbun_exporter_refresh_duration_seconds 0`,
+		`porkbun_exporter_last_success_timestamp_seconds 1.7356896e+09`,
+	}, metrics(t, e))
+}
+
+func TestExporter_Domains(t *testing.T) {
+	server := setupServer(t)
+	e := newTestExporter(server.Client(), &Options{Domains: []string{"Example.org."}})
+
+	assert.NoError(t, e.Refresh(context.Background()))
+	for _, line := range metrics(t, e) {
+		assert.NotContains(t, line, "example.com")
+	}
+	assert.Contains(t, metrics(t, e), `porkbun_domain_expiry_days{domain="example.org"} -0.5`)
+}
+
+func TestExporter_DaysAtScrapeTime(t *testing.T) {
+	server := setupServer(t)
+	e := newTestExporter(server.Client(), &Options{Domains: []string{"example.com"}})
+	assert.NoError(t, e.Refresh(context.Background()))
+
+	e.now = func() time.Time { return testNow.Add(36 * time.Hour) }
+	lines := metrics(t, e)
+	assert.Contains(t, lines, `porkbun_domain_expiry_days{domain="example.com"} 28.5`)
+	assert.Contains(t, lines, `porkbun_certificate_expiry_days{domain="example.com"} 8.5`)
+}
+
+func TestExporter_RefreshErrors(t *testing.T) {
+	server := setupServer(t)
+
+	// Fail the requests while failing is set
+	var failing atomic.Bool
+	var failRecords atomic.Bool
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() || (failRecords.Load() && strings.Contains(r.URL.Path, "/dns/retrieve/example.org")) {
+			http.Error(w, `{"status":"ERROR","message":"unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer api.Close()
+
+	client := porkbun.NewClient(&porkbun.Options{
+		ApiKey:       porkbuntest.DefaultApiKey,
+		SecretApiKey: porkbuntest.DefaultSecretApiKey,
+		BaseURL:      api.URL,
+	})
+	e := newTestExporter(client, nil)
+	ctx := context.Background()
+
+	// A failed listing before the first refresh exports no domains
+	failing.Store(true)
+	assert.Error(t, e.Refresh(ctx))
+	assert.Equal(t, []string{
+		`porkbun_exporter_refresh_success 0`,
+		`porkbun_exporter_refresh_duration_seconds 0`,
+	}, metrics(t, e))
+
+	// Domains whose records fail are exported without record counts
+	failing.Store(false)
+	failRecords.Store(true)
+	err := e.Refresh(ctx)
+	assert.ErrorContains(t, err, "example.org: retrieving records")
+	lines := metrics(t, e)
+	assert.Contains(t, lines, `porkbun_domain_records{domain="example.com",type="A"} 2`)
+	assert.Contains(t, lines, `porkbun_domain_refresh_success{domain="example.org"} 0`)
+	assert.Contains(t, lines, `porkbun_domain_expiry_days{domain="example.org"} -0.5`)
+	assert.Contains(t, lines, `porkbun_exporter_refresh_success 0`)
+	assert.NotContains(t, strings.Join(lines, "\n"), "porkbun_exporter_last_success_timestamp_seconds")
+
+	// A failed listing keeps the previous domains
+	failRecords.Store(false)
+	assert.NoError(t, e.Refresh(ctx))
+	failing.Store(true)
+	assert.Error(t, e.Refresh(ctx))
+	lines = metrics(t, e)
+	assert.Contains(t, lines, `porkbun_domain_records{domain="example.com",type="A"} 2`)
+	assert.Contains(t, lines, `porkbun_exporter_refresh_success 0`)
+	assert.Contains(t, lines, `porkbun_exporter_last_success_timestamp_seconds 1.7356896e+09`)
+}
+
+func TestExporter_Requests(t *testing.T) {
+	server := setupServer(t)
+	requests := NewRequestMetrics(nil)
+	e := newTestExporter(newInstrumentedClient(server, requests), &Options{Requests: requests})
+
+	assert.NoError(t, e.Refresh(context.Background()))
+	lines := metrics(t, e)
+	assert.Contains(t, lines, `porkbun_api_requests_total{operation="domain/listAll",outcome="success"} 1`)
+	assert.Contains(t, lines, `porkbun_api_requests_total{operation="dns/retrieve",outcome="success"} 2`)
+	assert.Contains(t, lines, `porkbun_api_requests_total{operation="ssl/retrieve",outcome="success"} 1`)
+	assert.Contains(t, lines, `porkbun_api_requests_total{operation="ssl/retrieve",outcome="api_error"} 1`)
+	assert.Contains(t, lines, `porkbun_api_request_duration_seconds_count{operation="dns/retrieve",outcome="success"} 2`)
+}
+
+func TestExporter_ServeHTTP(t *testing.T) {
+	server := setupServer(t)
+	e := newTestExporter(server.Client(), nil)
+	assert.NoError(t, e.Refresh(context.Background()))
+
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "# TYPE porkbun_domain_expiry_days gauge\n")
+	assert.Contains(t, recorder.Body.String(), `porkbun_domain_auto_renew{domain="example.com"} 1`)
+}
+
+func TestExporter_Run(t *testing.T) {
+	server := setupServer(t)
+	e := newTestExporter(server.Client(), &Options{RefreshInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waits := make(chan time.Duration)
+	e.after = func(d time.Duration) <-chan time.Time {
+		waits <- d
+		return make(chan time.Time)
+	}
+
+	done := make(chan error)
+	go func() { done <- e.Run(ctx) }()
+
+	// The cache is filled before the first wait
+	assert.Equal(t, time.Hour, <-waits)
+	assert.Contains(t, metrics(t, e), `porkbun_exporter_refresh_success 1`)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
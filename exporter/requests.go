@@ -0,0 +1,162 @@
+package exporter
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// Outcomes of API requests.
+const (
+	OutcomeSuccess      = "success"       // The API answered with 200 OK
+	OutcomeAPIError     = "api_error"     // The API answered with an error status
+	OutcomeNetworkError = "network_error" // No response, e.g. a timeout
+)
+
+// DefaultBuckets are the upper bounds in seconds of the request latency histogram buckets.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// apiGroups are the first path segments of the API endpoints, see operation.
+var apiGroups = map[string]bool{"dns": true, "domain": true, "ssl": true, "pricing": true, "ping": true}
+
+// RequestMetrics counts the API requests of the clients it instruments, with their latency, by
+// operation and outcome. It is safe for concurrent use.
+type RequestMetrics struct {
+	buckets []float64
+
+	mu    sync.Mutex
+	stats map[requestKey]*requestStats
+}
+
+// requestKey identifies the requests of an operation with an outcome.
+type requestKey struct {
+	operation, outcome string
+}
+
+// requestStats holds the counter and latency histogram of requests.
+type requestStats struct {
+	count   uint64
+	sum     float64  // Total latency in seconds
+	buckets []uint64 // Non-cumulative counts by bucket of RequestMetrics.buckets
+}
+
+// NewRequestMetrics creates request metrics with the latency buckets, DefaultBuckets if nil.
+func NewRequestMetrics(buckets []float64) *RequestMetrics {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	return &RequestMetrics{buckets: buckets, stats: make(map[requestKey]*requestStats)}
+}
+
+// Instrument wraps an HTTP client, http.DefaultClient if nil, so the requests made through it are
+// recorded. Use the result as porkbun.Options.HttpClient.
+func (m *RequestMetrics) Instrument(next porkbun.HTTPClient) porkbun.HTTPClient {
+	if next == nil {
+		next = http.DefaultClient
+	}
+	return &instrumentedClient{next: next, metrics: m, now: time.Now}
+}
+
+// observe records a request.
+func (m *RequestMetrics) observe(operation, outcome string, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := requestKey{operation, outcome}
+	stats, ok := m.stats[key]
+	if !ok {
+		stats = &requestStats{buckets: make([]uint64, len(m.buckets))}
+		m.stats[key] = stats
+	}
+
+	seconds := latency.Seconds()
+	stats.count++
+	stats.sum += seconds
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+			break
+		}
+	}
+}
+
+// families returns the request counter and latency histogram.
+func (m *RequestMetrics) families() []*family {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]requestKey, 0, len(m.stats))
+	for key := range m.stats {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].outcome < keys[j].outcome
+	})
+
+	requests := &family{name: "porkbun_api_requests_total", help: "Porkbun API requests by operation and outcome.", typ: typeCounter}
+	latency := &family{name: "porkbun_api_request_duration_seconds", help: "Latency of the Porkbun API requests by operation and outcome.", typ: typeHistogram}
+	for _, key := range keys {
+		stats := m.stats[key]
+		op, out := label{"operation", key.operation}, label{"outcome", key.outcome}
+		requests.add(float64(stats.count), op, out)
+
+		var cumulative uint64
+		for i, bound := range m.buckets {
+			cumulative += stats.buckets[i]
+			latency.samples = append(latency.samples, sample{suffix: "_bucket", labels: []label{op, out, {"le", formatValue(bound)}}, value: float64(cumulative)})
+		}
+		latency.samples = append(latency.samples,
+			sample{suffix: "_bucket", labels: []label{op, out, {"le", "+Inf"}}, value: float64(stats.count)},
+			sample{suffix: "_sum", labels: []label{op, out}, value: stats.sum},
+			sample{suffix: "_count", labels: []label{op, out}, value: float64(stats.count)},
+		)
+	}
+	return []*family{requests, latency}
+}
+
+// instrumentedClient records the requests of the wrapped client.
+type instrumentedClient struct {
+	next    porkbun.HTTPClient
+	metrics *RequestMetrics
+	now     func() time.Time
+}
+
+// Do implements porkbun.HTTPClient.
+func (c *instrumentedClient) Do(req *http.Request) (*http.Response, error) {
+	start := c.now()
+	resp, err := c.next.Do(req)
+	latency := c.now().Sub(start)
+
+	outcome := OutcomeSuccess
+	switch {
+	case err != nil:
+		outcome = OutcomeNetworkError
+	case resp.StatusCode != http.StatusOK:
+		outcome = OutcomeAPIError
+	}
+	c.metrics.observe(operation(req.URL.Path), outcome, latency)
+	return resp, err
+}
+
+// operation returns the API operation of a request path, such as "dns/retrieve" for
+// /api/json/v3/dns/retrieve/example.com, or "other".
+func operation(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		if !apiGroups[s] {
+			continue
+		}
+		if i+1 < len(segments) {
+			return s + "/" + segments[i+1]
+		}
+		return s
+	}
+	return "other"
+}
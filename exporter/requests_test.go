@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+)
+
+// failingClient fails every request.
+type failingClient struct{}
+
+func (failingClient) Do(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+// newInstrumentedClient returns a client of the server whose requests are recorded by metrics.
+func newInstrumentedClient(server *porkbuntest.Server, metrics *RequestMetrics) *porkbun.Client {
+	httpClient := metrics.Instrument(nil)
+	return porkbun.NewClient(&porkbun.Options{
+		ApiKey:       porkbuntest.DefaultApiKey,
+		SecretApiKey: porkbuntest.DefaultSecretApiKey,
+		BaseURL:      server.URL,
+		HttpClient:   &httpClient,
+	})
+}
+
+func TestOperation(t *testing.T) {
+	assert.Equal(t, "dns/retrieve", operation("/api/json/v3/dns/retrieve/example.com"))
+	assert.Equal(t, "dns/retrieveByNameType", operation("/dns/retrieveByNameType/example.com/A/www"))
+	assert.Equal(t, "domain/listAll", operation("/api/json/v3/domain/listAll"))
+	assert.Equal(t, "ssl/retrieve", operation("/api/json/v3/ssl/retrieve/example.com"))
+	assert.Equal(t, "pricing/get", operation("/api/json/v3/pricing/get"))
+	assert.Equal(t, "ping", operation("/api/json/v3/ping"))
+	assert.Equal(t, "other", operation("/"))
+	assert.Equal(t, "other", operation("/api/json/v3/unknown/thing"))
+}
+
+func TestRequestMetrics(t *testing.T) {
+	metrics := NewRequestMetrics([]float64{0.5, 1})
+	metrics.observe("dns/retrieve", OutcomeSuccess, 200*time.Millisecond)
+	metrics.observe("dns/retrieve", OutcomeSuccess, 700*time.Millisecond)
+	metrics.observe("dns/retrieve", OutcomeSuccess, 3*time.Second)
+	metrics.observe("dns/retrieve", OutcomeAPIError, 100*time.Millisecond)
+
+	var buf bytes.Buffer
+	assert.NoError(t, write(&buf, metrics.families()))
+	assert.Equal(t, `# HELP porkbun_api_requests_total Porkbun API requests by operation and outcome.
+# TYPE porkbun_api_requests_total counter
+porkbun_api_requests_total{operation="dns/retrieve",outcome="api_error"} 1
+porkbun_api_requests_total{operation="dns/retrieve",outcome="success"} 3
+# HELP porkbun_api_request_duration_seconds Latency of the Porkbun API requests by operation and outcome.
+# TYPE porkbun_api_request_duration_seconds histogram
+porkbun_api_request_duration_seconds_bucket{operation="dns/retrieve",outcome="api_error",le="0.5"} 1
+porkbun_api_request_duration_seconds_bucket{operation="dns/retrieve",outcome="api_error",le="1"} 1
+porkbun_api_request_duration_seconds_bucket{operation="dns/retrieve",outcome="api_error",le="+Inf"} 1
+porkbun_api_request_duration_seconds_sum{operation="dns/retrieve",outcome="api_error"} 0.1
+porkbun_api_request_duration_seconds_count{operation="dns/retrieve",outcome="api_error"} 1
+porkbun_api_request_duration_seconds_bucket{operation="dns/retrieve",outcome="success",le="0.5"} 1
+porkbun_api_request_duration_seconds_bucket{operation="dns/retrieve",outcome="success",le="1"} 2
+porkbun_api_request_duration_seconds_bucket{operation="dns/retrieve",outcome="success",le="+Inf"} 3
+porkbun_api_request_duration_seconds_sum{operation="dns/retrieve",outcome="success"} 3.9
+porkbun_api_request_duration_seconds_count{operation="dns/retrieve",outcome="success"} 3
+`, buf.String())
+}
+
+func TestRequestMetrics_Outcomes(t *testing.T) {
+	server := porkbuntest.NewServer(nil)
+	defer server.Close()
+	server.AddDomain(porkbun.Domain{Domain: "example.com"})
+
+	metrics := NewRequestMetrics(nil)
+	client := newInstrumentedClient(server, metrics)
+	ctx := context.Background()
+
+	_, err := client.Dns.GetRecords(ctx, "example.com", nil)
+	assert.NoError(t, err)
+	_, err = client.Dns.GetRecords(ctx, "example.org", nil)
+	assert.Error(t, err)
+	_, err = client.Domains.ListDomains(ctx, nil)
+	assert.NoError(t, err)
+
+	var failing porkbun.HTTPClient = failingClient{}
+	failing = metrics.Instrument(failing)
+	_, err = porkbun.NewClient(&porkbun.Options{BaseURL: server.URL, HttpClient: &failing}).Ping(ctx)
+	assert.Error(t, err)
+
+	counts := make(map[requestKey]uint64)
+	for key, stats := range metrics.stats {
+		counts[key] = stats.count
+		assert.Len(t, stats.buckets, len(DefaultBuckets))
+	}
+	assert.Equal(t, map[requestKey]uint64{
+		{"dns/retrieve", OutcomeSuccess}:   1,
+		{"dns/retrieve", OutcomeAPIError}:  1,
+		{"domain/listAll", OutcomeSuccess}: 1,
+		{"ping", OutcomeNetworkError}:      1,
+	}, counts)
+}
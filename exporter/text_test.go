@@ -0,0 +1,35 @@
+package exporter
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	gauge := &family{name: "test_gauge", help: "A gauge\nwith a \\ backslash.", typ: typeGauge}
+	gauge.add(1.5, label{"domain", "example.com"}, label{"note", "say \"hi\"\n"})
+	gauge.add(-2)
+	empty := &family{name: "test_empty", help: "No samples.", typ: typeCounter}
+
+	var buf bytes.Buffer
+	assert.NoError(t, write(&buf, []*family{gauge, empty}))
+	assert.Equal(t, `# HELP test_gauge A gauge\nwith a \\ backslash.
+# TYPE test_gauge gauge
+test_gauge{domain="example.com",note="say \"hi\"\n"} 1.5
+test_gauge -2
+# HELP test_empty No samples.
+# TYPE test_empty counter
+`, buf.String())
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "0", formatValue(0))
+	assert.Equal(t, "0.25", formatValue(0.25))
+	assert.Equal(t, "1e+06", formatValue(1e6))
+	assert.Equal(t, "+Inf", formatValue(math.Inf(1)))
+	assert.Equal(t, "-Inf", formatValue(math.Inf(-1)))
+	assert.Equal(t, "NaN", formatValue(math.NaN()))
+}
@@ -0,0 +1,117 @@
+package externaldns
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// Endpoint is the external-dns representation of a DNS record set.
+type Endpoint struct {
+	DNSName          string             `json:"dnsName,omitempty"`          // The fully qualified name of the record set
+	Targets          []string           `json:"targets,omitempty"`          // The values of the record set
+	RecordType       string             `json:"recordType,omitempty"`       // The DNS record type
+	SetIdentifier    string             `json:"setIdentifier,omitempty"`    // Identifier for routing policies (unused by Porkbun)
+	RecordTTL        int64              `json:"recordTTL,omitempty"`        // TTL of the record set, zero if not configured
+	Labels           map[string]string  `json:"labels,omitempty"`           // Labels attached by external-dns
+	ProviderSpecific []ProviderProperty `json:"providerSpecific,omitempty"` // Provider specific properties
+}
+
+// ProviderProperty is a provider specific name/value pair attached to an Endpoint.
+type ProviderProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Changes holds the record sets external-dns wants to create, update and delete.
+// The field names match the JSON emitted by external-dns, which does not use tags.
+type Changes struct {
+	Create    []*Endpoint `json:"Create"`
+	UpdateOld []*Endpoint `json:"UpdateOld"`
+	UpdateNew []*Endpoint `json:"UpdateNew"`
+	Delete    []*Endpoint `json:"Delete"`
+}
+
+// DomainFilter restricts the zones and names managed by the provider.
+type DomainFilter struct {
+	Include []string // Domains (and their subdomains) that are managed, all if empty
+	Exclude []string // Domains (and their subdomains) that are never managed
+}
+
+// NewDomainFilter creates a DomainFilter from include and exclude lists, normalising the entries.
+func NewDomainFilter(include, exclude []string) DomainFilter {
+	return DomainFilter{
+		Include: normalizeDomains(include),
+		Exclude: normalizeDomains(exclude),
+	}
+}
+
+// MarshalJSON encodes the filter in the format expected by the external-dns negotiation endpoint.
+func (f DomainFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Include []string `json:"include,omitempty"`
+		Exclude []string `json:"exclude,omitempty"`
+	}{
+		Include: f.Include,
+		Exclude: f.Exclude,
+	})
+}
+
+// Match reports whether the name is managed according to the filter.
+func (f DomainFilter) Match(name string) bool {
+	name = porkbun.NormalizeName(name)
+
+	for _, exclude := range f.Exclude {
+		if isSubdomain(name, exclude) {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, include := range f.Include {
+		if isSubdomain(name, include) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchZone reports whether any name in the zone could be managed according to the filter.
+func (f DomainFilter) MatchZone(zone string) bool {
+	zone = porkbun.NormalizeName(zone)
+
+	for _, exclude := range f.Exclude {
+		if isSubdomain(zone, exclude) {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, include := range f.Include {
+		if isSubdomain(zone, include) || isSubdomain(include, zone) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDomains normalises a list of domains, dropping empty entries.
+func normalizeDomains(domains []string) []string {
+	var result []string
+	for _, d := range domains {
+		if d = strings.TrimPrefix(porkbun.NormalizeName(d), "."); d != "" {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// isSubdomain reports whether name is equal to or a subdomain of domain.
+func isSubdomain(name, domain string) bool {
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
@@ -0,0 +1,63 @@
+package externaldns
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainFilter_Match(t *testing.T) {
+	filter := NewDomainFilter([]string{"Example.com.", " ", ".example.org"}, []string{"internal.example.com"})
+
+	assert.Equal(t, []string{"example.com", "example.org"}, filter.Include)
+
+	assert.True(t, filter.Match("example.com"))
+	assert.True(t, filter.Match("www.example.com."))
+	assert.True(t, filter.Match("API.example.org"))
+	assert.False(t, filter.Match("badexample.com"))
+	assert.False(t, filter.Match("internal.example.com"))
+	assert.False(t, filter.Match("db.internal.example.com"))
+	assert.False(t, filter.Match("example.net"))
+}
+
+func TestDomainFilter_MatchEmpty(t *testing.T) {
+	filter := DomainFilter{}
+
+	assert.True(t, filter.Match("anything.example.net"))
+	assert.True(t, filter.MatchZone("example.net"))
+}
+
+func TestDomainFilter_MatchZone(t *testing.T) {
+	filter := NewDomainFilter([]string{"dev.example.com"}, []string{"example.org"})
+
+	assert.True(t, filter.MatchZone("example.com"))
+	assert.False(t, filter.MatchZone("example.org"))
+	assert.False(t, filter.MatchZone("example.net"))
+}
+
+func TestDomainFilter_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(NewDomainFilter([]string{"example.com"}, nil))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"include":["example.com"]}`, string(data))
+
+	data, err = json.Marshal(DomainFilter{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(data))
+}
+
+func TestRecordTargetConversion(t *testing.T) {
+	content, prio := recordContent("MX", "10 mail.example.com")
+	assert.Equal(t, "mail.example.com", content)
+	assert.Equal(t, "10", prio)
+
+	content, prio = recordContent("SRV", "10 5 5060 sip.example.com")
+	assert.Equal(t, "5 5060 sip.example.com", content)
+	assert.Equal(t, "10", prio)
+
+	content, _ = recordContent("TXT", `"heritage=external-dns,external-dns/owner=default"`)
+	assert.Equal(t, "heritage=external-dns,external-dns/owner=default", content)
+
+	content, _ = recordContent("TXT", `"v=spf1 -all"`)
+	assert.Equal(t, `"v=spf1 -all"`, content)
+}
@@ -0,0 +1,166 @@
+package externaldns
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// fakeRecord is a DNS record stored by the fake Porkbun API.
+type fakeRecord struct {
+	ID      int64
+	Zone    string
+	Name    string // Fully qualified name
+	Type    string
+	Content string
+	TTL     string
+	Prio    string
+}
+
+// fakePorkbun is a minimal in-memory implementation of the Porkbun domain and DNS endpoints.
+type fakePorkbun struct {
+	mu      sync.Mutex
+	domains []string
+	records []*fakeRecord
+	nextID  int64
+}
+
+func (f *fakePorkbun) add(zone, name, recordType, content, ttl, prio string) {
+	f.nextID++
+	fqdn := zone
+	if name != "" {
+		fqdn = name + "." + zone
+	}
+	f.records = append(f.records, &fakeRecord{ID: f.nextID, Zone: zone, Name: fqdn, Type: recordType, Content: content, TTL: ttl, Prio: prio})
+}
+
+func (f *fakePorkbun) find(zone, name, recordType string) []*fakeRecord {
+	var result []*fakeRecord
+	for _, r := range f.records {
+		if r.Zone == zone && r.Name == name && r.Type == recordType {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func (f *fakePorkbun) writeRecords(w http.ResponseWriter, records []*fakeRecord) {
+	list := make([]map[string]interface{}, 0, len(records))
+	for _, r := range records {
+		list = append(list, map[string]interface{}{
+			"id": strconv.FormatInt(r.ID, 10), "name": r.Name, "type": r.Type,
+			"content": r.Content, "ttl": r.TTL, "prio": r.Prio,
+		})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "SUCCESS", "records": list})
+}
+
+func (f *fakePorkbun) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	str := func(key string) string {
+		v, _ := body[key].(string)
+		return v
+	}
+
+	switch strings.Join(parts[:2], "/") {
+	case "domain/listAll":
+		var domains []map[string]interface{}
+		if str("start") == "0" {
+			for _, d := range f.domains {
+				domains = append(domains, map[string]interface{}{
+					"domain": d, "status": "ACTIVE", "createDate": "2020-01-01 00:00:00", "expireDate": "2030-01-01 00:00:00",
+					"securityLock": "1", "whoisPrivacy": "1", "autoRenew": 1, "notLocal": 0,
+				})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "SUCCESS", "domains": domains})
+
+	case "dns/retrieve":
+		var records []*fakeRecord
+		for _, rec := range f.records {
+			if rec.Zone == parts[2] {
+				records = append(records, rec)
+			}
+		}
+		f.writeRecords(w, records)
+
+	case "dns/retrieveByNameType":
+		name := parts[2]
+		if len(parts) > 4 {
+			name = parts[4] + "." + parts[2]
+		}
+		f.writeRecords(w, f.find(parts[2], name, parts[3]))
+
+	case "dns/create":
+		if str("type") == "CNAME" && len(f.find(parts[2], qualify(str("name"), parts[2]), "CNAME")) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"ERROR","message":"Could not add CNAME record: duplicate."}`)
+			return
+		}
+		f.add(parts[2], str("name"), str("type"), str("content"), str("ttl"), str("prio"))
+		fmt.Fprintf(w, `{"status":"SUCCESS","id":%d}`, f.nextID)
+
+	case "dns/edit":
+		id, _ := strconv.ParseInt(parts[3], 10, 64)
+		for _, rec := range f.records {
+			if rec.ID == id {
+				rec.Name = qualify(str("name"), parts[2])
+				rec.Content, rec.TTL, rec.Prio = str("content"), str("ttl"), str("prio")
+			}
+		}
+		fmt.Fprint(w, `{"status":"SUCCESS"}`)
+
+	case "dns/delete":
+		id, _ := strconv.ParseInt(parts[3], 10, 64)
+		for i, rec := range f.records {
+			if rec.ID == id {
+				f.records = append(f.records[:i], f.records[i+1:]...)
+				fmt.Fprint(w, `{"status":"SUCCESS"}`)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"status":"ERROR","message":"Invalid record ID."}`)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"status":"ERROR","message":"Not found."}`)
+	}
+}
+
+// qualify returns the fully qualified name for a relative record name.
+func qualify(name, zone string) string {
+	if name == "" {
+		return zone
+	}
+	return name + "." + zone
+}
+
+func setupFakePorkbun(t *testing.T) (*porkbun.Client, *fakePorkbun) {
+	fake := &fakePorkbun{domains: []string{"example.com", "example.org"}}
+	fake.add("example.com", "", "A", "1.2.3.4", "600", "")
+	fake.add("example.com", "", "NS", "maceio.porkbun.com", "86400", "")
+	fake.add("example.com", "", "MX", "mail.example.com", "600", "10")
+	fake.add("example.com", "www", "CNAME", "example.com", "600", "")
+	fake.add("example.com", "a-www", "TXT", "heritage=external-dns,external-dns/owner=default", "600", "")
+	fake.add("example.org", "api", "A", "5.6.7.8", "3600", "")
+	fake.add("example.org", "api", "A", "5.6.7.9", "3600", "")
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return porkbun.NewClient(&porkbun.Options{BaseURL: server.URL}), fake
+}
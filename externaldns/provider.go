@@ -0,0 +1,387 @@
+// Package externaldns implements a Kubernetes external-dns webhook provider backed by the Porkbun DNS API.
+//
+// The Provider translates external-dns endpoints into Porkbun DNS records, and the Server exposes it
+// over the external-dns webhook protocol (negotiation, records, adjustendpoints and apply changes).
+package externaldns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+const (
+	// MinTTL is the lowest TTL accepted by Porkbun.
+	MinTTL = 600
+
+	// ownershipPrefix is the prefix of TXT records created by the external-dns TXT registry.
+	ownershipPrefix = "heritage="
+)
+
+// supportedTypes lists the record types managed by the provider.
+var supportedTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"CNAME": true,
+	"TXT":   true,
+	"MX":    true,
+	"SRV":   true,
+	"CAA":   true,
+}
+
+// ProviderOptions defines the configuration options for the Provider.
+type ProviderOptions struct {
+	DomainFilter DomainFilter // Restricts the zones and names managed by the provider.
+}
+
+// Provider manages external-dns endpoints using the Porkbun DNS API.
+type Provider struct {
+	dns     porkbun.DNSAPI
+	domains porkbun.DomainsAPI
+	filter  DomainFilter
+}
+
+// NewProvider initializes a new Provider using the provided DNS and domains APIs, usually
+// client.Dns and client.Domains, and options.
+func NewProvider(dns porkbun.DNSAPI, domains porkbun.DomainsAPI, options *ProviderOptions) *Provider {
+	p := &Provider{dns: dns, domains: domains}
+	if options != nil {
+		p.filter = options.DomainFilter
+	}
+	return p
+}
+
+// DomainFilter returns the domain filter used by the provider.
+func (p *Provider) DomainFilter() DomainFilter {
+	return p.filter
+}
+
+// Zones returns the Porkbun domains that match the provider's domain filter.
+func (p *Provider) Zones(ctx context.Context) ([]string, error) {
+	domains, err := p.domains.ListAllDomains(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing domains: %w", err)
+	}
+
+	var zones []string
+	for _, domain := range domains {
+		if p.filter.MatchZone(domain.Domain) {
+			zones = append(zones, porkbun.NormalizeName(domain.Domain))
+		}
+	}
+
+	sort.Strings(zones)
+	return zones, nil
+}
+
+// Records returns all managed record sets in the matching zones.
+func (p *Provider) Records(ctx context.Context) ([]*Endpoint, error) {
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []*Endpoint
+	for _, zone := range zones {
+		resp, err := p.dns.GetRecords(ctx, zone, nil)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving records for %s: %w", zone, err)
+		}
+
+		endpoints = append(endpoints, p.groupRecords(resp.Records)...)
+	}
+
+	return endpoints, nil
+}
+
+// groupRecords converts Porkbun records into endpoints, grouping them by name and type.
+func (p *Provider) groupRecords(records []porkbun.DnsRecord) []*Endpoint {
+	type key struct{ name, recordType string }
+
+	groups := make(map[key]*Endpoint)
+	var keys []key
+
+	for _, record := range records {
+		recordType := record.Type.String()
+		name := porkbun.NormalizeName(record.Name)
+		if !supportedTypes[recordType] || !p.filter.Match(name) {
+			continue
+		}
+
+		k := key{name, recordType}
+		ep, ok := groups[k]
+		if !ok {
+			ep = &Endpoint{DNSName: name, RecordType: recordType}
+			if ttl, err := strconv.ParseInt(record.TTL, 10, 64); err == nil {
+				ep.RecordTTL = ttl
+			}
+			groups[k] = ep
+			keys = append(keys, k)
+		}
+		ep.Targets = append(ep.Targets, recordTarget(record))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].recordType < keys[j].recordType
+	})
+
+	endpoints := make([]*Endpoint, 0, len(keys))
+	for _, k := range keys {
+		ep := groups[k]
+		sort.Strings(ep.Targets)
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints
+}
+
+// AdjustEndpoints normalises the desired endpoints so they compare equal to what Records returns.
+func (p *Provider) AdjustEndpoints(endpoints []*Endpoint) []*Endpoint {
+	adjusted := make([]*Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep == nil {
+			continue
+		}
+
+		ep.DNSName = porkbun.NormalizeName(ep.DNSName)
+		if ep.RecordTTL > 0 && ep.RecordTTL < MinTTL {
+			ep.RecordTTL = MinTTL
+		}
+		switch ep.RecordType {
+		case "CNAME", "MX", "SRV":
+			for i, target := range ep.Targets {
+				ep.Targets[i] = porkbun.NormalizeName(target)
+			}
+		}
+		adjusted = append(adjusted, ep)
+	}
+	return adjusted
+}
+
+// ApplyChanges applies the requested changes, processing deletions first, then updates and creations.
+func (p *Provider) ApplyChanges(ctx context.Context, changes *Changes) error {
+	if changes == nil {
+		return nil
+	}
+
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, ep := range changes.Delete {
+		if err := p.apply(ctx, zones, ep, p.deleteEndpoint); err != nil {
+			return err
+		}
+	}
+	for _, ep := range changes.UpdateNew {
+		if err := p.apply(ctx, zones, ep, p.updateEndpoint); err != nil {
+			return err
+		}
+	}
+	for _, ep := range changes.Create {
+		if err := p.apply(ctx, zones, ep, p.createEndpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// endpointFunc applies a change for an endpoint to the given zone and relative name.
+type endpointFunc func(ctx context.Context, zone, subdomain string, ep *Endpoint) error
+
+// apply resolves the zone of an endpoint and calls fn, skipping endpoints the provider does not manage.
+func (p *Provider) apply(ctx context.Context, zones []string, ep *Endpoint, fn endpointFunc) error {
+	if ep == nil || !supportedTypes[ep.RecordType] || !p.filter.Match(ep.DNSName) {
+		return nil
+	}
+
+	name := porkbun.NormalizeName(ep.DNSName)
+	zone := findZone(zones, name)
+	if zone == "" {
+		return fmt.Errorf("no zone found for %s", ep.DNSName)
+	}
+
+	if err := fn(ctx, zone, porkbun.RelativeName(name, zone), ep); err != nil {
+		return fmt.Errorf("%s %s: %w", ep.RecordType, ep.DNSName, err)
+	}
+	return nil
+}
+
+// createEndpoint creates one record per target of the endpoint.
+func (p *Provider) createEndpoint(ctx context.Context, zone, subdomain string, ep *Endpoint) error {
+	for _, target := range ep.Targets {
+		if err := p.createRecord(ctx, zone, subdomain, ep, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteEndpoint deletes the existing records whose values are targets of the endpoint.
+func (p *Provider) deleteEndpoint(ctx context.Context, zone, subdomain string, ep *Endpoint) error {
+	existing, err := p.existingRecords(ctx, zone, subdomain, ep.RecordType)
+	if err != nil {
+		return err
+	}
+
+	targets := targetSet(ep)
+	for _, record := range existing {
+		if !targets[recordTarget(record)] || record.ID == nil {
+			continue
+		}
+		if _, err := p.dns.DeleteRecord(ctx, zone, *record.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateEndpoint converges the existing records of the endpoint's name and type to its targets and TTL.
+func (p *Provider) updateEndpoint(ctx context.Context, zone, subdomain string, ep *Endpoint) error {
+	existing, err := p.existingRecords(ctx, zone, subdomain, ep.RecordType)
+	if err != nil {
+		return err
+	}
+
+	targets := targetSet(ep)
+	present := make(map[string]bool)
+
+	for _, record := range existing {
+		if record.ID == nil {
+			continue
+		}
+
+		target := recordTarget(record)
+		if !targets[target] || present[target] {
+			if _, err := p.dns.DeleteRecord(ctx, zone, *record.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		present[target] = true
+
+		if ep.RecordTTL > 0 && record.TTL != strconv.FormatInt(ep.RecordTTL, 10) {
+			content, prio := recordContent(ep.RecordType, target)
+			_, err := p.dns.EditRecord(ctx, zone, *record.ID, &porkbun.EditRecord{
+				Name:    subdomain,
+				Type:    porkbun.DnsRecordType(ep.RecordType),
+				Content: content,
+				TTL:     recordTTL(ep),
+				Prio:    prio,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, target := range ep.Targets {
+		if present[target] {
+			continue
+		}
+		if err := p.createRecord(ctx, zone, subdomain, ep, target); err != nil {
+			return err
+		}
+		present[target] = true
+	}
+	return nil
+}
+
+// createRecord creates a single record for a target of the endpoint.
+func (p *Provider) createRecord(ctx context.Context, zone, subdomain string, ep *Endpoint, target string) error {
+	content, prio := recordContent(ep.RecordType, target)
+	_, err := p.dns.CreateRecord(ctx, zone, &porkbun.DnsRecord{
+		Name:    subdomain,
+		Type:    porkbun.DnsRecordType(ep.RecordType),
+		Content: content,
+		TTL:     recordTTL(ep),
+		Prio:    prio,
+	})
+	return err
+}
+
+// existingRecords retrieves the records with the given relative name and type.
+func (p *Provider) existingRecords(ctx context.Context, zone, subdomain, recordType string) ([]porkbun.DnsRecord, error) {
+	var sub *string
+	if subdomain != "" {
+		sub = porkbun.String(subdomain)
+	}
+
+	resp, err := p.dns.GetRecordsByType(ctx, zone, porkbun.DnsRecordType(recordType), sub)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Records, nil
+}
+
+// findZone returns the longest zone that contains the name, or an empty string.
+func findZone(zones []string, name string) string {
+	var match string
+	for _, zone := range zones {
+		if isSubdomain(name, zone) && len(zone) > len(match) {
+			match = zone
+		}
+	}
+	return match
+}
+
+// recordTTL returns the Porkbun TTL string for an endpoint, empty to use the API default.
+func recordTTL(ep *Endpoint) string {
+	if ep.RecordTTL <= 0 {
+		return ""
+	}
+	if ep.RecordTTL < MinTTL {
+		return strconv.Itoa(MinTTL)
+	}
+	return strconv.FormatInt(ep.RecordTTL, 10)
+}
+
+// targetSet returns the targets of an endpoint as a set.
+func targetSet(ep *Endpoint) map[string]bool {
+	set := make(map[string]bool, len(ep.Targets))
+	for _, target := range ep.Targets {
+		set[target] = true
+	}
+	return set
+}
+
+// recordTarget converts a Porkbun record into an external-dns target.
+// MX and SRV priorities are folded into the target, and TXT registry records are quoted.
+func recordTarget(record porkbun.DnsRecord) string {
+	switch record.Type {
+	case porkbun.MX, porkbun.SRV:
+		if record.Prio != "" {
+			return record.Prio + " " + record.Content
+		}
+	case porkbun.TXT:
+		if strings.HasPrefix(record.Content, ownershipPrefix) {
+			return strconv.Quote(record.Content)
+		}
+	}
+	return record.Content
+}
+
+// recordContent converts an external-dns target into Porkbun record content and priority.
+func recordContent(recordType, target string) (content, prio string) {
+	switch recordType {
+	case "MX", "SRV":
+		if fields := strings.SplitN(target, " ", 2); len(fields) == 2 {
+			if _, err := strconv.Atoi(fields[0]); err == nil {
+				return fields[1], fields[0]
+			}
+		}
+	case "TXT":
+		if unquoted, err := strconv.Unquote(target); err == nil && strings.HasPrefix(unquoted, ownershipPrefix) {
+			return unquoted, ""
+		}
+	}
+	return target, ""
+}
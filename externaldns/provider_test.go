@@ -0,0 +1,185 @@
+package externaldns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvider_Zones(t *testing.T) {
+	client, _ := setupFakePorkbun(t)
+
+	zones, err := NewProvider(client.Dns, client.Domains, nil).Zones(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "example.org"}, zones)
+
+	provider := NewProvider(client.Dns, client.Domains, &ProviderOptions{DomainFilter: NewDomainFilter([]string{"sub.example.org"}, nil)})
+	zones, err = provider.Zones(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.org"}, zones)
+}
+
+func TestProvider_Records(t *testing.T) {
+	client, _ := setupFakePorkbun(t)
+
+	endpoints, err := NewProvider(client.Dns, client.Domains, nil).Records(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*Endpoint{
+		{DNSName: "a-www.example.com", RecordType: "TXT", RecordTTL: 600, Targets: []string{`"heritage=external-dns,external-dns/owner=default"`}},
+		{DNSName: "example.com", RecordType: "A", RecordTTL: 600, Targets: []string{"1.2.3.4"}},
+		{DNSName: "example.com", RecordType: "MX", RecordTTL: 600, Targets: []string{"10 mail.example.com"}},
+		{DNSName: "www.example.com", RecordType: "CNAME", RecordTTL: 600, Targets: []string{"example.com"}},
+		{DNSName: "api.example.org", RecordType: "A", RecordTTL: 3600, Targets: []string{"5.6.7.8", "5.6.7.9"}},
+	}, endpoints)
+}
+
+func TestProvider_RecordsDomainFilter(t *testing.T) {
+	client, _ := setupFakePorkbun(t)
+
+	provider := NewProvider(client.Dns, client.Domains, &ProviderOptions{
+		DomainFilter: NewDomainFilter([]string{"example.com"}, []string{"www.example.com"}),
+	})
+
+	endpoints, err := provider.Records(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, endpoints, 3)
+	for _, ep := range endpoints {
+		assert.NotEqual(t, "www.example.com", ep.DNSName)
+		assert.NotEqual(t, "api.example.org", ep.DNSName)
+	}
+}
+
+func TestProvider_AdjustEndpoints(t *testing.T) {
+	provider := NewProvider(nil, nil, nil)
+
+	adjusted := provider.AdjustEndpoints([]*Endpoint{
+		{DNSName: "WWW.Example.com.", RecordType: "CNAME", RecordTTL: 60, Targets: []string{"Target.Example.net."}},
+		nil,
+		{DNSName: "txt.example.com", RecordType: "TXT", Targets: []string{`"Some Value."`}},
+	})
+
+	assert.Equal(t, []*Endpoint{
+		{DNSName: "www.example.com", RecordType: "CNAME", RecordTTL: MinTTL, Targets: []string{"target.example.net"}},
+		{DNSName: "txt.example.com", RecordType: "TXT", Targets: []string{`"Some Value."`}},
+	}, adjusted)
+}
+
+func TestProvider_ApplyChanges(t *testing.T) {
+	client, fake := setupFakePorkbun(t)
+	provider := NewProvider(client.Dns, client.Domains, nil)
+
+	err := provider.ApplyChanges(context.Background(), &Changes{
+		Create: []*Endpoint{
+			{DNSName: "new.example.com", RecordType: "A", Targets: []string{"9.9.9.9", "8.8.8.8"}},
+			{DNSName: "a-new.example.com", RecordType: "TXT", Targets: []string{`"heritage=external-dns,external-dns/owner=default"`}},
+		},
+		UpdateOld: []*Endpoint{
+			{DNSName: "api.example.org", RecordType: "A", RecordTTL: 3600, Targets: []string{"5.6.7.8", "5.6.7.9"}},
+		},
+		UpdateNew: []*Endpoint{
+			{DNSName: "api.example.org", RecordType: "A", RecordTTL: 1200, Targets: []string{"5.6.7.9", "5.6.7.10"}},
+		},
+		Delete: []*Endpoint{
+			{DNSName: "www.example.com", RecordType: "CNAME", Targets: []string{"example.com"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Empty(t, fake.find("example.com", "www.example.com", "CNAME"))
+
+	created := fake.find("example.com", "new.example.com", "A")
+	if assert.Len(t, created, 2) {
+		assert.Equal(t, "9.9.9.9", created[0].Content)
+		assert.Equal(t, "8.8.8.8", created[1].Content)
+		assert.Equal(t, "", created[0].TTL)
+	}
+
+	owner := fake.find("example.com", "a-new.example.com", "TXT")
+	if assert.Len(t, owner, 1) {
+		assert.Equal(t, "heritage=external-dns,external-dns/owner=default", owner[0].Content)
+	}
+
+	updated := fake.find("example.org", "api.example.org", "A")
+	if assert.Len(t, updated, 2) {
+		assert.Equal(t, "5.6.7.9", updated[0].Content)
+		assert.Equal(t, "1200", updated[0].TTL)
+		assert.Equal(t, "5.6.7.10", updated[1].Content)
+		assert.Equal(t, "1200", updated[1].TTL)
+	}
+}
+
+func TestProvider_ApplyChangesMX(t *testing.T) {
+	client, fake := setupFakePorkbun(t)
+	provider := NewProvider(client.Dns, client.Domains, nil)
+
+	err := provider.ApplyChanges(context.Background(), &Changes{
+		UpdateNew: []*Endpoint{
+			{DNSName: "example.com", RecordType: "MX", Targets: []string{"10 mail.example.com", "20 backup.example.com"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	records := fake.find("example.com", "example.com", "MX")
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "mail.example.com", records[0].Content)
+		assert.Equal(t, "backup.example.com", records[1].Content)
+		assert.Equal(t, "20", records[1].Prio)
+	}
+}
+
+func TestProvider_ApplyChangesReplaceCNAME(t *testing.T) {
+	client, fake := setupFakePorkbun(t)
+	provider := NewProvider(client.Dns, client.Domains, nil)
+
+	// Deletions are applied before creations, so a CNAME can be replaced in one batch
+	err := provider.ApplyChanges(context.Background(), &Changes{
+		Delete: []*Endpoint{{DNSName: "www.example.com", RecordType: "CNAME", Targets: []string{"example.com"}}},
+		Create: []*Endpoint{{DNSName: "www.example.com", RecordType: "CNAME", Targets: []string{"other.example.net"}}},
+	})
+	assert.NoError(t, err)
+
+	records := fake.find("example.com", "www.example.com", "CNAME")
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "other.example.net", records[0].Content)
+	}
+}
+
+func TestProvider_ApplyChangesSkipsFiltered(t *testing.T) {
+	client, fake := setupFakePorkbun(t)
+	provider := NewProvider(client.Dns, client.Domains, &ProviderOptions{DomainFilter: NewDomainFilter([]string{"example.com"}, nil)})
+
+	err := provider.ApplyChanges(context.Background(), &Changes{
+		Delete: []*Endpoint{
+			{DNSName: "api.example.org", RecordType: "A", Targets: []string{"5.6.7.8"}},
+			{DNSName: "example.com", RecordType: "NS", Targets: []string{"maceio.porkbun.com"}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, fake.find("example.org", "api.example.org", "A"), 2)
+	assert.Len(t, fake.find("example.com", "example.com", "NS"), 1)
+}
+
+func TestProvider_ApplyChangesUnknownZone(t *testing.T) {
+	client, _ := setupFakePorkbun(t)
+	provider := NewProvider(client.Dns, client.Domains, nil)
+
+	err := provider.ApplyChanges(context.Background(), &Changes{
+		Create: []*Endpoint{{DNSName: "www.example.net", RecordType: "A", Targets: []string{"1.1.1.1"}}},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no zone found for www.example.net")
+}
+
+func TestProvider_ApplyChangesError(t *testing.T) {
+	client, _ := setupFakePorkbun(t)
+	provider := NewProvider(client.Dns, client.Domains, nil)
+
+	err := provider.ApplyChanges(context.Background(), &Changes{
+		Create: []*Endpoint{{DNSName: "www.example.com", RecordType: "CNAME", Targets: []string{"other.example.net"}}},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "CNAME www.example.com")
+	assert.Contains(t, err.Error(), "duplicate")
+}
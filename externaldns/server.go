@@ -0,0 +1,128 @@
+package externaldns
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	// MediaType is the versioned media type used by the external-dns webhook protocol.
+	MediaType = "application/external.dns.webhook+json;version=1"
+
+	contentTypeHeader = "Content-Type"
+	varyHeader        = "Vary"
+)
+
+// Server exposes a Provider over the external-dns webhook protocol.
+type Server struct {
+	provider *Provider
+	mux      *http.ServeMux
+}
+
+// NewServer creates a new webhook Server for the provider.
+func NewServer(provider *Provider) *Server {
+	s := &Server{
+		provider: provider,
+		mux:      http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/", s.handleNegotiate)
+	s.mux.HandleFunc("/records", s.handleRecords)
+	s.mux.HandleFunc("/adjustendpoints", s.handleAdjustEndpoints)
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+
+	return s
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleNegotiate returns the domain filter, which external-dns uses to negotiate the protocol version.
+func (s *Server) handleNegotiate(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.provider.DomainFilter())
+}
+
+// handleRecords returns the current records on GET and applies changes on POST.
+func (s *Server) handleRecords(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		endpoints, err := s.provider.Records(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if endpoints == nil {
+			endpoints = []*Endpoint{}
+		}
+		writeJSON(w, http.StatusOK, endpoints)
+
+	case http.MethodPost:
+		changes := &Changes{}
+		if err := json.NewDecoder(r.Body).Decode(changes); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.provider.ApplyChanges(r.Context(), changes); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleAdjustEndpoints normalises the desired endpoints before external-dns plans its changes.
+func (s *Server) handleAdjustEndpoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	var endpoints []*Endpoint
+	if err := json.NewDecoder(r.Body).Decode(&endpoints); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.provider.AdjustEndpoints(endpoints))
+}
+
+// handleHealth reports that the server is up.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// writeJSON encodes the value using the webhook media type.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set(contentTypeHeader, MediaType)
+	w.Header().Set(varyHeader, contentTypeHeader)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the error message as a plain text response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	http.Error(w, err.Error(), status)
+}
+
+// methodNotAllowed writes a 405 response listing the allowed methods.
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	for _, method := range allowed {
+		w.Header().Add("Allow", method)
+	}
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
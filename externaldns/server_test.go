@@ -0,0 +1,143 @@
+package externaldns
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupWebhook(t *testing.T, filter DomainFilter) (*httptest.Server, *fakePorkbun) {
+	client, fake := setupFakePorkbun(t)
+
+	server := httptest.NewServer(NewServer(NewProvider(client.Dns, client.Domains, &ProviderOptions{DomainFilter: filter})))
+	t.Cleanup(server.Close)
+
+	return server, fake
+}
+
+func TestServer_Negotiate(t *testing.T) {
+	server, _ := setupWebhook(t, NewDomainFilter([]string{"example.com."}, []string{"internal.example.com"}))
+
+	resp, err := http.Get(server.URL + "/")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, MediaType, resp.Header.Get("Content-Type"))
+
+	var body map[string][]string
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, map[string][]string{
+		"include": {"example.com"},
+		"exclude": {"internal.example.com"},
+	}, body)
+}
+
+func TestServer_GetRecords(t *testing.T) {
+	server, _ := setupWebhook(t, NewDomainFilter([]string{"example.org"}, nil))
+
+	resp, err := http.Get(server.URL + "/records")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, MediaType, resp.Header.Get("Content-Type"))
+
+	var endpoints []*Endpoint
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&endpoints))
+	assert.Equal(t, []*Endpoint{
+		{DNSName: "api.example.org", RecordType: "A", RecordTTL: 3600, Targets: []string{"5.6.7.8", "5.6.7.9"}},
+	}, endpoints)
+}
+
+func TestServer_GetRecordsEmpty(t *testing.T) {
+	server, _ := setupWebhook(t, NewDomainFilter([]string{"example.net"}, nil))
+
+	resp, err := http.Get(server.URL + "/records")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body []interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.NotNil(t, body)
+	assert.Empty(t, body)
+}
+
+func TestServer_ApplyChanges(t *testing.T) {
+	server, fake := setupWebhook(t, DomainFilter{})
+
+	payload := `{"Create":[{"dnsName":"new.example.com","targets":["1.1.1.1"],"recordType":"A","recordTTL":300}],"UpdateOld":null,"UpdateNew":null,"Delete":null}`
+	resp, err := http.Post(server.URL+"/records", MediaType, strings.NewReader(payload))
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	records := fake.find("example.com", "new.example.com", "A")
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "1.1.1.1", records[0].Content)
+		assert.Equal(t, "600", records[0].TTL)
+	}
+}
+
+func TestServer_ApplyChangesErrors(t *testing.T) {
+	server, _ := setupWebhook(t, DomainFilter{})
+
+	resp, err := http.Post(server.URL+"/records", MediaType, strings.NewReader("not json"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	payload := `{"Create":[{"dnsName":"www.example.net","targets":["1.1.1.1"],"recordType":"A"}]}`
+	resp, err = http.Post(server.URL+"/records", MediaType, strings.NewReader(payload))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestServer_AdjustEndpoints(t *testing.T) {
+	server, _ := setupWebhook(t, DomainFilter{})
+
+	payload := `[{"dnsName":"WWW.example.com.","targets":["Target.example.com."],"recordType":"CNAME","recordTTL":1}]`
+	resp, err := http.Post(server.URL+"/adjustendpoints", MediaType, strings.NewReader(payload))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var endpoints []*Endpoint
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&endpoints))
+	assert.Equal(t, []*Endpoint{
+		{DNSName: "www.example.com", RecordType: "CNAME", RecordTTL: MinTTL, Targets: []string{"target.example.com"}},
+	}, endpoints)
+}
+
+func TestServer_MethodNotAllowed(t *testing.T) {
+	server, _ := setupWebhook(t, DomainFilter{})
+
+	for _, path := range []string{"/", "/records", "/adjustendpoints"} {
+		req, _ := http.NewRequest(http.MethodDelete, server.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode, path)
+	}
+}
+
+func TestServer_Healthz(t *testing.T) {
+	server, _ := setupWebhook(t, DomainFilter{})
+
+	resp, err := http.Get(server.URL + "/healthz")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/unknown")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,12 @@
+// Package fixtures embeds raw HTTP responses captured from the Porkbun API, for use with the
+// fixture loader and replay client in the porkbuntest package.
+//
+// File names are relative to this directory, e.g. "dns/retrieveByDomain/success.http".
+package fixtures
+
+import "embed"
+
+// FS holds the captured .http responses.
+//
+//go:embed *.http dns domains ping pricing ssl
+var FS embed.FS
@@ -1,14 +1,24 @@
 module github.com/tuzzmaniandevil/porkbun-go
 
-
 go 1.21.0
 
-require github.com/stretchr/testify v1.9.0
+require (
+	github.com/miekg/dns v1.1.62
+	github.com/stretchr/testify v1.9.0
+	software.sslmate.com/src/go-pkcs12 v0.5.0
+)
+
+require (
+	golang.org/x/crypto v0.26.0 // indirect
+	golang.org/x/mod v0.18.0 // indirect
+	golang.org/x/sync v0.7.0 // indirect
+	golang.org/x/sys v0.23.0 // indirect
+	golang.org/x/tools v0.22.0 // indirect
+)
 
 require (
-	golang.org/x/text v0.17.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/net v0.28.0
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,116 @@
+// Package testcert generates certificate bundles shaped like the ones returned by
+// SslService.Retrieve, for use in tests.
+package testcert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+)
+
+// Bundle is a generated certificate bundle in PEM format.
+type Bundle struct {
+	CertificateChain string            // The leaf followed by an intermediate certificate
+	PrivateKey       string            // The PKCS #8 private key of the leaf
+	PublicKey        string            // The PKIX public key of the leaf
+	Leaf             *x509.Certificate // The parsed leaf certificate
+	Root             *x509.Certificate // The self-signed root that issued the intermediate
+}
+
+// Generate creates a bundle for the names, valid between notBefore and notAfter.
+func Generate(names []string, notBefore, notAfter time.Time) (*Bundle, error) {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	root, err := sign(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             notBefore.Add(-time.Hour),
+		NotAfter:              notAfter.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, rootKey.Public(), rootKey)
+	if err != nil {
+		return nil, err
+	}
+
+	intermediateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	intermediate, err := sign(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test Intermediate CA"},
+		NotBefore:             notBefore.Add(-time.Hour),
+		NotAfter:              notAfter.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, root, intermediateKey.Public(), rootKey)
+	if err != nil {
+		return nil, err
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := sign(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: names[0]},
+		DNSNames:    names,
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, intermediate, leafKey.Public(), intermediateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(leafKey)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(leafKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bundle{
+		CertificateChain: encode("CERTIFICATE", leaf.Raw) + "\n" + encode("CERTIFICATE", intermediate.Raw),
+		PrivateKey:       encode("PRIVATE KEY", keyDER),
+		PublicKey:        encode("PUBLIC KEY", publicDER),
+		Leaf:             leaf,
+		Root:             root,
+	}, nil
+}
+
+// sign creates a certificate from the template, self-signed if parent is nil.
+func sign(template, parent *x509.Certificate, public crypto.PublicKey, signer crypto.Signer) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber = serial
+
+	if parent == nil {
+		parent = template
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, public, signer)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// encode returns the PEM encoding of the DER bytes.
+func encode(blockType string, der []byte) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+}
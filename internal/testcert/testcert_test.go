@@ -0,0 +1,33 @@
+package testcert
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	bundle, err := Generate([]string{"example.com", "*.example.com"}, now, now.Add(24*time.Hour))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"example.com", "*.example.com"}, bundle.Leaf.DNSNames)
+	assert.True(t, bundle.Leaf.NotAfter.Equal(now.Add(24*time.Hour)))
+	assert.Contains(t, bundle.PrivateKey, "BEGIN PRIVATE KEY")
+	assert.Contains(t, bundle.PublicKey, "BEGIN PUBLIC KEY")
+
+	roots := x509.NewCertPool()
+	roots.AddCert(bundle.Root)
+	intermediates := x509.NewCertPool()
+	assert.True(t, intermediates.AppendCertsFromPEM([]byte(bundle.CertificateChain)))
+
+	_, err = bundle.Leaf.Verify(x509.VerifyOptions{
+		DNSName:       "www.example.com",
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now.Add(time.Hour),
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,421 @@
+// Package notify posts webhook notifications when the configuration of a Porkbun account changes
+// or when its domains are about to expire, for chat tools such as Slack or Microsoft Teams or for
+// any HTTP endpoint.
+//
+//	notifier, err := notify.NewNotifier(client.Domains, &notify.Options{
+//		Targets:    []notify.Target{{URL: "https://hooks.example.com/porkbun", Secret: secret}},
+//		ExpiryDays: 30,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	err = notifier.Run(ctx, watch.NewWatcher(client.Dns, client.Domains, nil), nil)
+//
+// By default the body is the JSON encoding of a Message. A target can instead render its body from
+// a text/template executed with the Message, for example {"text": {{json .Text}}} for Slack.
+//
+// Requests to targets with a secret are signed: the X-Porkbun-Timestamp header holds the Unix time
+// of the request and X-Porkbun-Signature is "sha256=" followed by the hex HMAC-SHA256 of the
+// timestamp, a dot and the body. Receivers check it with Verify.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/watch"
+)
+
+const (
+	// DefaultRetries is the default number of retries of a failed request.
+	DefaultRetries = 3
+	// DefaultMinBackoff is the default wait before the first retry.
+	DefaultMinBackoff = time.Second
+	// DefaultMaxBackoff is the default upper limit of the wait between retries.
+	DefaultMaxBackoff = 30 * time.Second
+	// DefaultExpiryInterval is the default time between two expiry checks.
+	DefaultExpiryInterval = 24 * time.Hour
+)
+
+// queueSize is the number of change notifications Run holds while a previous one is being sent.
+const queueSize = 100
+
+// Headers of signed requests.
+const (
+	SignatureHeader = "X-Porkbun-Signature" // "sha256=" and the hex HMAC-SHA256 of the timestamp, a dot and the body
+	TimestampHeader = "X-Porkbun-Timestamp" // Unix time of the request
+)
+
+// MessageType is the reason of a notification.
+type MessageType string
+
+// Constants representing the message types.
+const (
+	TypeChange MessageType = "change" // The configuration of a domain changed
+	TypeExpiry MessageType = "expiry" // A domain expires soon
+)
+
+// Message is the content of a notification.
+type Message struct {
+	Type   MessageType  `json:"type"`             // Reason of the notification.
+	Time   time.Time    `json:"time"`             // Time the change or the expiry was detected.
+	Domain string       `json:"domain"`           // Domain name.
+	Text   string       `json:"text"`             // Human readable summary.
+	Event  *watch.Event `json:"event,omitempty"`  // The change, for TypeChange.
+	Expiry *Expiry      `json:"expiry,omitempty"` // The expiry, for TypeExpiry.
+}
+
+// Expiry describes a domain about to expire.
+type Expiry struct {
+	ExpireDate time.Time `json:"expire_date"` // Date the domain expires.
+	DaysLeft   int       `json:"days_left"`   // Whole days until the expiry, negative once expired.
+	AutoRenew  bool      `json:"auto_renew"`  // Whether the domain renews automatically.
+}
+
+// ChangeMessage returns the message notifying a change.
+func ChangeMessage(e watch.Event) *Message {
+	return &Message{Type: TypeChange, Time: e.Time, Domain: e.Domain, Text: e.String(), Event: &e}
+}
+
+// ExpiryMessage returns the message notifying that the domain expires soon, as of now.
+func ExpiryMessage(d porkbun.Domain, now time.Time) *Message {
+	expiry := &Expiry{
+		ExpireDate: d.ExpireDate,
+		DaysLeft:   int(math.Floor(d.ExpireDate.Sub(now).Hours() / 24)),
+		AutoRenew:  bool(d.AutoRenew),
+	}
+
+	renewal := "auto-renew is off"
+	if expiry.AutoRenew {
+		renewal = "auto-renew is on"
+	}
+	text := fmt.Sprintf("%s expires in %d days on %s, %s", d.Domain, expiry.DaysLeft, d.ExpireDate.Format(time.DateOnly), renewal)
+	if expiry.DaysLeft < 0 {
+		text = fmt.Sprintf("%s expired on %s, %s", d.Domain, d.ExpireDate.Format(time.DateOnly), renewal)
+	}
+
+	return &Message{Type: TypeExpiry, Time: now, Domain: d.Domain, Text: text, Expiry: expiry}
+}
+
+// Target is an endpoint receiving notifications.
+type Target struct {
+	URL      string            // URL the messages are posted to.
+	Secret   string            // Key signing the requests, unsigned if empty.
+	Template string            // text/template of the body, executed with the *Message; its JSON encoding if empty.
+	Headers  map[string]string // Additional request headers, e.g. Content-Type for a non-JSON template.
+}
+
+// Options defines the configuration options for the Notifier.
+type Options struct {
+	Targets        []Target      // Endpoints receiving every notification.
+	HTTPClient     *http.Client  // Client sending the requests, defaults to one with a 10 second timeout.
+	Retries        int           // Retries of a failed request, defaults to DefaultRetries; use -1 for none.
+	MinBackoff     time.Duration // Wait before the first retry, defaults to DefaultMinBackoff.
+	MaxBackoff     time.Duration // Upper limit of the wait between retries, defaults to DefaultMaxBackoff.
+	ExpiryDays     int           // Notify domains expiring within this many days, expiry checks are disabled if zero.
+	ExpiryInterval time.Duration // Time between expiry checks in Run, defaults to DefaultExpiryInterval.
+	Logger         *log.Logger   // Logger for failed notifications in Run, discarded if nil.
+}
+
+// Notifier sends notifications to the targets. It is safe for concurrent use.
+type Notifier struct {
+	domains   porkbun.DomainsAPI
+	options   Options
+	templates []*template.Template // Parsed Target.Template by target, nil if unset
+
+	// now and after are replaced in tests.
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// templateFuncs are the functions available to body templates.
+var templateFuncs = template.FuncMap{
+	// json encodes a value, e.g. a string with its quotes and escapes
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// NewNotifier initializes a new Notifier using the provided domains API, usually client.Domains,
+// which is only used for the expiry checks, and options. It returns an error if a target is invalid.
+func NewNotifier(domains porkbun.DomainsAPI, options *Options) (*Notifier, error) {
+	n := &Notifier{
+		domains: domains,
+		now:     time.Now,
+		after:   time.After,
+	}
+
+	if options != nil {
+		n.options = *options
+	}
+	if n.options.HTTPClient == nil {
+		n.options.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if n.options.Retries == 0 {
+		n.options.Retries = DefaultRetries
+	}
+	if n.options.MinBackoff <= 0 {
+		n.options.MinBackoff = DefaultMinBackoff
+	}
+	if n.options.MaxBackoff < n.options.MinBackoff {
+		n.options.MaxBackoff = DefaultMaxBackoff
+		if n.options.MaxBackoff < n.options.MinBackoff {
+			n.options.MaxBackoff = n.options.MinBackoff
+		}
+	}
+	if n.options.ExpiryInterval <= 0 {
+		n.options.ExpiryInterval = DefaultExpiryInterval
+	}
+	if n.options.Logger == nil {
+		n.options.Logger = log.New(io.Discard, "", 0)
+	}
+
+	n.templates = make([]*template.Template, len(n.options.Targets))
+	for i, target := range n.options.Targets {
+		if !strings.HasPrefix(target.URL, "http://") && !strings.HasPrefix(target.URL, "https://") {
+			return nil, fmt.Errorf("notify: invalid target URL %q", target.URL)
+		}
+		if target.Template == "" {
+			continue
+		}
+		tmpl, err := template.New(target.URL).Funcs(templateFuncs).Option("missingkey=error").Parse(target.Template)
+		if err != nil {
+			return nil, fmt.Errorf("notify: template of %s: %w", target.URL, err)
+		}
+		n.templates[i] = tmpl
+	}
+
+	return n, nil
+}
+
+// Send posts the message to every target, retrying failed requests. It returns the errors of the
+// targets that could not be notified.
+func (n *Notifier) Send(ctx context.Context, msg *Message) error {
+	var errs []error
+	for i, target := range n.options.Targets {
+		body, err := n.render(i, msg)
+		if err == nil {
+			err = n.post(ctx, target, body)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notify %s: %w", target.URL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// render returns the body of the message for the target with the index.
+func (n *Notifier) render(i int, msg *Message) ([]byte, error) {
+	if n.templates[i] == nil {
+		return json.Marshal(msg)
+	}
+
+	var buf bytes.Buffer
+	if err := n.templates[i].Execute(&buf, msg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// post sends the body to the target, retrying transport errors, 429 and 5xx responses with an
+// exponential backoff.
+func (n *Notifier) post(ctx context.Context, target Target, body []byte) error {
+	for attempt := 0; ; attempt++ {
+		retry, wait, err := n.postOnce(ctx, target, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.options.Retries || ctx.Err() != nil {
+			return err
+		}
+
+		// Retry-After is honoured up to MaxBackoff
+		if wait <= 0 {
+			wait = n.backoff(attempt)
+		} else if wait > n.options.MaxBackoff {
+			wait = n.options.MaxBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-n.after(wait):
+		}
+	}
+}
+
+// postOnce sends a single request. It reports whether a failure may be retried, and after how
+// long if the target said so with Retry-After.
+func (n *Notifier) postOnce(ctx context.Context, target Target, body []byte) (bool, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "porkbun-go-notify")
+	for key, value := range target.Headers {
+		req.Header.Set(key, value)
+	}
+	if target.Secret != "" {
+		timestamp := strconv.FormatInt(n.now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(target.Secret, timestamp, body))
+	}
+
+	resp, err := n.options.HTTPClient.Do(req)
+	if err != nil {
+		return true, 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, 0, nil
+	}
+
+	err = fmt.Errorf("unexpected status %s", resp.Status)
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	var wait time.Duration
+	if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+		wait = time.Duration(seconds) * time.Second
+	}
+	return retry, wait, err
+}
+
+// backoff returns the wait after the given number of consecutive previous failures.
+func (n *Notifier) backoff(failures int) time.Duration {
+	wait := n.options.MinBackoff
+	for i := 0; i < failures; i++ {
+		wait *= 2
+		if wait >= n.options.MaxBackoff {
+			return n.options.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// Sign returns the signature of a request body sent at the Unix timestamp, as set in the
+// X-Porkbun-Signature header.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature and timestamp headers of a received request match its body
+// and whether the timestamp is within maxAge of now, to reject replayed requests.
+func Verify(secret, timestamp, signature string, body []byte, maxAge time.Duration) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > maxAge || age < -maxAge {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// NotifyExpiring sends a message for every domain expiring within ExpiryDays, and returns how
+// many domains were notified.
+func (n *Notifier) NotifyExpiring(ctx context.Context) (int, error) {
+	now := n.now()
+	domains, err := n.domains.ListAllDomains(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("listing domains: %w", err)
+	}
+
+	var expiring []porkbun.Domain
+	for _, d := range domains {
+		if !d.ExpireDate.IsZero() && d.ExpireDate.Before(now.AddDate(0, 0, n.options.ExpiryDays)) {
+			expiring = append(expiring, d)
+		}
+	}
+
+	var errs []error
+	for _, d := range expiring {
+		if err := n.Send(ctx, ExpiryMessage(d, now)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(expiring), errors.Join(errs...)
+}
+
+// RunExpiry calls NotifyExpiring every ExpiryInterval until the context is cancelled. Failed
+// checks are logged.
+func (n *Notifier) RunExpiry(ctx context.Context) error {
+	for {
+		if _, err := n.NotifyExpiring(ctx); err != nil && ctx.Err() == nil {
+			n.options.Logger.Printf("expiry check failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-n.after(n.options.ExpiryInterval):
+		}
+	}
+}
+
+// Run notifies the events of the watcher and, if ExpiryDays is set, runs the expiry checks, until
+// the context is cancelled. The handler, if not nil, is called with every event before it is
+// notified. Failed notifications are logged.
+//
+// Notifications are sent in order by a separate goroutine, so retries to a slow target do not delay
+// the polls of the watcher, unless more than queueSize of them are pending. Notifications still
+// pending when the context is cancelled are dropped.
+func (n *Notifier) Run(ctx context.Context, watcher *watch.Watcher, handler func(watch.Event)) error {
+	var wg sync.WaitGroup
+	if n.options.ExpiryDays > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = n.RunExpiry(ctx)
+		}()
+	}
+
+	queue := make(chan *Message, queueSize)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-queue:
+				if err := n.Send(ctx, msg); err != nil && ctx.Err() == nil {
+					n.options.Logger.Print(err)
+				}
+			}
+		}
+	}()
+
+	err := watcher.Run(ctx, func(e watch.Event) {
+		if handler != nil {
+			handler(e)
+		}
+		select {
+		case queue <- ChangeMessage(e):
+		case <-ctx.Done():
+		}
+	})
+	wg.Wait()
+	return err
+}
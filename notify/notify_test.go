@@ -0,0 +1,272 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+	"github.com/tuzzmaniandevil/porkbun-go/snapshot"
+	"github.com/tuzzmaniandevil/porkbun-go/watch"
+)
+
+// request is a request received by a receiver.
+type request struct {
+	header http.Header
+	body   string
+}
+
+// receiver is a webhook endpoint answering with the queued status codes, then 200.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []request
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, request{header: req.Header, body: string(body)})
+		if len(r.statuses) > 0 {
+			status := r.statuses[0]
+			r.statuses = r.statuses[1:]
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "120")
+			}
+			w.WriteHeader(status)
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// received returns the requests received so far.
+func (r *receiver) received() []request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]request(nil), r.requests...)
+}
+
+// instantAfter records the waits and returns at once.
+func instantAfter(waits *[]time.Duration) func(time.Duration) <-chan time.Time {
+	return func(d time.Duration) <-chan time.Time {
+		*waits = append(*waits, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+}
+
+func testEvent() watch.Event {
+	return watch.Event{
+		Type:   watch.RecordAdded,
+		Domain: "example.com",
+		Time:   time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		New:    &snapshot.Record{Name: "www", Type: porkbun.A, Content: "192.0.2.1"},
+	}
+}
+
+func TestNotifier_Send(t *testing.T) {
+	plain := newReceiver(t)
+	slack := newReceiver(t)
+
+	n, err := NewNotifier(nil, &Options{Targets: []Target{
+		{URL: plain.URL, Secret: "s3cret"},
+		{URL: slack.URL, Template: `{"text": {{json .Text}}}`, Headers: map[string]string{"X-Team": "ops"}},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, n.Send(context.Background(), ChangeMessage(testEvent())))
+
+	if requests := plain.received(); assert.Len(t, requests, 1) {
+		r := requests[0]
+		assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+		assert.True(t, Verify("s3cret", r.header.Get(TimestampHeader), r.header.Get(SignatureHeader), []byte(r.body), time.Minute))
+		assert.False(t, Verify("wrong", r.header.Get(TimestampHeader), r.header.Get(SignatureHeader), []byte(r.body), time.Minute))
+
+		var msg struct {
+			Type   string `json:"type"`
+			Domain string `json:"domain"`
+			Text   string `json:"text"`
+			Event  struct {
+				Type string `json:"type"`
+			} `json:"event"`
+		}
+		if assert.NoError(t, json.Unmarshal([]byte(r.body), &msg)) {
+			assert.Equal(t, "change", msg.Type)
+			assert.Equal(t, "example.com", msg.Domain)
+			assert.Equal(t, `+ example.com record www A "192.0.2.1"`, msg.Text)
+			assert.Equal(t, "record_added", msg.Event.Type)
+		}
+	}
+
+	if requests := slack.received(); assert.Len(t, requests, 1) {
+		assert.Equal(t, `{"text": "+ example.com record www A \"192.0.2.1\""}`, requests[0].body)
+		assert.Equal(t, "ops", requests[0].header.Get("X-Team"))
+		assert.Empty(t, requests[0].header.Get(SignatureHeader))
+	}
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	r := newReceiver(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway)
+	n, err := NewNotifier(nil, &Options{
+		Targets:    []Target{{URL: r.URL}},
+		MinBackoff: time.Second,
+		MaxBackoff: time.Minute,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	var waits []time.Duration
+	n.after = instantAfter(&waits)
+
+	// Three retries, the second one after the Retry-After capped to MaxBackoff, are not enough
+	err = n.Send(context.Background(), ChangeMessage(testEvent()))
+	assert.ErrorContains(t, err, "502 Bad Gateway")
+	assert.Equal(t, []time.Duration{time.Second, time.Minute, 4 * time.Second}, waits)
+	assert.Len(t, r.received(), 4)
+
+	waits = nil
+	assert.NoError(t, n.Send(context.Background(), ChangeMessage(testEvent())))
+	assert.Empty(t, waits)
+
+	// Client errors are not retried
+	r = newReceiver(t, http.StatusBadRequest)
+	n, _ = NewNotifier(nil, &Options{Targets: []Target{{URL: r.URL}}})
+	n.after = instantAfter(&waits)
+	assert.ErrorContains(t, n.Send(context.Background(), ChangeMessage(testEvent())), "400 Bad Request")
+	assert.Len(t, r.received(), 1)
+	assert.Empty(t, waits)
+
+	r = newReceiver(t, http.StatusServiceUnavailable)
+	n, _ = NewNotifier(nil, &Options{Targets: []Target{{URL: r.URL}}, Retries: -1})
+	assert.Error(t, n.Send(context.Background(), ChangeMessage(testEvent())))
+	assert.Len(t, r.received(), 1)
+}
+
+func TestNewNotifier_Errors(t *testing.T) {
+	_, err := NewNotifier(nil, &Options{Targets: []Target{{URL: "ftp://example.com"}}})
+	assert.ErrorContains(t, err, "invalid target URL")
+
+	_, err = NewNotifier(nil, &Options{Targets: []Target{{URL: "https://example.com", Template: "{{.Text"}}})
+	assert.ErrorContains(t, err, "template of https://example.com")
+
+	// Templates referencing missing fields fail when sent
+	r := newReceiver(t)
+	n, err := NewNotifier(nil, &Options{Targets: []Target{{URL: r.URL, Template: "{{.Nope}}"}}})
+	assert.NoError(t, err)
+	assert.ErrorContains(t, n.Send(context.Background(), ChangeMessage(testEvent())), "Nope")
+	assert.Empty(t, r.received())
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"change"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := Sign("key", now, body)
+
+	assert.True(t, Verify("key", now, signature, body, time.Minute))
+	assert.False(t, Verify("key", now, signature, []byte(`{"type":"expiry"}`), time.Minute))
+	assert.False(t, Verify("key", "bogus", signature, body, time.Minute))
+
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	assert.False(t, Verify("key", old, Sign("key", old, body), body, time.Minute))
+}
+
+func TestExpiryMessage(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	msg := ExpiryMessage(porkbun.Domain{Domain: "example.com", ExpireDate: now.Add(12*24*time.Hour + time.Hour), AutoRenew: true}, now)
+	assert.Equal(t, TypeExpiry, msg.Type)
+	assert.Equal(t, 12, msg.Expiry.DaysLeft)
+	assert.Equal(t, "example.com expires in 12 days on 2026-10-30, auto-renew is on", msg.Text)
+
+	msg = ExpiryMessage(porkbun.Domain{Domain: "example.com", ExpireDate: now.Add(-time.Hour)}, now)
+	assert.Equal(t, -1, msg.Expiry.DaysLeft)
+	assert.Equal(t, "example.com expired on 2026-10-18, auto-renew is off", msg.Text)
+}
+
+func TestNotifier_NotifyExpiring(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	domains := porkbuntest.NewFakeDomains(
+		porkbun.Domain{Domain: "soon.com", ExpireDate: now.AddDate(0, 0, 10)},
+		porkbun.Domain{Domain: "later.com", ExpireDate: now.AddDate(0, 0, 60)},
+	)
+
+	r := newReceiver(t)
+	n, err := NewNotifier(domains, &Options{Targets: []Target{{URL: r.URL, Template: "{{.Domain}} {{.Expiry.DaysLeft}}"}}, ExpiryDays: 30})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	count, err := n.NotifyExpiring(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	if requests := r.received(); assert.Len(t, requests, 1) {
+		assert.Regexp(t, `^soon\.com (9|10)$`, requests[0].body)
+	}
+}
+
+func TestNotifier_Run(t *testing.T) {
+	server := porkbuntest.NewServer(nil)
+	t.Cleanup(server.Close)
+	server.AddDomain(porkbun.Domain{Domain: "example.com", ExpireDate: time.Now().AddDate(0, 0, 5)})
+
+	r := newReceiver(t)
+	client := server.Client()
+	n, err := NewNotifier(client.Domains, &Options{Targets: []Target{{URL: r.URL}}, ExpiryDays: 7, ExpiryInterval: time.Hour})
+	if !assert.NoError(t, err) {
+		return
+	}
+	expiryChecked := make(chan struct{}, 1)
+	n.after = func(d time.Duration) <-chan time.Time {
+		select {
+		case expiryChecked <- struct{}{}:
+		default:
+		}
+		return make(chan time.Time) // Never checks again
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := watch.NewWatcher(client.Dns, client.Domains, &watch.Options{Interval: 10 * time.Millisecond})
+	done := make(chan error)
+	var handled atomic.Int32
+	go func() { done <- n.Run(ctx, w, func(watch.Event) { handled.Add(1) }) }()
+
+	<-expiryChecked
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; len(r.received()) < 2 && time.Now().Before(deadline); i++ {
+		server.AddRecord("example.com", porkbun.DnsRecord{Name: "r" + strconv.Itoa(i), Type: porkbun.A, Content: "192.0.2.1"})
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	assert.True(t, errors.Is(<-done, context.Canceled))
+	assert.Greater(t, int(handled.Load()), 0)
+
+	var types []string
+	for _, req := range r.received() {
+		var msg Message
+		if assert.NoError(t, json.Unmarshal([]byte(req.body), &msg)) {
+			types = append(types, string(msg.Type))
+		}
+	}
+	if assert.GreaterOrEqual(t, len(types), 2) {
+		assert.Equal(t, []string{"expiry", "change"}, types[:2])
+	}
+}
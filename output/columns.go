@@ -0,0 +1,179 @@
+package output
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+var domainColumns = []column[porkbun.Domain]{
+	{name: "domain", header: "DOMAIN", value: func(d porkbun.Domain) any { return d.Domain }},
+	{name: "status", header: "STATUS", value: func(d porkbun.Domain) any { return d.Status }},
+	{name: "tld", header: "TLD", value: func(d porkbun.Domain) any { return d.TLD }, extra: true},
+	{name: "created", header: "CREATED", value: func(d porkbun.Domain) any { return d.CreateDate }, extra: true},
+	{name: "expires", header: "EXPIRES", value: func(d porkbun.Domain) any { return d.ExpireDate }},
+	{name: "auto_renew", header: "AUTO-RENEW", value: func(d porkbun.Domain) any { return bool(d.AutoRenew) }},
+	{name: "locked", header: "LOCKED", value: func(d porkbun.Domain) any { return bool(d.SecurityLock) }},
+	{name: "privacy", header: "PRIVACY", value: func(d porkbun.Domain) any { return bool(d.WhoisPrivacy) }},
+	{name: "not_local", header: "NOT-LOCAL", value: func(d porkbun.Domain) any { return bool(d.NotLocal) }, extra: true},
+	{name: "labels", header: "LABELS", value: func(d porkbun.Domain) any {
+		titles := make([]string, 0, len(d.Labels))
+		for _, label := range d.Labels {
+			titles = append(titles, label.Title)
+		}
+		sort.Strings(titles)
+		return titles
+	}, extra: true},
+}
+
+var forwardColumns = []column[porkbun.UrlForwardData]{
+	{name: "id", header: "ID", value: func(f porkbun.UrlForwardData) any { return f.Id }},
+	{name: "subdomain", header: "SUBDOMAIN", value: func(f porkbun.UrlForwardData) any { return f.Subdomain }},
+	{name: "location", header: "LOCATION", value: func(f porkbun.UrlForwardData) any { return f.Location }},
+	{name: "type", header: "TYPE", value: func(f porkbun.UrlForwardData) any { return string(f.Type) }},
+	{name: "include_path", header: "INCLUDE-PATH", value: func(f porkbun.UrlForwardData) any { return f.IncludePath }},
+	{name: "wildcard", header: "WILDCARD", value: func(f porkbun.UrlForwardData) any { return f.Wildcard }},
+}
+
+var nameServerColumns = []column[string]{
+	{name: "nameserver", header: "NAMESERVER", value: func(ns string) any { return ns }},
+}
+
+// tldPricing is a pricing table entry with its TLD.
+type tldPricing struct {
+	tld string
+	porkbun.Pricing
+}
+
+var pricingColumns = []column[tldPricing]{
+	{name: "tld", header: "TLD", value: func(p tldPricing) any { return p.tld }},
+	{name: "registration", header: "REGISTRATION", value: func(p tldPricing) any { return p.Registration }},
+	{name: "renewal", header: "RENEWAL", value: func(p tldPricing) any { return p.Renewal }},
+	{name: "transfer", header: "TRANSFER", value: func(p tldPricing) any { return p.Transfer }},
+	{name: "special_type", header: "SPECIAL-TYPE", value: func(p tldPricing) any {
+		if p.SpecialType == nil {
+			return ""
+		}
+		return *p.SpecialType
+	}, extra: true},
+	{name: "coupons", header: "COUPONS", value: func(p tldPricing) any {
+		codes := make([]string, 0, len(p.Coupons))
+		for code := range p.Coupons {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		return codes
+	}, extra: true},
+}
+
+// recordColumns returns the DNS record columns, with names relative to domain if it is not empty.
+func recordColumns(domain string) []column[porkbun.DnsRecord] {
+	return []column[porkbun.DnsRecord]{
+		{name: "id", header: "ID", value: func(r porkbun.DnsRecord) any { return r.ID }},
+		{name: "name", header: "NAME", value: func(r porkbun.DnsRecord) any { return recordName(r.Name, domain) }},
+		{name: "type", header: "TYPE", value: func(r porkbun.DnsRecord) any { return string(r.Type) }},
+		{name: "content", header: "CONTENT", value: func(r porkbun.DnsRecord) any { return r.Content }},
+		{name: "ttl", header: "TTL", value: func(r porkbun.DnsRecord) any { return r.TTL }},
+		{name: "prio", header: "PRIO", value: func(r porkbun.DnsRecord) any { return r.Prio }},
+		{name: "notes", header: "NOTES", value: func(r porkbun.DnsRecord) any { return r.Notes }},
+	}
+}
+
+// DomainColumns returns the names of the columns available for domains.
+func DomainColumns() []string { return columnNames(domainColumns) }
+
+// RecordColumns returns the names of the columns available for DNS records.
+func RecordColumns() []string { return columnNames(recordColumns("")) }
+
+// ForwardColumns returns the names of the columns available for URL forwards.
+func ForwardColumns() []string { return columnNames(forwardColumns) }
+
+// NameServerColumns returns the names of the columns available for name servers.
+func NameServerColumns() []string { return columnNames(nameServerColumns) }
+
+// PricingColumns returns the names of the columns available for pricing.
+func PricingColumns() []string { return columnNames(pricingColumns) }
+
+// Domains writes the domains sorted by name.
+func Domains(w io.Writer, domains []porkbun.Domain, options *Options) error {
+	sorted := append([]porkbun.Domain(nil), domains...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].Domain) < strings.ToLower(sorted[j].Domain)
+	})
+	return write(w, sorted, domainColumns, options)
+}
+
+// Records writes the DNS records sorted by name, type, content and ID.
+func Records(w io.Writer, records []porkbun.DnsRecord, options *Options) error {
+	domain := ""
+	if options != nil {
+		domain = options.Domain
+	}
+
+	sorted := append([]porkbun.DnsRecord(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if an, bn := recordName(a.Name, domain), recordName(b.Name, domain); an != bn {
+			return an < bn
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Content != b.Content {
+			return a.Content < b.Content
+		}
+		return a.ID != nil && (b.ID == nil || *a.ID < *b.ID)
+	})
+	return write(w, sorted, recordColumns(domain), options)
+}
+
+// Forwards writes the URL forwards sorted by subdomain, location and ID.
+func Forwards(w io.Writer, forwards []porkbun.UrlForwardData, options *Options) error {
+	sorted := append([]porkbun.UrlForwardData(nil), forwards...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Subdomain != b.Subdomain {
+			return a.Subdomain < b.Subdomain
+		}
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		return a.Id < b.Id
+	})
+	return write(w, sorted, forwardColumns, options)
+}
+
+// NameServers writes the name servers sorted by name.
+func NameServers(w io.Writer, ns porkbun.NameServers, options *Options) error {
+	sorted := make([]string, len(ns))
+	for i, name := range ns {
+		sorted[i] = porkbun.NormalizeName(name)
+	}
+	sort.Strings(sorted)
+	return write(w, sorted, nameServerColumns, options)
+}
+
+// Pricing writes the pricing table sorted by TLD.
+func Pricing(w io.Writer, pricing map[string]porkbun.Pricing, options *Options) error {
+	items := make([]tldPricing, 0, len(pricing))
+	for tld, p := range pricing {
+		items = append(items, tldPricing{tld: tld, Pricing: p})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].tld < items[j].tld })
+	return write(w, items, pricingColumns, options)
+}
+
+// recordName returns the record name relative to domain with "@" for the apex, or the name
+// unchanged if domain is empty.
+func recordName(name, domain string) string {
+	if domain == "" {
+		return name
+	}
+
+	if name = porkbun.RelativeName(name, domain); name == "" {
+		return "@"
+	}
+	return name
+}
@@ -0,0 +1,104 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+func TestRecords(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	records := []porkbun.DnsRecord{
+		{ID: id(4), Name: "www.example.com", Type: porkbun.A, Content: "192.0.2.2", TTL: "600"},
+		{ID: id(3), Name: "www.example.com", Type: porkbun.A, Content: "192.0.2.1", TTL: "600"},
+		{ID: id(2), Name: "example.com", Type: porkbun.MX, Content: "mail.example.com", TTL: "600", Prio: "10"},
+		{ID: id(1), Name: "example.com", Type: porkbun.A, Content: "192.0.2.1", TTL: "600"},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Records(&buf, records, &Options{Format: FormatCSV, Domain: "Example.com", Columns: []string{"id", "name", "type", "content", "prio"}}))
+	assert.Equal(t, ""+
+		"ID,NAME,TYPE,CONTENT,PRIO\n"+
+		"1,@,A,192.0.2.1,\n"+
+		"2,@,MX,mail.example.com,10\n"+
+		"3,www,A,192.0.2.1,\n"+
+		"4,www,A,192.0.2.2,\n", buf.String())
+
+	// The input is left untouched
+	assert.Equal(t, int64(4), *records[0].ID)
+
+	// Without a domain names are shown as returned
+	buf.Reset()
+	assert.NoError(t, Records(&buf, records[:1], &Options{Format: FormatJSON, Columns: []string{"id", "name"}}))
+	assert.Equal(t, "[\n  {\n    \"id\": 4,\n    \"name\": \"www.example.com\"\n  }\n]\n", buf.String())
+}
+
+func TestForwards(t *testing.T) {
+	forwards := []porkbun.UrlForwardData{
+		{Id: "2", UrlForward: porkbun.UrlForward{Subdomain: "www", Location: "https://example.org", Type: porkbun.Permanent, IncludePath: "no", Wildcard: "yes"}},
+		{Id: "1", UrlForward: porkbun.UrlForward{Location: "https://example.net", Type: porkbun.Temporary, IncludePath: "yes", Wildcard: "no"}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Forwards(&buf, forwards, &Options{Format: FormatCSV}))
+	assert.Equal(t, ""+
+		"ID,SUBDOMAIN,LOCATION,TYPE,INCLUDE-PATH,WILDCARD\n"+
+		"1,,https://example.net,temporary,yes,no\n"+
+		"2,www,https://example.org,permanent,no,yes\n", buf.String())
+
+	// URLs are not HTML escaped
+	forwards[0].Location = "https://example.org/?a=1&b=<2>"
+	buf.Reset()
+	assert.NoError(t, Forwards(&buf, forwards[:1], &Options{Format: FormatJSON, Columns: []string{"location"}}))
+	assert.Contains(t, buf.String(), `"location": "https://example.org/?a=1&b=<2>"`)
+}
+
+func TestNameServers(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, NameServers(&buf, porkbun.NameServers{"NS2.example.net.", "ns1.example.net"}, &Options{Format: FormatYAML}))
+	assert.Equal(t, "- nameserver: ns1.example.net\n- nameserver: ns2.example.net\n", buf.String())
+}
+
+func TestPricing(t *testing.T) {
+	special := "handshake"
+	pricing := map[string]porkbun.Pricing{
+		"net": {Registration: "11.48", Renewal: "12.52", Transfer: "11.48"},
+		"com": {Registration: "9.68", Renewal: "10.37", Transfer: "9.68", Coupons: porkbun.Coupons{
+			"SAVE": {Code: "SAVE"}, "FIRST": {Code: "FIRST"},
+		}},
+		"xyz": {Registration: "2.04", Renewal: "12.98", Transfer: "12.98", SpecialType: &special},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Pricing(&buf, pricing, &Options{Format: FormatCSV}))
+	assert.Equal(t, ""+
+		"TLD,REGISTRATION,RENEWAL,TRANSFER\n"+
+		"com,9.68,10.37,9.68\n"+
+		"net,11.48,12.52,11.48\n"+
+		"xyz,2.04,12.98,12.98\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, Pricing(&buf, pricing, &Options{Format: FormatCSV, Columns: []string{"tld", "special_type", "coupons"}}))
+	assert.Equal(t, ""+
+		"TLD,SPECIAL-TYPE,COUPONS\n"+
+		"com,,\"FIRST,SAVE\"\n"+
+		"net,,\n"+
+		"xyz,handshake,\n", buf.String())
+}
+
+func TestColumnNames(t *testing.T) {
+	assert.Equal(t, []string{"id", "name", "type", "content", "ttl", "prio", "notes"}, RecordColumns())
+	assert.Contains(t, DomainColumns(), "labels")
+	assert.Contains(t, ForwardColumns(), "include_path")
+	assert.Equal(t, []string{"nameserver"}, NameServerColumns())
+	assert.Contains(t, PricingColumns(), "coupons")
+}
+
+func TestRecordName(t *testing.T) {
+	assert.Equal(t, "@", recordName("example.com.", "example.com"))
+	assert.Equal(t, "a.b", recordName("A.B.example.com", "example.com"))
+	assert.Equal(t, "other.org", recordName("other.org", "example.com"))
+	assert.Equal(t, "www.example.com", recordName("www.example.com", ""))
+}
@@ -0,0 +1,288 @@
+// Package output formats porkbun results as aligned tables, JSON, YAML or CSV.
+//
+// Each result type has a fixed set of named columns, of which a default subset is shown unless
+// Options.Columns selects others. Rows are always sorted, so the output of unchanged data is
+// byte-for-byte identical and diffs between runs are meaningful:
+//
+//	err := output.Records(os.Stdout, resp.Records, &output.Options{Format: output.FormatCSV, Domain: "example.com"})
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format.
+type Format string
+
+// Constants representing the supported formats.
+const (
+	FormatTable Format = "table" // Aligned columns with a header row, for humans
+	FormatJSON  Format = "json"  // An indented array of objects keyed by column name
+	FormatYAML  Format = "yaml"  // A sequence of mappings keyed by column name
+	FormatCSV   Format = "csv"   // Comma separated values with a header row
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{FormatTable, FormatJSON, FormatYAML, FormatCSV}
+
+// Errors returned for invalid options.
+var (
+	ErrUnknownFormat = errors.New("output: unknown format")
+	ErrUnknownColumn = errors.New("output: unknown column")
+)
+
+// ParseFormat returns the format with the name, in any case.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(name, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q, expected one of %s", ErrUnknownFormat, name, formatNames())
+}
+
+// formatNames returns the names of the supported formats for error messages.
+func formatNames() string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Options defines the configuration options for formatting results.
+type Options struct {
+	Format  Format   // Output format, defaults to FormatTable.
+	Columns []string // Columns to show in this order, defaults to the default columns of the result type.
+	Domain  string   // Domain of DNS records, whose names are then shown relative to it with "@" for the apex.
+}
+
+// column describes a named value of a result item.
+type column[T any] struct {
+	name   string      // Key in JSON and YAML, and name used to select the column
+	header string      // Heading in tables and CSV
+	value  func(T) any // Value of the column for an item
+	extra  bool        // Only shown when selected
+}
+
+// write formats the items, which must already be sorted, with the selected columns.
+func write[T any](w io.Writer, items []T, columns []column[T], options *Options) error {
+	var opts Options
+	if options != nil {
+		opts = *options
+	}
+	if opts.Format == "" {
+		opts.Format = FormatTable
+	}
+
+	selected, err := selectColumns(columns, opts.Columns)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(items))
+	for i, item := range items {
+		rows[i] = make([]any, len(selected))
+		for j, c := range selected {
+			rows[i][j] = c.value(item)
+		}
+	}
+
+	switch opts.Format {
+	case FormatTable:
+		return writeTable(w, selected, rows)
+	case FormatCSV:
+		return writeCSV(w, selected, rows)
+	case FormatJSON:
+		return writeJSON(w, selected, rows)
+	case FormatYAML:
+		return writeYAML(w, selected, rows)
+	}
+	return fmt.Errorf("%w %q, expected one of %s", ErrUnknownFormat, opts.Format, formatNames())
+}
+
+// selectColumns returns the named columns, or the default ones if names is empty.
+func selectColumns[T any](columns []column[T], names []string) ([]column[T], error) {
+	if len(names) == 0 {
+		var defaults []column[T]
+		for _, c := range columns {
+			if !c.extra {
+				defaults = append(defaults, c)
+			}
+		}
+		return defaults, nil
+	}
+
+	selected := make([]column[T], 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, c := range columns {
+			if c.name == name {
+				selected = append(selected, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w %q, expected one of %s", ErrUnknownColumn, name, strings.Join(columnNames(columns), ", "))
+		}
+	}
+	return selected, nil
+}
+
+// columnNames returns the names of all columns.
+func columnNames[T any](columns []column[T]) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// writeTable writes the rows as aligned columns.
+func writeTable[T any](w io.Writer, columns []column[T], rows [][]any) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	cells := make([]string, len(columns))
+	for i, c := range columns {
+		cells[i] = c.header
+	}
+	fmt.Fprintln(tw, strings.Join(cells, "\t"))
+
+	for _, row := range rows {
+		for i, v := range row {
+			// Tabs and newlines would break the alignment
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(text(v))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeCSV writes the rows as CSV with a header row.
+func writeCSV[T any](w io.Writer, columns []column[T], rows [][]any) error {
+	cw := csv.NewWriter(w)
+
+	cells := make([]string, len(columns))
+	for i, c := range columns {
+		cells[i] = c.header
+	}
+	if err := cw.Write(cells); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		for i, v := range row {
+			cells[i] = text(v)
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON writes the rows as an indented JSON array of objects, keys in column order.
+func writeJSON[T any](w io.Writer, columns []column[T], rows [][]any) error {
+	var buf strings.Builder
+	buf.WriteString("[")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		for j, v := range row {
+			if j > 0 {
+				buf.WriteString(",")
+			}
+			// Encode without HTML escaping, which would mangle URLs and TXT records
+			var value bytes.Buffer
+			encoder := json.NewEncoder(&value)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("    ", "  ")
+			if err := encoder.Encode(v); err != nil {
+				return err
+			}
+			fmt.Fprintf(&buf, "\n    %q: %s", columns[j].name, bytes.TrimSuffix(value.Bytes(), []byte("\n")))
+		}
+		buf.WriteString("\n  }")
+	}
+	if len(rows) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// writeYAML writes the rows as a YAML sequence of mappings, keys in column order.
+func writeYAML[T any](w io.Writer, columns []column[T], rows [][]any) error {
+	doc := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, row := range rows {
+		item := &yaml.Node{Kind: yaml.MappingNode}
+		for j, v := range row {
+			value := &yaml.Node{}
+			if err := value.Encode(v); err != nil {
+				return err
+			}
+			item.Content = append(item.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: columns[j].name}, value)
+		}
+		doc.Content = append(doc.Content, item)
+	}
+
+	if len(rows) == 0 {
+		doc.Style = yaml.FlowStyle
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// text formats a value for tables and CSV.
+func text(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case *int64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatInt(*v, 10)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.DateTime)
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return fmt.Sprint(v)
+}
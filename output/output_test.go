@@ -0,0 +1,127 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+func testDomains() []porkbun.Domain {
+	expires := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []porkbun.Domain{
+		{Domain: "example.org", Status: "ACTIVE", ExpireDate: expires, Labels: []porkbun.Label{{Title: "prod"}, {Title: "eu"}}},
+		{Domain: "example.com", Status: "ACTIVE", ExpireDate: expires, AutoRenew: true, SecurityLock: true},
+	}
+}
+
+func format(t *testing.T, f Format, columns ...string) string {
+	var buf bytes.Buffer
+	assert.NoError(t, Domains(&buf, testDomains(), &Options{Format: f, Columns: columns}))
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("JSON")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSON, f)
+
+	_, err = ParseFormat("xml")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	assert.Contains(t, err.Error(), "table, json, yaml, csv")
+}
+
+func TestWrite_Table(t *testing.T) {
+	assert.Equal(t, ""+
+		"DOMAIN       STATUS  EXPIRES              AUTO-RENEW  LOCKED  PRIVACY\n"+
+		"example.com  ACTIVE  2025-03-01 12:00:00  yes         yes     no\n"+
+		"example.org  ACTIVE  2025-03-01 12:00:00  no          no      no\n",
+		format(t, FormatTable))
+
+	// Nil options use the table format and default columns
+	var buf bytes.Buffer
+	assert.NoError(t, Domains(&buf, testDomains(), nil))
+	assert.Equal(t, format(t, FormatTable), buf.String())
+}
+
+func TestWrite_Columns(t *testing.T) {
+	assert.Equal(t, ""+
+		"LABELS   DOMAIN\n"+
+		"         example.com\n"+
+		"eu,prod  example.org\n",
+		format(t, FormatTable, "labels", " Domain"))
+
+	var buf bytes.Buffer
+	err := Domains(&buf, testDomains(), &Options{Columns: []string{"domain", "owner"}})
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+	assert.Contains(t, err.Error(), `"owner"`)
+	assert.Contains(t, err.Error(), "not_local")
+}
+
+func TestWrite_CSV(t *testing.T) {
+	assert.Equal(t, ""+
+		"DOMAIN,LABELS,AUTO-RENEW\n"+
+		"example.com,,yes\n"+
+		"example.org,\"eu,prod\",no\n",
+		format(t, FormatCSV, "domain", "labels", "auto_renew"))
+}
+
+func TestWrite_JSON(t *testing.T) {
+	assert.Equal(t, `[
+  {
+    "domain": "example.com",
+    "auto_renew": true,
+    "expires": "2025-03-01T12:00:00Z",
+    "labels": []
+  },
+  {
+    "domain": "example.org",
+    "auto_renew": false,
+    "expires": "2025-03-01T12:00:00Z",
+    "labels": [
+      "eu",
+      "prod"
+    ]
+  }
+]
+`, format(t, FormatJSON, "domain", "auto_renew", "expires", "labels"))
+
+	var buf bytes.Buffer
+	assert.NoError(t, Domains(&buf, nil, &Options{Format: FormatJSON}))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestWrite_YAML(t *testing.T) {
+	assert.Equal(t, ""+
+		"- domain: example.com\n"+
+		"  auto_renew: true\n"+
+		"  labels: []\n"+
+		"- domain: example.org\n"+
+		"  auto_renew: false\n"+
+		"  labels:\n"+
+		"    - eu\n"+
+		"    - prod\n",
+		format(t, FormatYAML, "domain", "auto_renew", "labels"))
+
+	var buf bytes.Buffer
+	assert.NoError(t, Domains(&buf, nil, &Options{Format: FormatYAML}))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := Domains(&buf, testDomains(), &Options{Format: "xml"})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestText(t *testing.T) {
+	id := int64(42)
+	assert.Equal(t, "", text(nil))
+	assert.Equal(t, "42", text(&id))
+	assert.Equal(t, "", text((*int64)(nil)))
+	assert.Equal(t, "", text(time.Time{}))
+	assert.Equal(t, "a,b", text([]string{"a", "b"}))
+	assert.Equal(t, "1.5", text(1.5))
+}
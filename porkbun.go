@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -57,6 +58,23 @@ func (r *ErrorResponse) Error() string {
 // String is a helper function that allocates a new string value and returns a pointer to it.
 func String(v string) *string { return &v }
 
+// NormalizeName lower-cases a domain or record name and removes surrounding spaces and the
+// trailing dot, so names can be compared.
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
+
+// RelativeName returns the name of a record relative to its domain, empty for the apex as expected
+// by the subdomain parameters of DnsService. Both names are normalized, and a name outside the
+// domain is returned normalized but otherwise unchanged.
+func RelativeName(name, domain string) string {
+	name, domain = NormalizeName(name), NormalizeName(domain)
+	if name == domain {
+		return ""
+	}
+	return strings.TrimSuffix(name, "."+domain)
+}
+
 // BoolString is a custom type for handling boolean values represented as "1"/"0" strings in JSON.
 type BoolString bool
 
@@ -110,6 +110,20 @@ func TestStringPointerWithEmptyString(t *testing.T) {
 	assert.Equal(t, "", *str)
 }
 
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "example.com", NormalizeName(" Example.COM. "))
+	assert.Equal(t, "", NormalizeName("."))
+}
+
+func TestRelativeName(t *testing.T) {
+	assert.Equal(t, "", RelativeName("Example.com.", "example.com"))
+	assert.Equal(t, "", RelativeName("", "example.com"))
+	assert.Equal(t, "www", RelativeName("WWW.example.com", "example.com."))
+	assert.Equal(t, "a.b", RelativeName("a.b.example.com", "example.com"))
+	assert.Equal(t, "www.example.org", RelativeName("www.example.org.", "example.com"))
+	assert.Equal(t, "notexample.com", RelativeName("notexample.com", "example.com"))
+}
+
 func TestBoolStringWithInvalidValue(t *testing.T) {
 	var bs BoolString
 	err := json.Unmarshal([]byte(`"invalid"`), &bs)
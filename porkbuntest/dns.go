@@ -0,0 +1,327 @@
+package porkbuntest
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// wireRecord is the JSON representation of a DNS record in API responses.
+type wireRecord struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Type    string  `json:"type"`
+	Content string  `json:"content"`
+	TTL     string  `json:"ttl"`
+	Prio    *string `json:"prio"`
+	Notes   *string `json:"notes"`
+}
+
+// AddRecord adds a DNS record to the domain, with the name relative to the domain as in
+// DnsService.CreateRecord. It returns the ID of the new record, or false if the domain does not exist.
+func (s *Server) AddRecord(domain string, record porkbun.DnsRecord) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.domains[porkbun.NormalizeName(domain)]
+	if state == nil {
+		return 0, false
+	}
+	return s.addRecord(state, record), true
+}
+
+// Records returns the DNS records of the domain as returned by DnsService.GetRecords,
+// with fully qualified names.
+func (s *Server) Records(domain string) []porkbun.DnsRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.domains[porkbun.NormalizeName(domain)]
+	if state == nil {
+		return nil
+	}
+
+	records := make([]porkbun.DnsRecord, 0, len(state.records))
+	for _, record := range state.records {
+		id := *record.ID
+		record.ID = &id
+		record.Name = fqdn(record.Name, state.domain.Domain)
+		records = append(records, record)
+	}
+	return records
+}
+
+// addRecord stores the record with the next ID, applying the API's defaults.
+func (s *Server) addRecord(state *domainState, record porkbun.DnsRecord) int64 {
+	id := s.nextRecordID
+	s.nextRecordID++
+
+	record.ID = &id
+	record.Name = porkbun.NormalizeName(record.Name)
+	record.Type = porkbun.DnsRecordType(strings.ToUpper(string(record.Type)))
+	record.TTL = normalizeTTL(record.TTL)
+	state.records = append(state.records, record)
+
+	return id
+}
+
+// serveDns handles the dns/{action}/{domain}/... endpoints.
+func (s *Server) serveDns(w http.ResponseWriter, action, domain string, args []string, body map[string]json.RawMessage) {
+	switch action {
+	case "create", "edit", "editByNameType", "delete", "deleteByNameType", "retrieve", "retrieveByNameType":
+	default:
+		writeError(w, http.StatusNotFound, MsgInvalidEndpoint)
+		return
+	}
+
+	state := s.find(w, domain)
+	if state == nil {
+		return
+	}
+
+	switch action {
+	case "create":
+		s.createRecord(w, state, body)
+	case "edit":
+		s.editRecord(w, state, args, body)
+	case "delete":
+		s.deleteRecord(w, state, args)
+	case "retrieve":
+		s.retrieveRecords(w, state, args)
+	default:
+		recordType, subdomain, ok := typeArgs(w, args)
+		if !ok {
+			return
+		}
+		switch action {
+		case "editByNameType":
+			s.editRecordsByType(w, state, recordType, subdomain, body)
+		case "deleteByNameType":
+			s.deleteRecordsByType(w, state, recordType, subdomain)
+		case "retrieveByNameType":
+			s.retrieveRecordsByType(w, state, recordType, subdomain)
+		}
+	}
+}
+
+// createRecord handles dns/create/{domain}.
+func (s *Server) createRecord(w http.ResponseWriter, state *domainState, body map[string]json.RawMessage) {
+	record := porkbun.DnsRecord{
+		Name:    stringField(body, "name"),
+		Type:    porkbun.DnsRecordType(strings.ToUpper(stringField(body, "type"))),
+		Content: stringField(body, "content"),
+		TTL:     stringField(body, "ttl"),
+		Prio:    stringField(body, "prio"),
+		Notes:   stringField(body, "notes"),
+	}
+	if message := validateRecord(record.Type, record.Content); message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	id := s.addRecord(state, record)
+	writeJSON(w, map[string]any{"status": "SUCCESS", "id": id})
+}
+
+// editRecord handles dns/edit/{domain}/{id}.
+func (s *Server) editRecord(w http.ResponseWriter, state *domainState, args []string, body map[string]json.RawMessage) {
+	i := recordIndex(state, args)
+	if i < 0 {
+		writeError(w, http.StatusBadRequest, MsgInvalidRecordID)
+		return
+	}
+
+	recordType := porkbun.DnsRecordType(strings.ToUpper(stringField(body, "type")))
+	content := stringField(body, "content")
+	if message := validateRecord(recordType, content); message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	record := &state.records[i]
+	record.Name = porkbun.NormalizeName(stringField(body, "name"))
+	record.Type = recordType
+	record.Content = content
+	record.TTL = normalizeTTL(stringField(body, "ttl"))
+	record.Prio = stringField(body, "prio")
+	if _, ok := body["notes"]; ok {
+		record.Notes = stringField(body, "notes")
+	}
+
+	writeSuccess(w)
+}
+
+// editRecordsByType handles dns/editByNameType/{domain}/{type}/{subdomain}.
+func (s *Server) editRecordsByType(w http.ResponseWriter, state *domainState, recordType porkbun.DnsRecordType, subdomain string, body map[string]json.RawMessage) {
+	content := stringField(body, "content")
+	if message := validateRecord(recordType, content); message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	for i := range state.records {
+		record := &state.records[i]
+		if record.Type == recordType && record.Name == subdomain {
+			record.Content = content
+			record.TTL = normalizeTTL(stringField(body, "ttl"))
+			record.Prio = stringField(body, "prio")
+		}
+	}
+
+	writeSuccess(w)
+}
+
+// deleteRecord handles dns/delete/{domain}/{id}.
+func (s *Server) deleteRecord(w http.ResponseWriter, state *domainState, args []string) {
+	i := recordIndex(state, args)
+	if i < 0 {
+		writeError(w, http.StatusBadRequest, MsgInvalidRecordID)
+		return
+	}
+
+	state.records = append(state.records[:i], state.records[i+1:]...)
+	writeSuccess(w)
+}
+
+// deleteRecordsByType handles dns/deleteByNameType/{domain}/{type}/{subdomain}.
+func (s *Server) deleteRecordsByType(w http.ResponseWriter, state *domainState, recordType porkbun.DnsRecordType, subdomain string) {
+	kept := state.records[:0]
+	for _, record := range state.records {
+		if record.Type != recordType || record.Name != subdomain {
+			kept = append(kept, record)
+		}
+	}
+	state.records = kept
+
+	writeSuccess(w)
+}
+
+// retrieveRecords handles dns/retrieve/{domain}/{id}, where the ID is optional.
+func (s *Server) retrieveRecords(w http.ResponseWriter, state *domainState, args []string) {
+	if len(args) == 0 {
+		writeRecords(w, state, state.records)
+		return
+	}
+
+	// An unknown ID returns an empty list rather than an error
+	if i := recordIndex(state, args); i >= 0 {
+		writeRecords(w, state, state.records[i:i+1])
+		return
+	}
+	writeRecords(w, state, nil)
+}
+
+// retrieveRecordsByType handles dns/retrieveByNameType/{domain}/{type}/{subdomain}.
+func (s *Server) retrieveRecordsByType(w http.ResponseWriter, state *domainState, recordType porkbun.DnsRecordType, subdomain string) {
+	var records []porkbun.DnsRecord
+	for _, record := range state.records {
+		if record.Type == recordType && record.Name == subdomain {
+			records = append(records, record)
+		}
+	}
+	writeRecords(w, state, records)
+}
+
+// writeRecords writes the records in the API's format, with fully qualified names and string IDs.
+func writeRecords(w http.ResponseWriter, state *domainState, records []porkbun.DnsRecord) {
+	wire := make([]wireRecord, 0, len(records))
+	for _, record := range records {
+		r := wireRecord{
+			ID:      strconv.FormatInt(*record.ID, 10),
+			Name:    fqdn(record.Name, state.domain.Domain),
+			Type:    string(record.Type),
+			Content: record.Content,
+			TTL:     record.TTL,
+		}
+		// Copies, as the loop variable is reused across iterations
+		if prio := record.Prio; prio != "" {
+			r.Prio = &prio
+		}
+		if notes := record.Notes; notes != "" {
+			r.Notes = &notes
+		}
+		wire = append(wire, r)
+	}
+
+	writeJSON(w, map[string]any{"status": "SUCCESS", "cloudflare": "enabled", "records": wire})
+}
+
+// typeArgs parses the {type}/{subdomain} path arguments, writing an error response if the type is invalid.
+func typeArgs(w http.ResponseWriter, args []string) (porkbun.DnsRecordType, string, bool) {
+	if len(args) == 0 || len(args) > 2 {
+		writeError(w, http.StatusBadRequest, MsgInvalidRecordType)
+		return "", "", false
+	}
+
+	recordType := porkbun.DnsRecordType(strings.ToUpper(args[0]))
+	if !recordType.IsValid() {
+		writeError(w, http.StatusBadRequest, MsgInvalidRecordType)
+		return "", "", false
+	}
+
+	subdomain := ""
+	if len(args) == 2 {
+		subdomain = porkbun.NormalizeName(args[1])
+	}
+	return recordType, subdomain, true
+}
+
+// recordIndex returns the index of the record whose ID is the first path argument, or -1.
+func recordIndex(state *domainState, args []string) int {
+	if len(args) != 1 {
+		return -1
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return -1
+	}
+	for i, record := range state.records {
+		if *record.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateRecord checks the record type and content, returning the API error message if invalid.
+func validateRecord(recordType porkbun.DnsRecordType, content string) string {
+	if !recordType.IsValid() {
+		return MsgInvalidRecordType
+	}
+	if content == "" {
+		return MsgInvalidContent
+	}
+
+	switch recordType {
+	case porkbun.A:
+		if ip := net.ParseIP(content); ip == nil || ip.To4() == nil {
+			return MsgInvalidContent
+		}
+	case porkbun.AAAA:
+		if ip := net.ParseIP(content); ip == nil || ip.To4() != nil {
+			return MsgInvalidContent
+		}
+	}
+	return ""
+}
+
+// normalizeTTL applies the default and minimum TTL.
+func normalizeTTL(ttl string) string {
+	value, err := strconv.Atoi(ttl)
+	if err != nil || value < MinTTL {
+		return strconv.Itoa(MinTTL)
+	}
+	return strconv.Itoa(value)
+}
+
+// fqdn returns the fully qualified name of a name relative to the domain.
+func fqdn(name, domain string) string {
+	if name == "" {
+		return domain
+	}
+	return name + "." + domain
+}
@@ -0,0 +1,135 @@
+package porkbuntest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+func TestServer_DnsLifecycle(t *testing.T) {
+	server, client := setupServer(t)
+	ctx := context.Background()
+
+	created, err := client.Dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "203.0.113.1", TTL: "300"})
+	assert.NoError(t, err)
+	second, err := client.Dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Type: porkbun.MX, Content: "mail.example.com", Prio: "10", Notes: "Mail"})
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID+1, second.ID)
+
+	resp, err := client.Dns.GetRecords(ctx, "example.com", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []porkbun.DnsRecord{
+		{ID: &created.ID, Name: "www.example.com", Type: porkbun.A, Content: "203.0.113.1", TTL: "600"},
+		{ID: &second.ID, Name: "example.com", Type: porkbun.MX, Content: "mail.example.com", TTL: "600", Prio: "10", Notes: "Mail"},
+	}, resp.Records)
+	assert.Equal(t, resp.Records, server.Records("example.com"))
+
+	resp, err = client.Dns.GetRecords(ctx, "example.com", &second.ID)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Records, 1)
+	assert.Equal(t, porkbun.MX, resp.Records[0].Type)
+
+	_, err = client.Dns.EditRecord(ctx, "example.com", created.ID, &porkbun.EditRecord{Name: "api", Type: porkbun.A, Content: "203.0.113.2", TTL: "3600"})
+	assert.NoError(t, err)
+
+	resp, err = client.Dns.GetRecordsByType(ctx, "example.com", porkbun.A, porkbun.String("api"))
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Records, 1) {
+		assert.Equal(t, "api.example.com", resp.Records[0].Name)
+		assert.Equal(t, "203.0.113.2", resp.Records[0].Content)
+		assert.Equal(t, "3600", resp.Records[0].TTL)
+	}
+
+	_, err = client.Dns.EditRecordByType(ctx, "example.com", porkbun.A, porkbun.String("api"), &porkbun.EditTypeRecord{Content: "203.0.113.3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.3", server.Records("example.com")[0].Content)
+
+	_, err = client.Dns.DeleteRecordByType(ctx, "example.com", porkbun.A, porkbun.String("api"))
+	assert.NoError(t, err)
+	_, err = client.Dns.DeleteRecord(ctx, "example.com", second.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, server.Records("example.com"))
+}
+
+func TestServer_DnsOptionalFields(t *testing.T) {
+	server, client := setupServer(t)
+
+	// Priorities and notes stay with their record when followed by records without them
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.MX, Content: "mx1.example.net", Prio: "10", Notes: "primary"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.MX, Content: "mx2.example.net", Prio: "20"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.A, Content: "203.0.113.1"})
+
+	resp, err := client.Dns.GetRecords(context.Background(), "example.com", nil)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Records, 3) {
+		assert.Equal(t, "10", resp.Records[0].Prio)
+		assert.Equal(t, "primary", resp.Records[0].Notes)
+		assert.Equal(t, "20", resp.Records[1].Prio)
+		assert.Equal(t, "", resp.Records[1].Notes)
+		assert.Equal(t, "", resp.Records[2].Prio)
+	}
+}
+
+func TestServer_DnsApexRecordsByType(t *testing.T) {
+	server, client := setupServer(t)
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.TXT, Content: "v=spf1 -all"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.TXT, Content: "other"})
+
+	resp, err := client.Dns.GetRecordsByType(context.Background(), "example.com", porkbun.TXT, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Records, 1) {
+		assert.Equal(t, "example.com", resp.Records[0].Name)
+	}
+
+	_, ok := server.AddRecord("example.org", porkbun.DnsRecord{Type: porkbun.TXT, Content: "x"})
+	assert.False(t, ok)
+}
+
+func TestServer_DnsErrors(t *testing.T) {
+	server, client := setupServer(t)
+	ctx := context.Background()
+	id, _ := server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.A, Content: "203.0.113.1"})
+
+	for name, call := range map[string]struct {
+		fn      func() error
+		message string
+	}{
+		"invalid domain": {func() error {
+			_, err := client.Dns.GetRecords(ctx, "example.org", nil)
+			return err
+		}, MsgInvalidDomain},
+		"invalid type": {func() error {
+			_, err := client.Dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Type: "BOGUS", Content: "x"})
+			return err
+		}, MsgInvalidRecordType},
+		"invalid A content": {func() error {
+			_, err := client.Dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Type: porkbun.A, Content: "2001:db8::1"})
+			return err
+		}, MsgInvalidContent},
+		"empty content": {func() error {
+			_, err := client.Dns.EditRecordByType(ctx, "example.com", porkbun.A, nil, &porkbun.EditTypeRecord{})
+			return err
+		}, MsgInvalidContent},
+		"unknown record": {func() error {
+			_, err := client.Dns.EditRecord(ctx, "example.com", id+100, &porkbun.EditRecord{Type: porkbun.A, Content: "203.0.113.1"})
+			return err
+		}, MsgInvalidRecordID},
+		"unknown delete": {func() error {
+			_, err := client.Dns.DeleteRecord(ctx, "example.com", id+100)
+			return err
+		}, MsgInvalidRecordID},
+		"invalid type path": {func() error {
+			_, err := client.Dns.DeleteRecordByType(ctx, "example.com", "BOGUS", nil)
+			return err
+		}, MsgInvalidRecordType},
+	} {
+		message, code := apiError(t, call.fn())
+		assert.Equal(t, call.message, message, name)
+		assert.Equal(t, http.StatusBadRequest, code, name)
+	}
+
+	assert.Len(t, server.Records("example.com"), 1)
+}
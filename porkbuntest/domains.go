@@ -0,0 +1,218 @@
+package porkbuntest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// wireDomain is the JSON representation of a domain in listAll responses.
+type wireDomain struct {
+	Domain       string          `json:"domain"`
+	Status       string          `json:"status"`
+	TLD          string          `json:"tld"`
+	CreateDate   string          `json:"createDate"`
+	ExpireDate   string          `json:"expireDate"`
+	SecurityLock string          `json:"securityLock"`
+	WhoisPrivacy string          `json:"whoisPrivacy"`
+	AutoRenew    int             `json:"autoRenew"`
+	NotLocal     int             `json:"notLocal"`
+	Labels       []porkbun.Label `json:"labels,omitempty"`
+}
+
+// NameServers returns the name servers of the domain.
+func (s *Server) NameServers(domain string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.domains[porkbun.NormalizeName(domain)]
+	if state == nil {
+		return nil
+	}
+	return append([]string(nil), state.nameServers...)
+}
+
+// Forwards returns the URL forwards of the domain.
+func (s *Server) Forwards(domain string) []porkbun.UrlForwardData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.domains[porkbun.NormalizeName(domain)]
+	if state == nil {
+		return nil
+	}
+	return append([]porkbun.UrlForwardData(nil), state.forwards...)
+}
+
+// serveDomain handles the domain/{action}/... endpoints.
+func (s *Server) serveDomain(w http.ResponseWriter, action string, args []string, body map[string]json.RawMessage) {
+	if action == "listAll" && len(args) == 0 {
+		s.listDomains(w, body)
+		return
+	}
+
+	switch action {
+	case "getNs", "updateNs", "getUrlForwarding", "addUrlForward", "deleteUrlForward":
+	default:
+		writeError(w, http.StatusNotFound, MsgInvalidEndpoint)
+		return
+	}
+	if len(args) == 0 {
+		writeError(w, http.StatusBadRequest, MsgInvalidDomain)
+		return
+	}
+
+	state := s.find(w, args[0])
+	if state == nil {
+		return
+	}
+
+	switch action {
+	case "getNs":
+		writeJSON(w, map[string]any{"status": "SUCCESS", "ns": state.nameServers})
+	case "updateNs":
+		s.updateNameServers(w, state, body)
+	case "getUrlForwarding":
+		writeJSON(w, map[string]any{"status": "SUCCESS", "forwards": append([]porkbun.UrlForwardData{}, state.forwards...)})
+	case "addUrlForward":
+		s.addUrlForward(w, state, body)
+	case "deleteUrlForward":
+		s.deleteUrlForward(w, state, args[1:])
+	}
+}
+
+// listDomains handles domain/listAll, returning up to DomainsPageSize domains from the start index.
+func (s *Server) listDomains(w http.ResponseWriter, body map[string]json.RawMessage) {
+	start, _ := strconv.Atoi(stringField(body, "start"))
+	includeLabels := stringField(body, "includeLabels") == "yes"
+
+	names := make([]string, 0, len(s.domains))
+	for name := range s.domains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if start < 0 || start > len(names) {
+		start = len(names)
+	}
+	end := start + DomainsPageSize
+	if end > len(names) {
+		end = len(names)
+	}
+
+	domains := make([]wireDomain, 0, end-start)
+	for _, name := range names[start:end] {
+		d := s.domains[name].domain
+		wire := wireDomain{
+			Domain:       d.Domain,
+			Status:       d.Status,
+			TLD:          d.TLD,
+			CreateDate:   d.CreateDate.Format(dateFormat),
+			ExpireDate:   d.ExpireDate.Format(dateFormat),
+			SecurityLock: boolString(bool(d.SecurityLock)),
+			WhoisPrivacy: boolString(bool(d.WhoisPrivacy)),
+			AutoRenew:    boolNumber(bool(d.AutoRenew)),
+			NotLocal:     boolNumber(bool(d.NotLocal)),
+		}
+		if includeLabels {
+			wire.Labels = d.Labels
+		}
+		domains = append(domains, wire)
+	}
+
+	writeJSON(w, map[string]any{"status": "SUCCESS", "domains": domains})
+}
+
+// updateNameServers handles domain/updateNs/{domain}.
+func (s *Server) updateNameServers(w http.ResponseWriter, state *domainState, body map[string]json.RawMessage) {
+	var ns []string
+	if err := json.Unmarshal(body["ns"], &ns); err != nil || !normalizeNameServers(ns) {
+		writeError(w, http.StatusBadRequest, MsgInvalidNameServer)
+		return
+	}
+
+	state.nameServers = ns
+	writeSuccess(w)
+}
+
+// normalizeNameServers normalizes the names in place, reporting false if the list is empty or
+// holds an empty name.
+func normalizeNameServers(ns []string) bool {
+	if len(ns) == 0 {
+		return false
+	}
+	for i, name := range ns {
+		if name == "" {
+			return false
+		}
+		ns[i] = porkbun.NormalizeName(name)
+	}
+	return true
+}
+
+// addUrlForward handles domain/addUrlForward/{domain}.
+func (s *Server) addUrlForward(w http.ResponseWriter, state *domainState, body map[string]json.RawMessage) {
+	forward := porkbun.UrlForward{
+		Subdomain:   porkbun.NormalizeName(stringField(body, "subdomain")),
+		Location:    stringField(body, "location"),
+		Type:        porkbun.ForwardType(stringField(body, "type")),
+		IncludePath: stringField(body, "includePath"),
+		Wildcard:    stringField(body, "wildcard"),
+	}
+
+	if !validForward(forward) {
+		writeError(w, http.StatusBadRequest, MsgInvalidForward)
+		return
+	}
+
+	id := s.nextForwardID
+	s.nextForwardID++
+	state.forwards = append(state.forwards, porkbun.UrlForwardData{Id: strconv.FormatInt(id, 10), UrlForward: forward})
+
+	writeSuccess(w)
+}
+
+// deleteUrlForward handles domain/deleteUrlForward/{domain}/{id}.
+func (s *Server) deleteUrlForward(w http.ResponseWriter, state *domainState, args []string) {
+	if len(args) == 1 {
+		for i, forward := range state.forwards {
+			if forward.Id == args[0] {
+				state.forwards = append(state.forwards[:i], state.forwards[i+1:]...)
+				writeSuccess(w)
+				return
+			}
+		}
+	}
+	writeError(w, http.StatusBadRequest, MsgInvalidRecordID)
+}
+
+// validForward reports whether the forward has a location, a known type and yes/no flags.
+func validForward(forward porkbun.UrlForward) bool {
+	return forward.Location != "" &&
+		(forward.Type == porkbun.Temporary || forward.Type == porkbun.Permanent) &&
+		yesNo(forward.IncludePath) && yesNo(forward.Wildcard)
+}
+
+// yesNo reports whether the value is "yes" or "no", the API's boolean flags for URL forwards.
+func yesNo(value string) bool {
+	return value == "yes" || value == "no"
+}
+
+// boolString encodes a boolean as the "1"/"0" strings used by the API.
+func boolString(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// boolNumber encodes a boolean as the 1/0 numbers used by the API.
+func boolNumber(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,148 @@
+package porkbuntest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+func TestServer_ListDomains(t *testing.T) {
+	server, client := setupServer(t)
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	server.AddDomain(porkbun.Domain{
+		Domain:       "Example.Dev",
+		CreateDate:   created,
+		SecurityLock: true,
+		AutoRenew:    true,
+		Labels:       []porkbun.Label{{ID: "1", Title: "prod", Color: "#ff0000"}},
+	})
+
+	resp, err := client.Domains.ListDomains(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Domains, 2) {
+		assert.Equal(t, "example.com", resp.Domains[0].Domain)
+		dev := resp.Domains[1]
+		assert.Equal(t, "example.dev", dev.Domain)
+		assert.Equal(t, "ACTIVE", dev.Status)
+		assert.Equal(t, "dev", dev.TLD)
+		assert.Equal(t, created, dev.CreateDate)
+		assert.Equal(t, created.AddDate(1, 0, 0), dev.ExpireDate)
+		assert.True(t, bool(dev.SecurityLock))
+		assert.False(t, bool(dev.WhoisPrivacy))
+		assert.True(t, bool(dev.AutoRenew))
+		assert.Empty(t, dev.Labels)
+	}
+
+	resp, err = client.Domains.ListDomains(context.Background(), &porkbun.DomainListOptions{IncludeLabels: porkbun.String("yes")})
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", resp.Domains[1].Labels[0].Title)
+}
+
+func TestServer_ListDomainsPagination(t *testing.T) {
+	server := NewServer(nil)
+	defer server.Close()
+	for i := 0; i < DomainsPageSize+5; i++ {
+		server.AddDomain(porkbun.Domain{Domain: fmt.Sprintf("domain%04d.com", i)})
+	}
+
+	client := server.Client()
+	resp, err := client.Domains.ListDomains(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Domains, DomainsPageSize)
+
+	resp, err = client.Domains.ListDomains(context.Background(), &porkbun.DomainListOptions{Start: porkbun.String(strconv.Itoa(DomainsPageSize))})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Domains, 5) {
+		assert.Equal(t, "domain1000.com", resp.Domains[0].Domain)
+	}
+	assert.Len(t, server.Domains(), DomainsPageSize+5)
+
+	all, err := client.Domains.ListAllDomains(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, all, DomainsPageSize+5) {
+		assert.Equal(t, "domain1004.com", all[DomainsPageSize+4].Domain)
+	}
+}
+
+func TestServer_UpdateRemoveDomain(t *testing.T) {
+	server, client := setupServer(t)
+	ctx := context.Background()
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1"})
+
+	assert.True(t, server.UpdateDomain(porkbun.Domain{Domain: "Example.com", Status: "EXPIRED", AutoRenew: true}))
+	assert.False(t, server.UpdateDomain(porkbun.Domain{Domain: "example.net"}))
+
+	resp, err := client.Domains.ListDomains(ctx, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Domains, 1) {
+		assert.Equal(t, "EXPIRED", resp.Domains[0].Status)
+		assert.True(t, bool(resp.Domains[0].AutoRenew))
+	}
+	assert.Len(t, server.Records("example.com"), 1)
+
+	assert.True(t, server.RemoveDomain("example.com"))
+	assert.False(t, server.RemoveDomain("example.com"))
+	assert.Empty(t, server.Domains())
+
+	_, err = client.Dns.GetRecords(ctx, "example.com", nil)
+	assert.Error(t, err)
+}
+
+func TestServer_NameServers(t *testing.T) {
+	server, client := setupServer(t)
+	ctx := context.Background()
+
+	resp, err := client.Domains.GetNameServers(ctx, "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, porkbun.NameServers(DefaultNameServers), resp.NS)
+
+	_, err = client.Domains.UpdateNameServers(ctx, "example.com", &porkbun.NameServers{"ns1.example.net", "NS2.example.net."})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns1.example.net", "ns2.example.net"}, server.NameServers("example.com"))
+
+	_, err = client.Domains.UpdateNameServers(ctx, "example.com", &porkbun.NameServers{})
+	message, _ := apiError(t, err)
+	assert.Equal(t, MsgInvalidNameServer, message)
+
+	_, err = client.Domains.GetNameServers(ctx, "example.org")
+	message, _ = apiError(t, err)
+	assert.Equal(t, MsgInvalidDomain, message)
+}
+
+func TestServer_UrlForwarding(t *testing.T) {
+	server, client := setupServer(t)
+	ctx := context.Background()
+
+	resp, err := client.Domains.GetDomainURLForwarding(ctx, "example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Forwards)
+
+	forward := porkbun.UrlForward{Subdomain: "blog", Location: "https://blog.example.net", Type: porkbun.Permanent, IncludePath: "yes", Wildcard: "no"}
+	_, err = client.Domains.AddDomainUrlForward(ctx, "example.com", &forward)
+	assert.NoError(t, err)
+
+	resp, err = client.Domains.GetDomainURLForwarding(ctx, "example.com")
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Forwards, 1) {
+		assert.Equal(t, forward, resp.Forwards[0].UrlForward)
+		assert.NotEmpty(t, resp.Forwards[0].Id)
+	}
+	assert.Equal(t, resp.Forwards, server.Forwards("example.com"))
+
+	_, err = client.Domains.AddDomainUrlForward(ctx, "example.com", &porkbun.UrlForward{Location: "https://example.net", Type: "sometimes"})
+	message, _ := apiError(t, err)
+	assert.Equal(t, MsgInvalidForward, message)
+
+	_, err = client.Domains.DeleteDomainUrlForward(ctx, "example.com", "1")
+	message, _ = apiError(t, err)
+	assert.Equal(t, MsgInvalidRecordID, message)
+
+	_, err = client.Domains.DeleteDomainUrlForward(ctx, "example.com", resp.Forwards[0].Id)
+	assert.NoError(t, err)
+	assert.Empty(t, server.Forwards("example.com"))
+}
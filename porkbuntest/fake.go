@@ -0,0 +1,605 @@
+package porkbuntest
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// Ensure the fakes implement the service interfaces.
+var (
+	_ porkbun.DNSAPI     = (*FakeDNS)(nil)
+	_ porkbun.DomainsAPI = (*FakeDomains)(nil)
+	_ porkbun.SSLAPI     = (*FakeSSL)(nil)
+	_ porkbun.PricingAPI = (*FakePricing)(nil)
+)
+
+// newAPIError returns the error the porkbun client reports for an API error response.
+func newAPIError(message string) error {
+	return &porkbun.ErrorResponse{BaseResponse: porkbun.BaseResponse{Status: "ERROR"}, Message: message}
+}
+
+// success returns a successful BaseResponse.
+func success() porkbun.BaseResponse {
+	return porkbun.BaseResponse{Status: "SUCCESS"}
+}
+
+// FakeDNS is an in-memory porkbun.DNSAPI for unit tests that do not need HTTP. It applies the
+// same validation and defaults as the Server, and returns *porkbun.ErrorResponse errors with the
+// API's messages.
+type FakeDNS struct {
+	Err error // If set, returned by every call. Set it before the fake is in use.
+
+	mu      sync.Mutex
+	records map[string][]porkbun.DnsRecord // Records by domain, with names relative to the domain
+	nextID  int64
+}
+
+// NewFakeDNS creates a FakeDNS managing the given domains.
+func NewFakeDNS(domains ...string) *FakeDNS {
+	f := &FakeDNS{
+		records: make(map[string][]porkbun.DnsRecord),
+		nextID:  100000001,
+	}
+	for _, domain := range domains {
+		f.AddDomain(domain)
+	}
+	return f
+}
+
+// AddDomain adds a domain without records. Adding an existing domain keeps its records.
+func (f *FakeDNS) AddDomain(domain string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domain = porkbun.NormalizeName(domain)
+	if _, ok := f.records[domain]; !ok {
+		f.records[domain] = []porkbun.DnsRecord{}
+	}
+}
+
+// AddRecord adds a record to the domain without validation, with the name relative to the domain.
+// It returns the ID of the new record, or false if the domain does not exist.
+func (f *FakeDNS) AddRecord(domain string, record porkbun.DnsRecord) (int64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domain = porkbun.NormalizeName(domain)
+	if _, ok := f.records[domain]; !ok {
+		return 0, false
+	}
+	return f.add(domain, record), true
+}
+
+// Records returns the records of the domain with fully qualified names, as returned by GetRecords.
+func (f *FakeDNS) Records(domain string) []porkbun.DnsRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domain = porkbun.NormalizeName(domain)
+	return f.export(domain, f.records[domain])
+}
+
+// GetRecords implements porkbun.DNSAPI.
+func (f *FakeDNS) GetRecords(ctx context.Context, domain string, recordId *int64) (*porkbun.GetRecordsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domain, records, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	// An unknown ID returns an empty list rather than an error
+	if recordId != nil {
+		var matched []porkbun.DnsRecord
+		if i := recordIndexByID(records, *recordId); i >= 0 {
+			matched = records[i : i+1]
+		}
+		records = matched
+	}
+
+	return &porkbun.GetRecordsResponse{BaseResponse: success(), Records: f.export(domain, records)}, nil
+}
+
+// GetRecordsByType implements porkbun.DNSAPI.
+func (f *FakeDNS) GetRecordsByType(ctx context.Context, domain string, recordType porkbun.DnsRecordType, subdomain *string) (*porkbun.GetRecordsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domain, records, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+	recordType, name, err := typeAndName(recordType, subdomain)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []porkbun.DnsRecord
+	for _, record := range records {
+		if record.Type == recordType && record.Name == name {
+			matched = append(matched, record)
+		}
+	}
+
+	return &porkbun.GetRecordsResponse{BaseResponse: success(), Records: f.export(domain, matched)}, nil
+}
+
+// CreateRecord implements porkbun.DNSAPI.
+func (f *FakeDNS) CreateRecord(ctx context.Context, domain string, record *porkbun.DnsRecord) (*porkbun.CreateRecordResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domain, _, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	r := *record
+	r.Type = porkbun.DnsRecordType(strings.ToUpper(string(r.Type)))
+	if message := validateRecord(r.Type, r.Content); message != "" {
+		return nil, newAPIError(message)
+	}
+
+	return &porkbun.CreateRecordResponse{BaseResponse: success(), ID: f.add(domain, r)}, nil
+}
+
+// EditRecord implements porkbun.DNSAPI.
+func (f *FakeDNS) EditRecord(ctx context.Context, domain string, recordId int64, record *porkbun.EditRecord) (*porkbun.EditRecordResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domain, records, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+	i := recordIndexByID(records, recordId)
+	if i < 0 {
+		return nil, newAPIError(MsgInvalidRecordID)
+	}
+
+	recordType := porkbun.DnsRecordType(strings.ToUpper(string(record.Type)))
+	if message := validateRecord(recordType, record.Content); message != "" {
+		return nil, newAPIError(message)
+	}
+
+	existing := &f.records[domain][i]
+	existing.Name = porkbun.NormalizeName(record.Name)
+	existing.Type = recordType
+	existing.Content = record.Content
+	existing.TTL = normalizeTTL(record.TTL)
+	existing.Prio = record.Prio
+
+	return &porkbun.EditRecordResponse{BaseResponse: success()}, nil
+}
+
+// EditRecordByType implements porkbun.DNSAPI.
+func (f *FakeDNS) EditRecordByType(ctx context.Context, domain string, recordType porkbun.DnsRecordType, subdomain *string, record *porkbun.EditTypeRecord) (*porkbun.EditRecordResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domain, records, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+	recordType, name, err := typeAndName(recordType, subdomain)
+	if err != nil {
+		return nil, err
+	}
+	if message := validateRecord(recordType, record.Content); message != "" {
+		return nil, newAPIError(message)
+	}
+
+	for i := range records {
+		existing := &records[i]
+		if existing.Type == recordType && existing.Name == name {
+			existing.Content = record.Content
+			existing.TTL = normalizeTTL(record.TTL)
+			existing.Prio = record.Prio
+		}
+	}
+
+	return &porkbun.EditRecordResponse{BaseResponse: success()}, nil
+}
+
+// DeleteRecord implements porkbun.DNSAPI.
+func (f *FakeDNS) DeleteRecord(ctx context.Context, domain string, recordId int64) (*porkbun.DeleteRecordResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domain, records, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+	i := recordIndexByID(records, recordId)
+	if i < 0 {
+		return nil, newAPIError(MsgInvalidRecordID)
+	}
+
+	f.records[domain] = append(records[:i], records[i+1:]...)
+	return &porkbun.DeleteRecordResponse{BaseResponse: success()}, nil
+}
+
+// DeleteRecordByType implements porkbun.DNSAPI.
+func (f *FakeDNS) DeleteRecordByType(ctx context.Context, domain string, recordType porkbun.DnsRecordType, subdomain *string) (*porkbun.DeleteRecordResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domain, records, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+	recordType, name, err := typeAndName(recordType, subdomain)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := records[:0]
+	for _, record := range records {
+		if record.Type != recordType || record.Name != name {
+			kept = append(kept, record)
+		}
+	}
+	f.records[domain] = kept
+
+	return &porkbun.DeleteRecordResponse{BaseResponse: success()}, nil
+}
+
+// find returns the normalized domain and its records, or the configured or API error.
+func (f *FakeDNS) find(domain string) (string, []porkbun.DnsRecord, error) {
+	if f.Err != nil {
+		return "", nil, f.Err
+	}
+
+	domain = porkbun.NormalizeName(domain)
+	records, ok := f.records[domain]
+	if !ok {
+		return "", nil, newAPIError(MsgInvalidDomain)
+	}
+	return domain, records, nil
+}
+
+// add stores the record with the next ID, applying the API's defaults.
+func (f *FakeDNS) add(domain string, record porkbun.DnsRecord) int64 {
+	id := f.nextID
+	f.nextID++
+
+	record.ID = &id
+	record.Name = porkbun.NormalizeName(record.Name)
+	record.Type = porkbun.DnsRecordType(strings.ToUpper(string(record.Type)))
+	record.TTL = normalizeTTL(record.TTL)
+	f.records[domain] = append(f.records[domain], record)
+
+	return id
+}
+
+// export copies the records with fully qualified names.
+func (f *FakeDNS) export(domain string, records []porkbun.DnsRecord) []porkbun.DnsRecord {
+	exported := make([]porkbun.DnsRecord, 0, len(records))
+	for _, record := range records {
+		id := *record.ID
+		record.ID = &id
+		record.Name = fqdn(record.Name, domain)
+		exported = append(exported, record)
+	}
+	return exported
+}
+
+// recordIndexByID returns the index of the record with the ID, or -1.
+func recordIndexByID(records []porkbun.DnsRecord, id int64) int {
+	for i, record := range records {
+		if *record.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// typeAndName validates the record type and normalizes the subdomain of a by-type call.
+func typeAndName(recordType porkbun.DnsRecordType, subdomain *string) (porkbun.DnsRecordType, string, error) {
+	recordType = porkbun.DnsRecordType(strings.ToUpper(string(recordType)))
+	if !recordType.IsValid() {
+		return "", "", newAPIError(MsgInvalidRecordType)
+	}
+
+	name := ""
+	if subdomain != nil {
+		name = porkbun.NormalizeName(*subdomain)
+	}
+	return recordType, name, nil
+}
+
+// FakeDomains is an in-memory porkbun.DomainsAPI for unit tests that do not need HTTP.
+type FakeDomains struct {
+	Err error // If set, returned by every call. Set it before the fake is in use.
+
+	mu            sync.Mutex
+	domains       map[string]*fakeDomain
+	nextForwardID int64
+}
+
+// fakeDomain holds what FakeDomains knows about a domain.
+type fakeDomain struct {
+	domain      porkbun.Domain
+	nameServers []string
+	forwards    []porkbun.UrlForwardData
+}
+
+// NewFakeDomains creates a FakeDomains holding the given domains, see AddDomain.
+func NewFakeDomains(domains ...porkbun.Domain) *FakeDomains {
+	f := &FakeDomains{
+		domains:       make(map[string]*fakeDomain),
+		nextForwardID: 20000001,
+	}
+	for _, domain := range domains {
+		f.AddDomain(domain)
+	}
+	return f
+}
+
+// AddDomain adds a domain to the account with DefaultNameServers. Missing fields are filled in
+// as by Server.AddDomain.
+func (f *FakeDomains) AddDomain(domain porkbun.Domain) {
+	domain = withDomainDefaults(domain)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.domains[domain.Domain] = &fakeDomain{
+		domain:      domain,
+		nameServers: append([]string(nil), DefaultNameServers...),
+	}
+}
+
+// NameServers returns the name servers of the domain.
+func (f *FakeDomains) NameServers(domain string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if d := f.domains[porkbun.NormalizeName(domain)]; d != nil {
+		return append([]string(nil), d.nameServers...)
+	}
+	return nil
+}
+
+// Forwards returns the URL forwards of the domain.
+func (f *FakeDomains) Forwards(domain string) []porkbun.UrlForwardData {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if d := f.domains[porkbun.NormalizeName(domain)]; d != nil {
+		return append([]porkbun.UrlForwardData(nil), d.forwards...)
+	}
+	return nil
+}
+
+// ListDomains implements porkbun.DomainsAPI, returning up to DomainsPageSize domains sorted by name.
+func (f *FakeDomains) ListDomains(ctx context.Context, options *porkbun.DomainListOptions) (*porkbun.ListDomainsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	start := 0
+	if options != nil && options.Start != nil {
+		start, _ = strconv.Atoi(*options.Start)
+	}
+	return &porkbun.ListDomainsResponse{BaseResponse: success(), Domains: f.list(start, DomainsPageSize, options)}, nil
+}
+
+// ListAllDomains implements porkbun.DomainsAPI, returning every domain sorted by name.
+func (f *FakeDomains) ListAllDomains(ctx context.Context, options *porkbun.DomainListOptions) ([]porkbun.Domain, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	return f.list(0, len(f.domains), options), nil
+}
+
+// list returns up to limit domains sorted by name from the start index, with labels if requested.
+func (f *FakeDomains) list(start, limit int, options *porkbun.DomainListOptions) []porkbun.Domain {
+	includeLabels := options != nil && options.IncludeLabels != nil && *options.IncludeLabels == "yes"
+
+	names := make([]string, 0, len(f.domains))
+	for name := range f.domains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if start < 0 || start > len(names) {
+		start = len(names)
+	}
+	end := start + limit
+	if end > len(names) {
+		end = len(names)
+	}
+
+	domains := make([]porkbun.Domain, 0, end-start)
+	for _, name := range names[start:end] {
+		domain := f.domains[name].domain
+		if includeLabels {
+			domain.Labels = append([]porkbun.Label(nil), domain.Labels...)
+		} else {
+			domain.Labels = nil
+		}
+		domains = append(domains, domain)
+	}
+	return domains
+}
+
+// GetNameServers implements porkbun.DomainsAPI.
+func (f *FakeDomains) GetNameServers(ctx context.Context, domain string) (*porkbun.GetNameServersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+	return &porkbun.GetNameServersResponse{BaseResponse: success(), NS: append(porkbun.NameServers(nil), d.nameServers...)}, nil
+}
+
+// UpdateNameServers implements porkbun.DomainsAPI.
+func (f *FakeDomains) UpdateNameServers(ctx context.Context, domain string, newNameservers *porkbun.NameServers) (*porkbun.UpdateNameServersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var ns []string
+	if newNameservers != nil {
+		ns = append(ns, *newNameservers...)
+	}
+	if !normalizeNameServers(ns) {
+		return nil, newAPIError(MsgInvalidNameServer)
+	}
+
+	d.nameServers = ns
+	return &porkbun.UpdateNameServersResponse{BaseResponse: success()}, nil
+}
+
+// GetDomainURLForwarding implements porkbun.DomainsAPI.
+func (f *FakeDomains) GetDomainURLForwarding(ctx context.Context, domain string) (*porkbun.GetDomainURLForwardingResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+	return &porkbun.GetDomainURLForwardingResponse{BaseResponse: success(), Forwards: append([]porkbun.UrlForwardData{}, d.forwards...)}, nil
+}
+
+// AddDomainUrlForward implements porkbun.DomainsAPI.
+func (f *FakeDomains) AddDomainUrlForward(ctx context.Context, domain string, forwardAttributes *porkbun.UrlForward) (*porkbun.AddDomainUrlForwardResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	forward := *forwardAttributes
+	forward.Subdomain = porkbun.NormalizeName(forward.Subdomain)
+	if !validForward(forward) {
+		return nil, newAPIError(MsgInvalidForward)
+	}
+
+	id := f.nextForwardID
+	f.nextForwardID++
+	d.forwards = append(d.forwards, porkbun.UrlForwardData{Id: strconv.FormatInt(id, 10), UrlForward: forward})
+
+	return &porkbun.AddDomainUrlForwardResponse{BaseResponse: success()}, nil
+}
+
+// DeleteDomainUrlForward implements porkbun.DomainsAPI.
+func (f *FakeDomains) DeleteDomainUrlForward(ctx context.Context, domain string, recordId string) (*porkbun.DeleteDomainUrlForwardResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d, err := f.find(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, forward := range d.forwards {
+		if forward.Id == recordId {
+			d.forwards = append(d.forwards[:i], d.forwards[i+1:]...)
+			return &porkbun.DeleteDomainUrlForwardResponse{BaseResponse: success()}, nil
+		}
+	}
+	return nil, newAPIError(MsgInvalidRecordID)
+}
+
+// find returns the domain, or the configured or API error.
+func (f *FakeDomains) find(domain string) (*fakeDomain, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if d := f.domains[porkbun.NormalizeName(domain)]; d != nil {
+		return d, nil
+	}
+	return nil, newAPIError(MsgInvalidDomain)
+}
+
+// FakeSSL is an in-memory porkbun.SSLAPI for unit tests that do not need HTTP.
+type FakeSSL struct {
+	Err error // If set, returned by every call. Set it before the fake is in use.
+
+	mu      sync.Mutex
+	bundles map[string]porkbun.SslRetrieveResponse
+}
+
+// NewFakeSSL creates a FakeSSL without bundles.
+func NewFakeSSL() *FakeSSL {
+	return &FakeSSL{bundles: make(map[string]porkbun.SslRetrieveResponse)}
+}
+
+// SetSSL sets the bundle returned for the domain.
+func (f *FakeSSL) SetSSL(domain string, bundle porkbun.SslRetrieveResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.bundles[porkbun.NormalizeName(domain)] = porkbun.SslRetrieveResponse{
+		Certificatechain: bundle.Certificatechain,
+		Privatekey:       bundle.Privatekey,
+		Publickey:        bundle.Publickey,
+	}
+}
+
+// Retrieve implements porkbun.SSLAPI. Domains without a bundle fail with MsgSslNotReady.
+func (f *FakeSSL) Retrieve(ctx context.Context, domain string) (*porkbun.SslRetrieveResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	bundle, ok := f.bundles[porkbun.NormalizeName(domain)]
+	if !ok {
+		return nil, newAPIError(MsgSslNotReady)
+	}
+	bundle.BaseResponse = success()
+	return &bundle, nil
+}
+
+// FakePricing is an in-memory porkbun.PricingAPI for unit tests that do not need HTTP.
+type FakePricing struct {
+	Err     error                      // If set, returned by every call. Set it before the fake is in use.
+	Pricing map[string]porkbun.Pricing // Pricing returned by ListPricing.
+}
+
+// NewFakePricing creates a FakePricing returning pricing, or the Server's default table if nil.
+func NewFakePricing(pricing map[string]porkbun.Pricing) *FakePricing {
+	if pricing == nil {
+		pricing = defaultPricing()
+	}
+	return &FakePricing{Pricing: pricing}
+}
+
+// ListPricing implements porkbun.PricingAPI, returning a copy of Pricing.
+func (f *FakePricing) ListPricing(ctx context.Context) (*porkbun.PricingResponse, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	pricing := make(map[string]porkbun.Pricing, len(f.Pricing))
+	for tld, p := range f.Pricing {
+		pricing[tld] = p
+	}
+	return &porkbun.PricingResponse{BaseResponse: success(), Pricing: pricing}, nil
+}
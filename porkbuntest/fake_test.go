@@ -0,0 +1,193 @@
+package porkbuntest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// assertAPIError checks that err is an API error with the message.
+func assertAPIError(t *testing.T, err error, message string) {
+	t.Helper()
+
+	var apiErr *porkbun.ErrorResponse
+	if assert.True(t, errors.As(err, &apiErr), "%v", err) {
+		assert.Equal(t, message, apiErr.Message)
+	}
+}
+
+// exerciseDNS runs the same calls against any DNSAPI and returns the resulting records.
+func exerciseDNS(t *testing.T, dns porkbun.DNSAPI) []porkbun.DnsRecord {
+	ctx := context.Background()
+
+	created, err := dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1"})
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	_, err = dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Name: "", Type: porkbun.MX, Content: "mail.example.com", Prio: "10", TTL: "3600"})
+	assert.NoError(t, err)
+	_, err = dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Name: "old", Type: porkbun.TXT, Content: "x"})
+	assert.NoError(t, err)
+
+	_, err = dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "not an ip"})
+	assertAPIError(t, err, MsgInvalidContent)
+	_, err = dns.CreateRecord(ctx, "example.org", &porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1"})
+	assertAPIError(t, err, MsgInvalidDomain)
+
+	_, err = dns.EditRecord(ctx, "example.com", created.ID, &porkbun.EditRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.2"})
+	assert.NoError(t, err)
+	_, err = dns.EditRecord(ctx, "example.com", 1, &porkbun.EditRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.2"})
+	assertAPIError(t, err, MsgInvalidRecordID)
+
+	_, err = dns.EditRecordByType(ctx, "example.com", porkbun.MX, nil, &porkbun.EditTypeRecord{Content: "mx.example.com", Prio: "20"})
+	assert.NoError(t, err)
+	_, err = dns.DeleteRecordByType(ctx, "example.com", porkbun.TXT, porkbun.String("old"))
+	assert.NoError(t, err)
+	_, err = dns.GetRecordsByType(ctx, "example.com", "BOGUS", nil)
+	assertAPIError(t, err, MsgInvalidRecordType)
+
+	byType, err := dns.GetRecordsByType(ctx, "example.com", porkbun.A, porkbun.String("www"))
+	if assert.NoError(t, err) && assert.Len(t, byType.Records, 1) {
+		assert.Equal(t, "192.0.2.2", byType.Records[0].Content)
+	}
+	byID, err := dns.GetRecords(ctx, "example.com", &created.ID)
+	if assert.NoError(t, err) {
+		assert.Len(t, byID.Records, 1)
+	}
+
+	all, err := dns.GetRecords(ctx, "example.com", nil)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+
+	_, err = dns.DeleteRecord(ctx, "example.com", created.ID)
+	assert.NoError(t, err)
+	_, err = dns.DeleteRecord(ctx, "example.com", created.ID)
+	assertAPIError(t, err, MsgInvalidRecordID)
+
+	return all.Records
+}
+
+func TestFakeDNS(t *testing.T) {
+	fake := NewFakeDNS("Example.com.")
+	records := exerciseDNS(t, fake)
+
+	// The fake behaves like the Server
+	server := NewServer(nil)
+	defer server.Close()
+	server.AddDomain(porkbun.Domain{Domain: "example.com"})
+	assert.Equal(t, exerciseDNS(t, server.Client().Dns), records)
+
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "www.example.com", records[0].Name)
+		assert.Equal(t, "600", records[0].TTL)
+		assert.Equal(t, "example.com", records[1].Name)
+		assert.Equal(t, "mx.example.com", records[1].Content)
+		assert.Equal(t, "20", records[1].Prio)
+	}
+	assert.Len(t, fake.Records("example.com"), 1)
+
+	id, ok := fake.AddRecord("example.com", porkbun.DnsRecord{Name: "api", Type: porkbun.CNAME, Content: "example.com"})
+	assert.True(t, ok)
+	assert.NotZero(t, id)
+	_, ok = fake.AddRecord("example.org", porkbun.DnsRecord{})
+	assert.False(t, ok)
+
+	fake.Err = errors.New("boom")
+	_, err := fake.GetRecords(context.Background(), "example.com", nil)
+	assert.EqualError(t, err, "boom")
+}
+
+func TestFakeDomains(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeDomains(
+		porkbun.Domain{Domain: "example.org"},
+		porkbun.Domain{Domain: "example.com", Labels: []porkbun.Label{{ID: "1", Title: "prod"}}},
+	)
+
+	list, err := fake.ListDomains(ctx, nil)
+	if assert.NoError(t, err) && assert.Len(t, list.Domains, 2) {
+		assert.Equal(t, "example.com", list.Domains[0].Domain)
+		assert.Equal(t, "ACTIVE", list.Domains[0].Status)
+		assert.Equal(t, "com", list.Domains[0].TLD)
+		assert.Empty(t, list.Domains[0].Labels)
+	}
+	list, err = fake.ListDomains(ctx, &porkbun.DomainListOptions{Start: porkbun.String("1"), IncludeLabels: porkbun.String("yes")})
+	if assert.NoError(t, err) && assert.Len(t, list.Domains, 1) {
+		assert.Equal(t, "example.org", list.Domains[0].Domain)
+	}
+	list, _ = fake.ListDomains(ctx, &porkbun.DomainListOptions{IncludeLabels: porkbun.String("yes")})
+	assert.Len(t, list.Domains[0].Labels, 1)
+	all, err := fake.ListAllDomains(ctx, &porkbun.DomainListOptions{Start: porkbun.String("1"), IncludeLabels: porkbun.String("yes")})
+	if assert.NoError(t, err) && assert.Len(t, all, 2) {
+		assert.Len(t, all[0].Labels, 1)
+	}
+
+	ns, err := fake.GetNameServers(ctx, "example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, porkbun.NameServers(DefaultNameServers), ns.NS)
+	}
+	_, err = fake.UpdateNameServers(ctx, "example.com", &porkbun.NameServers{"NS1.Example.net."})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns1.example.net"}, fake.NameServers("example.com"))
+	_, err = fake.UpdateNameServers(ctx, "example.com", &porkbun.NameServers{})
+	assertAPIError(t, err, MsgInvalidNameServer)
+	_, err = fake.GetNameServers(ctx, "example.net")
+	assertAPIError(t, err, MsgInvalidDomain)
+
+	forward := &porkbun.UrlForward{Location: "https://example.org", Type: porkbun.Permanent, IncludePath: "no", Wildcard: "yes"}
+	_, err = fake.AddDomainUrlForward(ctx, "example.com", forward)
+	assert.NoError(t, err)
+	_, err = fake.AddDomainUrlForward(ctx, "example.com", &porkbun.UrlForward{Location: "https://example.org"})
+	assertAPIError(t, err, MsgInvalidForward)
+
+	forwards, err := fake.GetDomainURLForwarding(ctx, "example.com")
+	if assert.NoError(t, err) && assert.Len(t, forwards.Forwards, 1) {
+		assert.Equal(t, *forward, forwards.Forwards[0].UrlForward)
+		_, err = fake.DeleteDomainUrlForward(ctx, "example.com", forwards.Forwards[0].Id)
+		assert.NoError(t, err)
+	}
+	assert.Empty(t, fake.Forwards("example.com"))
+	_, err = fake.DeleteDomainUrlForward(ctx, "example.com", "1")
+	assertAPIError(t, err, MsgInvalidRecordID)
+
+	forwards, err = fake.GetDomainURLForwarding(ctx, "example.com")
+	if assert.NoError(t, err) {
+		assert.NotNil(t, forwards.Forwards)
+	}
+}
+
+func TestFakeSSL(t *testing.T) {
+	fake := NewFakeSSL()
+	fake.SetSSL("example.com", porkbun.SslRetrieveResponse{Certificatechain: "chain", Privatekey: "key", Publickey: "pub"})
+
+	resp, err := fake.Retrieve(context.Background(), "Example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "SUCCESS", resp.Status)
+		assert.Equal(t, "chain", resp.Certificatechain)
+	}
+
+	_, err = fake.Retrieve(context.Background(), "example.org")
+	assertAPIError(t, err, MsgSslNotReady)
+}
+
+func TestFakePricing(t *testing.T) {
+	fake := NewFakePricing(nil)
+
+	resp, err := fake.ListPricing(context.Background())
+	if assert.NoError(t, err) {
+		assert.Equal(t, "9.68", resp.Pricing["com"].Registration)
+	}
+
+	// Changes to the response do not leak into the fake
+	delete(resp.Pricing, "com")
+	resp, _ = fake.ListPricing(context.Background())
+	assert.Contains(t, resp.Pricing, "com")
+
+	fake.Err = errors.New("boom")
+	_, err = fake.ListPricing(context.Background())
+	assert.EqualError(t, err, "boom")
+}
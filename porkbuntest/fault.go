@@ -0,0 +1,249 @@
+package porkbuntest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// Fault is a failure injected by FaultClient.
+type Fault int
+
+// Constants representing the faults FaultClient can inject.
+const (
+	FaultNone             Fault = iota // Pass the request through unchanged
+	FaultLatency                       // Delay the request by FaultOptions.Latency, then pass it through
+	FaultConnectionError               // Fail without a response, like a refused connection
+	FaultServerError                   // Respond 500 Internal Server Error with an HTML body
+	FaultUnavailable                   // Respond 503 Service Unavailable with an HTML body
+	FaultTruncatedJSON                 // Pass the request through and cut the response body in half
+	FaultStatusError                   // Respond 200 OK with "status":"ERROR" in the body
+	FaultWrongContentType              // Pass the request through and serve the response as text/html
+)
+
+// AllFaults lists every fault except FaultNone, the default candidates for random injection.
+var AllFaults = []Fault{
+	FaultLatency,
+	FaultConnectionError,
+	FaultServerError,
+	FaultUnavailable,
+	FaultTruncatedJSON,
+	FaultStatusError,
+	FaultWrongContentType,
+}
+
+// String returns the name of the fault.
+func (f Fault) String() string {
+	switch f {
+	case FaultNone:
+		return "none"
+	case FaultLatency:
+		return "latency"
+	case FaultConnectionError:
+		return "connection_error"
+	case FaultServerError:
+		return "server_error"
+	case FaultUnavailable:
+		return "unavailable"
+	case FaultTruncatedJSON:
+		return "truncated_json"
+	case FaultStatusError:
+		return "status_error"
+	case FaultWrongContentType:
+		return "wrong_content_type"
+	}
+	return "fault(" + strconv.Itoa(int(f)) + ")"
+}
+
+// ErrInjectedConnection is the cause of errors returned for FaultConnectionError.
+var ErrInjectedConnection = errors.New("porkbuntest: injected connection error")
+
+// MsgInjectedError is the message of FaultStatusError responses.
+const MsgInjectedError = "Injected error."
+
+// DefaultLatency is the delay added by FaultLatency when FaultOptions.Latency is not set.
+const DefaultLatency = 100 * time.Millisecond
+
+const (
+	serverErrorBody = "<!DOCTYPE html>\n<html><head><title>500 Internal Server Error</title></head>" +
+		"<body><h1>Internal Server Error</h1></body></html>\n"
+	unavailableBody = "<!DOCTYPE html>\n<html><head><title>503 Service Unavailable</title></head>" +
+		"<body><h1>Service Unavailable</h1><p>The server is temporarily unable to service your request.</p></body></html>\n"
+)
+
+// FaultRule injects a fault into requests whose path matches a pattern.
+type FaultRule struct {
+	Path  *regexp.Regexp // Pattern matched against the request path, nil matches every request.
+	Fault Fault          // Fault to inject.
+	Rate  float64        // Fraction of matching requests that get the fault, 0 means all of them.
+}
+
+// FaultOptions defines the configuration options for the FaultClient.
+type FaultOptions struct {
+	Rules   []FaultRule   // Rules checked in order, the first matching rule decides the fault.
+	Seed    int64         // Seed for random decisions, the same seed gives the same faults.
+	Rate    float64       // Fraction of requests not matched by a rule that get a random fault.
+	Faults  []Fault       // Candidates for random faults, defaults to AllFaults.
+	Latency time.Duration // Delay added by FaultLatency, defaults to DefaultLatency.
+}
+
+// FaultClient is a porkbun.HTTPClient that wraps another HTTPClient and injects faults, to test
+// how code behaves when the Porkbun API misbehaves.
+//
+// Faults are chosen by path with Rules, or at random with Rate. Random choices come from a source
+// seeded with Seed, so a sequence of requests always sees the same faults:
+//
+//	faults := porkbuntest.NewFaultClient(http.DefaultClient, &porkbuntest.FaultOptions{
+//		Rules: []porkbuntest.FaultRule{
+//			{Path: regexp.MustCompile(`/dns/edit/`), Fault: porkbuntest.FaultUnavailable},
+//		},
+//	})
+//
+// Injected responses go through the usual error handling of the porkbun client: server errors
+// surface as "HTTP error 500" style errors, FaultStatusError as a response with Status "ERROR",
+// and FaultTruncatedJSON as a JSON syntax error.
+type FaultClient struct {
+	client  porkbun.HTTPClient
+	options FaultOptions
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	injected []Fault
+}
+
+// NewFaultClient creates a FaultClient sending requests through client.
+func NewFaultClient(client porkbun.HTTPClient, options *FaultOptions) *FaultClient {
+	c := &FaultClient{client: client}
+
+	if options != nil {
+		c.options = *options
+	}
+	if c.options.Faults == nil {
+		c.options.Faults = AllFaults
+	}
+	if c.options.Latency == 0 {
+		c.options.Latency = DefaultLatency
+	}
+
+	c.rand = rand.New(rand.NewSource(c.options.Seed))
+	return c
+}
+
+// Injected returns the faults injected so far, in request order. Requests left alone are not
+// included.
+func (c *FaultClient) Injected() []Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Fault(nil), c.injected...)
+}
+
+// Do implements porkbun.HTTPClient.
+func (c *FaultClient) Do(req *http.Request) (*http.Response, error) {
+	fault := c.choose(req.URL.Path)
+
+	switch fault {
+	case FaultLatency:
+		timer := time.NewTimer(c.options.Latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: req.Context().Err()}
+		}
+		return c.client.Do(req)
+
+	case FaultConnectionError:
+		return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: ErrInjectedConnection}
+
+	case FaultServerError:
+		return newResponse(req, http.StatusInternalServerError, "text/html; charset=UTF-8", serverErrorBody), nil
+
+	case FaultUnavailable:
+		resp := newResponse(req, http.StatusServiceUnavailable, "text/html; charset=UTF-8", unavailableBody)
+		resp.Header.Set("Retry-After", "30")
+		return resp, nil
+
+	case FaultStatusError:
+		return newResponse(req, http.StatusOK, "application/json", `{"status":"ERROR","message":"`+MsgInjectedError+`"}`), nil
+
+	case FaultTruncatedJSON, FaultWrongContentType:
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if fault == FaultTruncatedJSON {
+			body = body[:len(body)/2]
+		} else {
+			resp.Header.Set("Content-Type", "text/html; charset=UTF-8")
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		resp.Header.Del("Content-Length")
+		return resp, nil
+	}
+
+	return c.client.Do(req)
+}
+
+// choose decides the fault for a request to path and records it.
+func (c *FaultClient) choose(path string) Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fault := FaultNone
+	matched := false
+	for _, rule := range c.options.Rules {
+		if rule.Path != nil && !rule.Path.MatchString(path) {
+			continue
+		}
+		matched = true
+		if rule.Rate <= 0 || c.rand.Float64() < rule.Rate {
+			fault = rule.Fault
+		}
+		break
+	}
+
+	if !matched && c.options.Rate > 0 && len(c.options.Faults) > 0 && c.rand.Float64() < c.options.Rate {
+		fault = c.options.Faults[c.rand.Intn(len(c.options.Faults))]
+	}
+
+	if fault != FaultNone {
+		c.injected = append(c.injected, fault)
+	}
+	return fault
+}
+
+// newResponse builds a response to req without sending it.
+func newResponse(req *http.Request, status int, contentType, body string) *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", contentType)
+
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,139 @@
+package porkbuntest
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+func setupFaults(t *testing.T, options *FaultOptions) (*FaultClient, *porkbun.Client) {
+	server := NewServer(nil)
+	t.Cleanup(server.Close)
+	server.AddDomain(porkbun.Domain{Domain: "example.com"})
+
+	faults := NewFaultClient(http.DefaultClient, options)
+
+	var httpClient porkbun.HTTPClient = faults
+	return faults, porkbun.NewClient(&porkbun.Options{
+		ApiKey:       DefaultApiKey,
+		SecretApiKey: DefaultSecretApiKey,
+		BaseURL:      server.URL,
+		HttpClient:   &httpClient,
+	})
+}
+
+func faultRule(fault Fault) *FaultOptions {
+	return &FaultOptions{Rules: []FaultRule{{Path: regexp.MustCompile(`^/ping$`), Fault: fault}}}
+}
+
+func TestFaultClient_Faults(t *testing.T) {
+	ctx := context.Background()
+
+	_, client := setupFaults(t, faultRule(FaultConnectionError))
+	_, err := client.Ping(ctx)
+	assert.ErrorIs(t, err, ErrInjectedConnection)
+
+	for _, fault := range []Fault{FaultServerError, FaultUnavailable} {
+		_, client = setupFaults(t, faultRule(fault))
+		_, err = client.Ping(ctx)
+		if assert.Error(t, err, fault.String()) {
+			assert.Contains(t, err.Error(), "HTTP error 5", fault.String())
+		}
+	}
+
+	_, client = setupFaults(t, faultRule(FaultTruncatedJSON))
+	_, err = client.Ping(ctx)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unexpected end of JSON input")
+	}
+
+	_, client = setupFaults(t, faultRule(FaultStatusError))
+	resp, err := client.Ping(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ERROR", resp.Status)
+	}
+
+	_, client = setupFaults(t, faultRule(FaultWrongContentType))
+	resp, err = client.Ping(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "SUCCESS", resp.Status)
+		assert.Equal(t, "text/html; charset=UTF-8", resp.HTTPResponse.Header.Get("Content-Type"))
+	}
+
+	// Other paths are left alone
+	_, err = client.Dns.GetRecords(ctx, "example.com", nil)
+	assert.NoError(t, err)
+}
+
+func TestFaultClient_Latency(t *testing.T) {
+	options := faultRule(FaultLatency)
+	options.Latency = 50 * time.Millisecond
+	_, client := setupFaults(t, options)
+
+	start := time.Now()
+	_, err := client.Ping(context.Background())
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), options.Latency)
+
+	// The delay respects the request context
+	options.Latency = time.Hour
+	_, client = setupFaults(t, options)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.Ping(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFaultClient_Seed(t *testing.T) {
+	run := func(seed int64) []Fault {
+		faults, client := setupFaults(t, &FaultOptions{
+			Seed:   seed,
+			Rate:   0.5,
+			Faults: []Fault{FaultConnectionError, FaultServerError, FaultStatusError},
+		})
+		for i := 0; i < 20; i++ {
+			_, _ = client.Ping(context.Background())
+		}
+		return faults.Injected()
+	}
+
+	first := run(42)
+	assert.NotEmpty(t, first)
+	assert.Less(t, len(first), 20)
+	assert.Equal(t, first, run(42))
+	assert.NotEqual(t, first, run(7))
+}
+
+func TestFaultClient_RuleRate(t *testing.T) {
+	faults, client := setupFaults(t, &FaultOptions{
+		Rules: []FaultRule{
+			{Path: regexp.MustCompile(`^/dns/`), Fault: FaultNone},
+			{Fault: FaultUnavailable, Rate: 0.25},
+		},
+		Rate: 1,
+	})
+
+	// A matching rule takes precedence over random faults
+	_, err := client.Dns.GetRecords(context.Background(), "example.com", nil)
+	assert.NoError(t, err)
+
+	for i := 0; i < 40; i++ {
+		_, _ = client.Ping(context.Background())
+	}
+	injected := faults.Injected()
+	assert.NotEmpty(t, injected)
+	assert.Less(t, len(injected), 40)
+	for _, fault := range injected {
+		assert.Equal(t, FaultUnavailable, fault)
+	}
+}
+
+func TestFault_String(t *testing.T) {
+	assert.Equal(t, "unavailable", FaultUnavailable.String())
+	assert.Equal(t, "fault(99)", Fault(99).String())
+}
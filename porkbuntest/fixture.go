@@ -0,0 +1,53 @@
+package porkbuntest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+)
+
+// ParseFixture parses a raw HTTP response in the .http format used by the fixtures directory:
+// a status line, headers, an empty line and the body.
+func ParseFixture(data []byte) (*http.Response, error) {
+	// http.ReadResponse expects a chunked body when this header is present, but captured bodies are decoded
+	data = bytes.ReplaceAll(data, []byte("Transfer-Encoding: chunked\r\n"), nil)
+	data = bytes.ReplaceAll(data, []byte("Transfer-Encoding: chunked\n"), nil)
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("porkbuntest: parsing fixture: %w", err)
+	}
+
+	// Buffer the body so the response stays usable after the fixture data is released
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("porkbuntest: reading fixture body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+
+	return resp, nil
+}
+
+// LoadFixture reads and parses the .http file at path.
+func LoadFixture(path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("porkbuntest: %w", err)
+	}
+	return ParseFixture(data)
+}
+
+// LoadFixtureFS reads and parses the named .http file from fsys, such as fixtures.FS.
+func LoadFixtureFS(fsys fs.FS, name string) (*http.Response, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("porkbuntest: %w", err)
+	}
+	return ParseFixture(data)
+}
@@ -0,0 +1,59 @@
+package porkbuntest
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go/fixtures"
+)
+
+func TestParseFixture(t *testing.T) {
+	resp, err := ParseFixture([]byte("HTTP/1.1 400 Bad Request\nContent-Type: application/json\nTransfer-Encoding: chunked\n\n{\"status\":\"ERROR\"}"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Empty(t, resp.TransferEncoding)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, `{"status":"ERROR"}`, string(body))
+	assert.Equal(t, int64(len(body)), resp.ContentLength)
+
+	_, err = ParseFixture([]byte("not a response"))
+	assert.Error(t, err)
+}
+
+func TestLoadFixture(t *testing.T) {
+	resp, err := LoadFixture("../fixtures/ping/success.http")
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	_, err = LoadFixture("../fixtures/missing.http")
+	assert.Error(t, err)
+}
+
+func TestLoadFixtureFS(t *testing.T) {
+	// Every embedded fixture parses
+	err := fs.WalkDir(fixtures.FS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".http") {
+			return err
+		}
+		_, err = LoadFixtureFS(fixtures.FS, path)
+		assert.NoError(t, err, path)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	resp, err := LoadFixtureFS(fixtures.FS, "ssl/retrieve-error.http")
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), MsgSslNotReady)
+	}
+}
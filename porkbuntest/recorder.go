@@ -0,0 +1,310 @@
+package porkbuntest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// RecorderMode selects whether a Recorder captures or replays requests.
+type RecorderMode int
+
+// Constants representing the Recorder modes.
+const (
+	ModeRecord RecorderMode = iota // Send requests and write them with their responses to disk
+	ModeReplay                     // Answer requests from disk, failing on anything not recorded
+)
+
+// Errors returned by a Recorder in ModeReplay.
+var (
+	ErrNotRecorded     = errors.New("porkbuntest: request was not recorded")
+	ErrRequestMismatch = errors.New("porkbuntest: request does not match the recording")
+)
+
+// Redacted replaces scrubbed credentials and private keys in recordings.
+const Redacted = "REDACTED"
+
+// DefaultBasePath is the API path prefix removed from request paths to name recordings.
+const DefaultBasePath = "/api/json/v3"
+
+var (
+	credentialPattern = regexp.MustCompile(`"(apikey|secretapikey)"(\s*):(\s*)"[^"]*"`)
+	apiKeyPattern     = regexp.MustCompile(`\b(pk1|sk1)_[0-9A-Za-z]+`)
+	privateKeyPattern = regexp.MustCompile(`(?s)-----BEGIN ([A-Z ]*)PRIVATE KEY-----.*?-----END ([A-Z ]*)PRIVATE KEY-----`)
+	ipv4Pattern       = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	ipv6Pattern       = regexp.MustCompile(`(?i)\b[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}\b`)
+)
+
+// documentationNets are left untouched by IP scrubbing, along with loopback and private addresses.
+var documentationNets = []*net.IPNet{
+	mustParseCIDR("192.0.2.0/24"),
+	mustParseCIDR("198.51.100.0/24"),
+	mustParseCIDR("203.0.113.0/24"),
+	mustParseCIDR("2001:db8::/32"),
+}
+
+// RecorderOptions defines the configuration options for the Recorder.
+type RecorderOptions struct {
+	Dir      string       // Directory holding the recordings.
+	Mode     RecorderMode // Record or replay, defaults to ModeRecord.
+	BasePath string       // API path prefix stripped from recording names, defaults to DefaultBasePath.
+	Secrets  []string     // Additional values replaced by Redacted, e.g. domain-specific tokens.
+}
+
+// Recorder is a porkbun.HTTPClient that records requests and responses as .http files, or replays them.
+//
+// Each exchange is stored as two files named after the API path, for example
+// "dns/retrieve/example.com.http" for the response and "dns/retrieve/example.com.request.http"
+// for the request. Repeated requests to the same path get a sequence number, as in
+// "dns/retrieve/example.com.2.http". Response files use the format of the fixtures directory.
+//
+// Before anything is written, API keys, private keys and public IP addresses are scrubbed.
+// IP addresses are consistently replaced by documentation addresses.
+//
+// In ModeReplay the recorded request body is compared with the scrubbed body of each request,
+// so tests fail visibly when requests drift from the recording.
+type Recorder struct {
+	client  porkbun.HTTPClient
+	options RecorderOptions
+
+	mu     sync.Mutex
+	counts map[string]int // Number of requests seen per recording name
+}
+
+// NewRecorder creates a Recorder sending requests through client, which may be nil in ModeReplay.
+func NewRecorder(client porkbun.HTTPClient, options *RecorderOptions) *Recorder {
+	r := &Recorder{
+		client: client,
+		counts: make(map[string]int),
+	}
+
+	if options != nil {
+		r.options = *options
+	}
+	if r.options.BasePath == "" {
+		r.options.BasePath = DefaultBasePath
+	}
+
+	return r
+}
+
+// Do implements porkbun.HTTPClient.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	name := r.nextName(req.URL.Path)
+	r.mu.Unlock()
+	scrubbedBody := r.scrub(body)
+
+	if r.options.Mode == ModeReplay {
+		return r.replay(req, name, scrubbedBody)
+	}
+	return r.record(req, name, scrubbedBody)
+}
+
+// record sends the request and writes the exchange to disk.
+func (r *Recorder) record(req *http.Request, name string, scrubbedBody []byte) (*http.Response, error) {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	scrubbedResp := r.scrub(respBody)
+
+	path := filepath.Join(r.options.Dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("porkbuntest: %w", err)
+	}
+	if err := os.WriteFile(path+".request.http", formatRequest(req, scrubbedBody), 0o644); err != nil {
+		return nil, fmt.Errorf("porkbuntest: %w", err)
+	}
+	if err := os.WriteFile(path+".http", formatResponse(resp, scrubbedResp), 0o644); err != nil {
+		return nil, fmt.Errorf("porkbuntest: %w", err)
+	}
+
+	return resp, nil
+}
+
+// replay checks the request against the recording and returns the recorded response.
+func (r *Recorder) replay(req *http.Request, name string, scrubbedBody []byte) (*http.Response, error) {
+	path := filepath.Join(r.options.Dir, filepath.FromSlash(name))
+
+	recorded, err := os.ReadFile(path + ".request.http")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s %s (%s)", ErrNotRecorded, req.Method, req.URL.Path, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("porkbuntest: %w", err)
+	}
+
+	recordedReq, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(recorded)))
+	if err != nil {
+		return nil, fmt.Errorf("porkbuntest: parsing %s.request.http: %w", name, err)
+	}
+	recordedBody, err := io.ReadAll(recordedReq.Body)
+	if err != nil {
+		return nil, fmt.Errorf("porkbuntest: reading %s.request.http: %w", name, err)
+	}
+
+	if recordedReq.Method != req.Method || !equalBodies(recordedBody, scrubbedBody) {
+		return nil, fmt.Errorf("%w: %s %s (%s)\nrecorded: %s %s\nreceived: %s %s", ErrRequestMismatch,
+			req.Method, req.URL.Path, name, recordedReq.Method, bytes.TrimSpace(recordedBody), req.Method, bytes.TrimSpace(scrubbedBody))
+	}
+
+	data, err := os.ReadFile(path + ".http")
+	if err != nil {
+		return nil, fmt.Errorf("porkbuntest: %w", err)
+	}
+	resp, err := ParseFixture(data)
+	if err != nil {
+		return nil, err
+	}
+	resp.Request = req
+	return resp, nil
+}
+
+// nextName returns the recording name for the next request to the path.
+func (r *Recorder) nextName(path string) string {
+	name := strings.Trim(strings.TrimPrefix(path, r.options.BasePath), "/")
+	if name == "" {
+		name = "index"
+	}
+
+	r.counts[name]++
+	if n := r.counts[name]; n > 1 {
+		name += "." + strconv.Itoa(n)
+	}
+	return name
+}
+
+// scrub removes credentials, private keys, configured secrets and public IP addresses from data.
+func (r *Recorder) scrub(data []byte) []byte {
+	data = credentialPattern.ReplaceAll(data, []byte(`"$1"$2:$3"`+Redacted+`"`))
+	data = apiKeyPattern.ReplaceAll(data, []byte("${1}_"+Redacted))
+	data = privateKeyPattern.ReplaceAll(data, []byte("-----BEGIN ${1}PRIVATE KEY-----"+Redacted+"-----END ${2}PRIVATE KEY-----"))
+	for _, secret := range r.options.Secrets {
+		if secret != "" {
+			data = bytes.ReplaceAll(data, []byte(secret), []byte(Redacted))
+		}
+	}
+
+	data = ipv4Pattern.ReplaceAllFunc(data, replaceIP)
+	data = ipv6Pattern.ReplaceAllFunc(data, replaceIP)
+	return data
+}
+
+// replaceIP maps a public IP address to a documentation address, keeping anything else unchanged.
+// The replacement is derived from a hash of the address, so it is the same in every Recorder and
+// recordings stay stable when the same calls are recorded again or replayed.
+func replaceIP(match []byte) []byte {
+	ip := net.ParseIP(string(match))
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() {
+		return match
+	}
+	for _, n := range documentationNets {
+		if n.Contains(ip) {
+			return match
+		}
+	}
+
+	h := fnv.New32a()
+	h.Write(ip)
+	sum := h.Sum32()
+
+	if ip.To4() != nil {
+		// Spread over the three IPv4 documentation ranges, 254 addresses each
+		prefixes := []string{"192.0.2.", "198.51.100.", "203.0.113."}
+		n := sum % (3 * 254)
+		return []byte(prefixes[n/254] + strconv.Itoa(int(n%254)+1))
+	}
+	return []byte(fmt.Sprintf("2001:db8::%x:%x", sum>>16, sum&0xffff))
+}
+
+// readBody reads the request body and puts a copy back so it can still be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// formatRequest encodes the request line, content type and body in HTTP/1.1 format.
+func formatRequest(req *http.Request, body []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", req.Method, req.URL.Path)
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		fmt.Fprintf(&buf, "Content-Type: %s\r\n", contentType)
+	}
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n", len(body))
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// formatResponse encodes the response in the .http fixture format. Headers tied to the original
+// encoding of the body, and cookies, are left out.
+func formatResponse(resp *http.Response, body []byte) []byte {
+	header := resp.Header.Clone()
+	for _, key := range []string{"Content-Length", "Transfer-Encoding", "Content-Encoding", "Set-Cookie"} {
+		header.Del(key)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HTTP/1.1 %s\r\n", resp.Status)
+	_ = header.Write(&buf)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// equalBodies compares two request bodies, as JSON values if both are valid JSON.
+func equalBodies(a, b []byte) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) == nil && json.Unmarshal(b, &vb) == nil {
+		ja, _ := json.Marshal(va)
+		jb, _ := json.Marshal(vb)
+		return bytes.Equal(ja, jb)
+	}
+	return bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b))
+}
+
+// mustParseCIDR parses a CIDR constant.
+func mustParseCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
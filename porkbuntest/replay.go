@@ -0,0 +1,102 @@
+package porkbuntest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNoFixture is returned by ReplayClient when no fixture is registered for the request path.
+var ErrNoFixture = errors.New("porkbuntest: no fixture for request")
+
+// ReplayClient is a porkbun.HTTPClient that answers requests with registered .http fixtures,
+// keyed by request path. A path is either the full request path or an API path such as
+// "/dns/retrieve/example.com", which matches regardless of the client's base URL.
+//
+//	replay := porkbuntest.NewReplayClient()
+//	replay.AddFS(fixtures.FS, "/dns/retrieve/example.com", "dns/retrieveByDomain/success.http")
+//
+//	var httpClient porkbun.HTTPClient = replay
+//	client := porkbun.NewClient(&porkbun.Options{HttpClient: &httpClient})
+type ReplayClient struct {
+	mu       sync.Mutex
+	fixtures map[string][]byte
+}
+
+// NewReplayClient creates a ReplayClient without fixtures.
+func NewReplayClient() *ReplayClient {
+	return &ReplayClient{fixtures: make(map[string][]byte)}
+}
+
+// Add registers the raw .http fixture for the request path.
+func (c *ReplayClient) Add(path string, fixture []byte) error {
+	if _, err := ParseFixture(fixture); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fixtures["/"+strings.Trim(path, "/")] = fixture
+	return nil
+}
+
+// AddFile registers the .http file at filename for the request path.
+func (c *ReplayClient) AddFile(path, filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("porkbuntest: %w", err)
+	}
+	return c.Add(path, data)
+}
+
+// AddFS registers the named .http file from fsys for the request path.
+func (c *ReplayClient) AddFS(fsys fs.FS, path, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("porkbuntest: %w", err)
+	}
+	return c.Add(path, data)
+}
+
+// Do implements porkbun.HTTPClient, returning a fresh copy of the fixture registered for the path.
+func (c *ReplayClient) Do(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	fixture, ok := c.lookup(req.URL.Path)
+	c.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoFixture, req.Method, req.URL.Path)
+	}
+
+	resp, err := ParseFixture(fixture)
+	if err != nil {
+		return nil, err
+	}
+	resp.Request = req
+	return resp, nil
+}
+
+// lookup returns the fixture for the exact path, or for the longest registered path that is a
+// trailing sequence of its segments.
+func (c *ReplayClient) lookup(path string) ([]byte, bool) {
+	path = "/" + strings.Trim(path, "/")
+	if fixture, ok := c.fixtures[path]; ok {
+		return fixture, true
+	}
+
+	var best string
+	for key := range c.fixtures {
+		if strings.HasSuffix(path, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return nil, false
+	}
+	return c.fixtures[best], true
+}
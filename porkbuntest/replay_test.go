@@ -0,0 +1,70 @@
+package porkbuntest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/fixtures"
+)
+
+func setupReplay(t *testing.T) (*ReplayClient, *porkbun.Client) {
+	replay := NewReplayClient()
+
+	var httpClient porkbun.HTTPClient = replay
+	return replay, porkbun.NewClient(&porkbun.Options{HttpClient: &httpClient})
+}
+
+func TestReplayClient(t *testing.T) {
+	replay, client := setupReplay(t)
+	assert.NoError(t, replay.AddFS(fixtures.FS, "/dns/retrieve/example.com", "dns/retrieveByDomain/success.http"))
+	assert.NoError(t, replay.AddFile("/ping", "../fixtures/ping/success.http"))
+	assert.NoError(t, replay.AddFS(fixtures.FS, "domain/getNs/example.org/", "domains/getNameServers-error.http"))
+
+	// Responses can be replayed any number of times
+	for i := 0; i < 2; i++ {
+		resp, err := client.Dns.GetRecords(context.Background(), "example.com", nil)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, resp.Records)
+		assert.Equal(t, int64(12345), *resp.Records[0].ID)
+	}
+
+	ping, err := client.Ping(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "2404:4400:5401:d900:6b19:e84:33cb:cd66", ping.YourIP)
+
+	_, err = client.Domains.GetNameServers(context.Background(), "example.org")
+	var apiErr *porkbun.ErrorResponse
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, MsgInvalidDomain, apiErr.Message)
+		assert.Contains(t, apiErr.Error(), "/api/json/v3/domain/getNs/example.org")
+	}
+}
+
+func TestReplayClient_FullPath(t *testing.T) {
+	replay, client := setupReplay(t)
+	assert.NoError(t, replay.AddFS(fixtures.FS, "/api/json/v3/ping", "ping/success.http"))
+	assert.NoError(t, replay.AddFS(fixtures.FS, "/ping", "ping/noauth.http"))
+
+	// The most specific path wins
+	_, err := client.Ping(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestReplayClient_Errors(t *testing.T) {
+	replay, client := setupReplay(t)
+
+	_, err := client.Ping(context.Background())
+	assert.ErrorIs(t, err, ErrNoFixture)
+
+	assert.Error(t, replay.Add("/ping", []byte("garbage")))
+	assert.Error(t, replay.AddFile("/ping", "../fixtures/missing.http"))
+	assert.Error(t, replay.AddFS(fixtures.FS, "/ping", "missing.http"))
+
+	// Segments only match whole
+	assert.NoError(t, replay.AddFS(fixtures.FS, "/ng", "ping/success.http"))
+	_, err = client.Ping(context.Background())
+	assert.ErrorIs(t, err, ErrNoFixture)
+}
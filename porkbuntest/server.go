@@ -0,0 +1,382 @@
+// Package porkbuntest provides test helpers for code using the porkbun package: a stateful,
+// in-memory fake of the Porkbun API, and HTTP clients that replay captured .http fixtures or record new ones.
+//
+// The Server keeps domains, DNS records, name servers, URL forwards and SSL bundles in memory and
+// implements every endpoint called by the porkbun package, including the API's error messages and
+// status codes:
+//
+//	server := porkbuntest.NewServer(nil)
+//	defer server.Close()
+//
+//	server.AddDomain(porkbun.Domain{Domain: "example.com"})
+//	client := server.Client()
+//
+// Code written against the porkbun.DNSAPI, DomainsAPI, SSLAPI and PricingAPI interfaces can use
+// the in-memory FakeDNS, FakeDomains, FakeSSL and FakePricing instead, without any HTTP.
+package porkbuntest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// Default credentials accepted by the Server.
+const (
+	DefaultApiKey       = "pk1_porkbuntest"
+	DefaultSecretApiKey = "sk1_porkbuntest"
+)
+
+// Error messages returned by the Server, matching the ones of the Porkbun API.
+const (
+	MsgInvalidApiKey     = "Invalid API key. (002)"
+	MsgInvalidDomain     = "Invalid domain."
+	MsgInvalidRecordType = "Invalid DNS record type"
+	MsgInvalidRecordID   = "Invalid record ID."
+	MsgInvalidContent    = "Invalid content."
+	MsgInvalidNameServer = "invalid name server list"
+	MsgInvalidForward    = "Invalid forward."
+	MsgSslNotReady       = "The SSL certificate is not ready for this domain."
+	MsgInvalidEndpoint   = "Invalid endpoint."
+	MsgInvalidMethod     = "Invalid method."
+	MsgInvalidBody       = "Invalid JSON body."
+)
+
+// Limits applied by the Server, matching the ones of the Porkbun API.
+const (
+	MinTTL           = 600  // Records created or edited with a lower TTL get this TTL
+	DomainsPageSize  = 1000 // Number of domains returned per listAll page
+	dateFormat       = "2006-01-02 15:04:05"
+	defaultYourIP    = "127.0.0.1"
+	defaultDomainTLD = "com"
+)
+
+// DefaultNameServers are assigned to domains added without name servers.
+var DefaultNameServers = []string{
+	"curitiba.ns.porkbun.com",
+	"fortaleza.ns.porkbun.com",
+	"maceio.ns.porkbun.com",
+	"salvador.ns.porkbun.com",
+}
+
+// Options defines the configuration options for the Server.
+type Options struct {
+	ApiKey       string                     // Accepted API key, defaults to DefaultApiKey.
+	SecretApiKey string                     // Accepted secret API key, defaults to DefaultSecretApiKey.
+	YourIP       string                     // Address returned by ping, defaults to 127.0.0.1.
+	Pricing      map[string]porkbun.Pricing // Pricing returned by pricing/get, a small default table if nil.
+}
+
+// domainState holds everything the Server knows about a domain.
+type domainState struct {
+	domain      porkbun.Domain
+	records     []porkbun.DnsRecord // Names are relative to the domain, "" being the apex
+	nameServers []string
+	forwards    []porkbun.UrlForwardData
+	ssl         *porkbun.SslRetrieveResponse
+}
+
+// Server is a fake Porkbun API server backed by httptest.
+type Server struct {
+	URL string // Base URL of the server, to be used as porkbun.Options.BaseURL
+
+	server  *httptest.Server
+	options Options
+
+	mu            sync.Mutex
+	domains       map[string]*domainState
+	nextRecordID  int64
+	nextForwardID int64
+}
+
+// NewServer starts a new Server using the provided options. Close must be called when done.
+func NewServer(options *Options) *Server {
+	s := NewUnstartedServer(options)
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer returns a Server that is not listening yet, to be used as an http.Handler
+// or started later with Start.
+func NewUnstartedServer(options *Options) *Server {
+	s := &Server{
+		domains:       make(map[string]*domainState),
+		nextRecordID:  100000001,
+		nextForwardID: 20000001,
+	}
+
+	if options != nil {
+		s.options = *options
+	}
+	if s.options.ApiKey == "" {
+		s.options.ApiKey = DefaultApiKey
+	}
+	if s.options.SecretApiKey == "" {
+		s.options.SecretApiKey = DefaultSecretApiKey
+	}
+	if s.options.YourIP == "" {
+		s.options.YourIP = defaultYourIP
+	}
+	if s.options.Pricing == nil {
+		s.options.Pricing = defaultPricing()
+	}
+
+	return s
+}
+
+// defaultPricing returns the pricing table used when none is configured.
+func defaultPricing() map[string]porkbun.Pricing {
+	return map[string]porkbun.Pricing{
+		"com": {Registration: "9.68", Renewal: "10.37", Transfer: "9.68"},
+		"net": {Registration: "11.48", Renewal: "12.52", Transfer: "11.48"},
+		"org": {Registration: "6.88", Renewal: "10.74", Transfer: "6.88"},
+	}
+}
+
+// Start starts listening on a local address.
+func (s *Server) Start() {
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+// Client returns a porkbun.Client configured with the server's URL and credentials.
+func (s *Server) Client() *porkbun.Client {
+	return porkbun.NewClient(&porkbun.Options{
+		ApiKey:       s.options.ApiKey,
+		SecretApiKey: s.options.SecretApiKey,
+		BaseURL:      s.URL,
+	})
+}
+
+// SetYourIP changes the address returned by ping, as when the client's public address changes.
+func (s *Server) SetYourIP(ip string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.options.YourIP = ip
+}
+
+// AddDomain adds a domain to the account. Missing fields are filled in: the status defaults to
+// ACTIVE, the TLD is derived from the name and the domain expires a year after its creation.
+func (s *Server) AddDomain(domain porkbun.Domain) {
+	domain = withDomainDefaults(domain)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.domains[domain.Domain] = &domainState{
+		domain:      domain,
+		nameServers: append([]string(nil), DefaultNameServers...),
+	}
+}
+
+// UpdateDomain replaces the details of an existing domain, such as its status or expiry date,
+// keeping its records, name servers and URL forwards. It reports false if the domain does not exist.
+func (s *Server) UpdateDomain(domain porkbun.Domain) bool {
+	domain = withDomainDefaults(domain)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.domains[domain.Domain]
+	if !ok {
+		return false
+	}
+	state.domain = domain
+	return true
+}
+
+// RemoveDomain removes a domain from the account, as when it is transferred away. It reports false
+// if the domain does not exist.
+func (s *Server) RemoveDomain(domain string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := porkbun.NormalizeName(domain)
+	if _, ok := s.domains[name]; !ok {
+		return false
+	}
+	delete(s.domains, name)
+	return true
+}
+
+// withDomainDefaults normalizes the domain name and fills in missing fields as described by AddDomain.
+func withDomainDefaults(domain porkbun.Domain) porkbun.Domain {
+	domain.Domain = porkbun.NormalizeName(domain.Domain)
+	if domain.Status == "" {
+		domain.Status = "ACTIVE"
+	}
+	if domain.TLD == "" {
+		domain.TLD = defaultDomainTLD
+		if i := strings.Index(domain.Domain, "."); i >= 0 {
+			domain.TLD = domain.Domain[i+1:]
+		}
+	}
+	if domain.CreateDate.IsZero() {
+		domain.CreateDate = time.Now().UTC().Truncate(time.Second)
+	}
+	if domain.ExpireDate.IsZero() {
+		domain.ExpireDate = domain.CreateDate.AddDate(1, 0, 0)
+	}
+	return domain
+}
+
+// Domains returns the domains of the account, sorted by name.
+func (s *Server) Domains() []porkbun.Domain {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	domains := make([]porkbun.Domain, 0, len(s.domains))
+	for _, state := range s.domains {
+		domains = append(domains, state.domain)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Domain < domains[j].Domain })
+	return domains
+}
+
+// SetSSL sets the SSL bundle returned for the domain. It reports false if the domain does not exist.
+func (s *Server) SetSSL(domain string, bundle porkbun.SslRetrieveResponse) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.domains[porkbun.NormalizeName(domain)]
+	if state == nil {
+		return false
+	}
+	state.ssl = &porkbun.SslRetrieveResponse{
+		Certificatechain: bundle.Certificatechain,
+		Privatekey:       bundle.Privatekey,
+		Publickey:        bundle.Publickey,
+	}
+	return true
+}
+
+// ServeHTTP implements http.Handler, dispatching the request to the endpoint handlers.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, MsgInvalidMethod)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	// Pricing is the only endpoint that does not require credentials
+	if len(segments) == 2 && segments[0] == "pricing" && segments[1] == "get" {
+		writeJSON(w, map[string]any{"status": "SUCCESS", "pricing": s.options.Pricing})
+		return
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, MsgInvalidBody)
+		return
+	}
+	if stringField(body, "apikey") != s.options.ApiKey || stringField(body, "secretapikey") != s.options.SecretApiKey {
+		writeError(w, http.StatusBadRequest, MsgInvalidApiKey)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch segments[0] {
+	case "ping":
+		if len(segments) == 1 {
+			writeJSON(w, map[string]any{"status": "SUCCESS", "yourIp": s.options.YourIP})
+			return
+		}
+	case "dns":
+		if len(segments) >= 3 {
+			s.serveDns(w, segments[1], segments[2], segments[3:], body)
+			return
+		}
+	case "domain":
+		if len(segments) >= 2 {
+			s.serveDomain(w, segments[1], segments[2:], body)
+			return
+		}
+	case "ssl":
+		if len(segments) == 3 && segments[1] == "retrieve" {
+			s.retrieveSsl(w, segments[2])
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, MsgInvalidEndpoint)
+}
+
+// retrieveSsl handles ssl/retrieve/{domain}.
+func (s *Server) retrieveSsl(w http.ResponseWriter, domain string) {
+	state := s.find(w, domain)
+	if state == nil {
+		return
+	}
+	if state.ssl == nil {
+		writeError(w, http.StatusBadRequest, MsgSslNotReady)
+		return
+	}
+	writeJSON(w, map[string]string{
+		"status":           "SUCCESS",
+		"certificatechain": state.ssl.Certificatechain,
+		"privatekey":       state.ssl.Privatekey,
+		"publickey":        state.ssl.Publickey,
+	})
+}
+
+// find returns the state of the domain, writing an error response if it does not exist.
+func (s *Server) find(w http.ResponseWriter, domain string) *domainState {
+	state := s.domains[porkbun.NormalizeName(domain)]
+	if state == nil {
+		writeError(w, http.StatusBadRequest, MsgInvalidDomain)
+	}
+	return state
+}
+
+// writeJSON writes a successful JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an API error response with the given status code.
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ERROR", "message": message})
+}
+
+// writeSuccess writes an API response without data.
+func writeSuccess(w http.ResponseWriter) {
+	writeJSON(w, map[string]string{"status": "SUCCESS"})
+}
+
+// stringField returns a string or number field of the request body as a string, or "" if it is missing.
+func stringField(body map[string]json.RawMessage, key string) string {
+	raw, ok := body[key]
+	if !ok {
+		return ""
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value
+	}
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number.String()
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package porkbuntest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/internal/testcert"
+)
+
+func setupServer(t *testing.T) (*Server, *porkbun.Client) {
+	server := NewServer(nil)
+	t.Cleanup(server.Close)
+
+	server.AddDomain(porkbun.Domain{Domain: "example.com"})
+	return server, server.Client()
+}
+
+// apiError returns the API error message and status code of the error.
+func apiError(t *testing.T, err error) (string, int) {
+	var errResp *porkbun.ErrorResponse
+	if !assert.True(t, errors.As(err, &errResp), "expected an ErrorResponse, got %v", err) {
+		return "", 0
+	}
+	return errResp.Message, errResp.HTTPResponse.StatusCode
+}
+
+func TestServer_Ping(t *testing.T) {
+	server := NewServer(&Options{YourIP: "203.0.113.7"})
+	defer server.Close()
+
+	resp, err := server.Client().Ping(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "SUCCESS", resp.Status)
+	assert.Equal(t, "203.0.113.7", resp.YourIP)
+
+	server.SetYourIP("2001:db8::7")
+	resp, err = server.Client().Ping(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::7", resp.YourIP)
+}
+
+func TestServer_InvalidCredentials(t *testing.T) {
+	server := NewServer(nil)
+	defer server.Close()
+
+	client := porkbun.NewClient(&porkbun.Options{ApiKey: "pk1_wrong", SecretApiKey: "sk1_wrong", BaseURL: server.URL})
+	_, err := client.Ping(context.Background())
+
+	message, code := apiError(t, err)
+	assert.Equal(t, MsgInvalidApiKey, message)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestServer_Pricing(t *testing.T) {
+	server := NewServer(&Options{Pricing: map[string]porkbun.Pricing{"dev": {Registration: "10.81", Renewal: "10.81", Transfer: "10.81"}}})
+	defer server.Close()
+
+	// Pricing does not need credentials
+	client := porkbun.NewClient(&porkbun.Options{BaseURL: server.URL})
+	resp, err := client.Pricing.ListPricing(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]porkbun.Pricing{"dev": {Registration: "10.81", Renewal: "10.81", Transfer: "10.81"}}, resp.Pricing)
+}
+
+func TestServer_Ssl(t *testing.T) {
+	server, client := setupServer(t)
+
+	_, err := client.Ssl.Retrieve(context.Background(), "example.com")
+	message, code := apiError(t, err)
+	assert.Equal(t, MsgSslNotReady, message)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	bundle, err := testcert.Generate([]string{"example.com"}, time.Now(), time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, server.SetSSL("example.com", porkbun.SslRetrieveResponse{
+		Certificatechain: bundle.CertificateChain,
+		Privatekey:       bundle.PrivateKey,
+		Publickey:        bundle.PublicKey,
+	}))
+	assert.False(t, server.SetSSL("example.org", porkbun.SslRetrieveResponse{}))
+
+	resp, err := client.Ssl.Retrieve(context.Background(), "example.com")
+	assert.NoError(t, err)
+	leaf, err := resp.Leaf()
+	assert.NoError(t, err)
+	assert.True(t, leaf.Equal(bundle.Leaf))
+
+	_, err = client.Ssl.Retrieve(context.Background(), "example.org")
+	message, _ = apiError(t, err)
+	assert.Equal(t, MsgInvalidDomain, message)
+}
+
+func TestServer_InvalidRequests(t *testing.T) {
+	server := NewUnstartedServer(nil)
+
+	for _, tc := range []struct {
+		method, path, body string
+		code               int
+		message            string
+	}{
+		{http.MethodGet, "/ping", "", http.StatusMethodNotAllowed, MsgInvalidMethod},
+		{http.MethodPost, "/ping", "not json", http.StatusBadRequest, MsgInvalidBody},
+		{http.MethodPost, "/unknown", `{"apikey":"pk1_porkbuntest","secretapikey":"sk1_porkbuntest"}`, http.StatusNotFound, MsgInvalidEndpoint},
+		{http.MethodPost, "/dns/unknown/example.com", `{"apikey":"pk1_porkbuntest","secretapikey":"sk1_porkbuntest"}`, http.StatusNotFound, MsgInvalidEndpoint},
+	} {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body)))
+
+		var body map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, tc.code, w.Code, tc.path)
+		assert.Equal(t, map[string]string{"status": "ERROR", "message": tc.message}, body, tc.path)
+	}
+}
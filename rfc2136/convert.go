@@ -0,0 +1,86 @@
+package rfc2136
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// recordTypes maps the DNS record types supported by Porkbun to their wire types.
+var recordTypes = map[uint16]porkbun.DnsRecordType{
+	dns.TypeA:     porkbun.A,
+	dns.TypeAAAA:  porkbun.AAAA,
+	dns.TypeCNAME: porkbun.CNAME,
+	dns.TypeMX:    porkbun.MX,
+	dns.TypeTXT:   porkbun.TXT,
+	dns.TypeNS:    porkbun.NS,
+	dns.TypeSRV:   porkbun.SRV,
+	dns.TypeCAA:   porkbun.CAA,
+	dns.TypeTLSA:  porkbun.TLSA,
+}
+
+// hostTypes are the record types whose content is a host name, compared without case. The content
+// of the other types, such as TXT, CAA and TLSA, is case-sensitive.
+var hostTypes = map[porkbun.DnsRecordType]bool{
+	porkbun.CNAME: true,
+	porkbun.ALIAS: true,
+	porkbun.MX:    true,
+	porkbun.NS:    true,
+	porkbun.SRV:   true,
+}
+
+// recordValue is the Porkbun representation of a single resource record's data.
+type recordValue struct {
+	Content string
+	Prio    string
+}
+
+// matches reports whether the Porkbun record holds the same data.
+func (v recordValue) matches(record porkbun.DnsRecord) bool {
+	if v.Prio != "" && record.Prio != v.Prio {
+		return false
+	}
+	if hostTypes[record.Type] {
+		return strings.EqualFold(strings.TrimSuffix(record.Content, "."), v.Content)
+	}
+	return record.Content == v.Content
+}
+
+// rrValue converts the data of a resource record into Porkbun content and priority.
+func rrValue(rr dns.RR) (recordValue, error) {
+	switch rr := rr.(type) {
+	case *dns.A:
+		return recordValue{Content: rr.A.String()}, nil
+	case *dns.AAAA:
+		return recordValue{Content: rr.AAAA.String()}, nil
+	case *dns.CNAME:
+		return recordValue{Content: porkbun.NormalizeName(rr.Target)}, nil
+	case *dns.NS:
+		return recordValue{Content: porkbun.NormalizeName(rr.Ns)}, nil
+	case *dns.MX:
+		return recordValue{Content: porkbun.NormalizeName(rr.Mx), Prio: strconv.Itoa(int(rr.Preference))}, nil
+	case *dns.TXT:
+		return recordValue{Content: strings.Join(rr.Txt, "")}, nil
+	case *dns.SRV:
+		return recordValue{
+			Content: fmt.Sprintf("%d %d %s", rr.Weight, rr.Port, porkbun.NormalizeName(rr.Target)),
+			Prio:    strconv.Itoa(int(rr.Priority)),
+		}, nil
+	case *dns.CAA:
+		return recordValue{Content: fmt.Sprintf("%d %s %q", rr.Flag, rr.Tag, rr.Value)}, nil
+	case *dns.TLSA:
+		return recordValue{Content: fmt.Sprintf("%d %d %d %s", rr.Usage, rr.Selector, rr.MatchingType, rr.Certificate)}, nil
+	}
+	return recordValue{}, fmt.Errorf("unsupported record type %s", dns.TypeToString[rr.Header().Rrtype])
+}
+
+// subdomainPtr returns the relative owner name as expected by the by-type DNS methods.
+func subdomainPtr(name, zone string) *string {
+	if sub := porkbun.RelativeName(name, zone); sub != "" {
+		return porkbun.String(sub)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package rfc2136
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+func TestRRValue(t *testing.T) {
+	tests := []struct {
+		rr    string
+		value recordValue
+	}{
+		{"a.example.com. 600 IN A 192.0.2.1", recordValue{Content: "192.0.2.1"}},
+		{"a.example.com. 600 IN AAAA 2001:db8::1", recordValue{Content: "2001:db8::1"}},
+		{"a.example.com. 600 IN CNAME Target.Example.com.", recordValue{Content: "target.example.com"}},
+		{"a.example.com. 600 IN NS ns1.example.net.", recordValue{Content: "ns1.example.net"}},
+		{"a.example.com. 600 IN MX 5 mail.example.com.", recordValue{Content: "mail.example.com", Prio: "5"}},
+		{`a.example.com. 600 IN TXT "part1" "part2"`, recordValue{Content: "part1part2"}},
+		{"_sip._tcp.example.com. 600 IN SRV 10 20 5060 sip.example.com.", recordValue{Content: "20 5060 sip.example.com", Prio: "10"}},
+		{`a.example.com. 600 IN CAA 0 issue "letsencrypt.org"`, recordValue{Content: `0 issue "letsencrypt.org"`}},
+	}
+
+	for _, tt := range tests {
+		rr, err := dns.NewRR(tt.rr)
+		assert.NoError(t, err)
+
+		value, err := rrValue(rr)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.value, value, tt.rr)
+	}
+
+	rr, _ := dns.NewRR("1.2.0.192.in-addr.arpa. 600 IN PTR host.example.com.")
+	_, err := rrValue(rr)
+	assert.Error(t, err)
+}
+
+func TestRecordValue_Matches(t *testing.T) {
+	value := recordValue{Content: "mail.example.com", Prio: "10"}
+
+	assert.True(t, value.matches(porkbun.DnsRecord{Type: porkbun.MX, Content: "Mail.example.com.", Prio: "10"}))
+	assert.False(t, value.matches(porkbun.DnsRecord{Type: porkbun.MX, Content: "mail.example.com", Prio: "20"}))
+	assert.True(t, recordValue{Content: "10.0.0.1"}.matches(porkbun.DnsRecord{Type: porkbun.A, Content: "10.0.0.1"}))
+
+	// TXT content is case-sensitive
+	txt := recordValue{Content: "Foo"}
+	assert.True(t, txt.matches(porkbun.DnsRecord{Type: porkbun.TXT, Content: "Foo"}))
+	assert.False(t, txt.matches(porkbun.DnsRecord{Type: porkbun.TXT, Content: "foo"}))
+}
+
+func TestSubdomainPtr(t *testing.T) {
+	assert.Nil(t, subdomainPtr("example.com.", "example.com."))
+	assert.Equal(t, "www", *subdomainPtr("WWW.example.com.", "example.com."))
+	assert.Equal(t, "a.b", *subdomainPtr("a.b.example.com.", "example.com."))
+}
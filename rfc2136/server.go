@@ -0,0 +1,498 @@
+// Package rfc2136 implements a DNS server that accepts RFC 2136 dynamic UPDATE messages,
+// authenticated with TSIG, and applies them to Porkbun DNS records.
+//
+// Additions are translated into DnsService.CreateRecord calls and RRset deletions into
+// DnsService.DeleteRecordByType calls. The server also answers SOA queries for its zones,
+// which update clients such as nsupdate use to locate the primary server.
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+const (
+	// DefaultTimeout is the default time allowed for the API calls of a single UPDATE message.
+	DefaultTimeout = 30 * time.Second
+
+	// minTTL is the lowest TTL accepted by Porkbun.
+	minTTL = 600
+	// soaTTL is the TTL of the synthesized SOA record.
+	soaTTL = 3600
+)
+
+// errUpdate carries the RCODE to return when an UPDATE message cannot be applied.
+type errUpdate int
+
+// Error implements the error interface for errUpdate.
+func (e errUpdate) Error() string {
+	return dns.RcodeToString[int(e)]
+}
+
+// Options defines the configuration options for the UPDATE Server.
+type Options struct {
+	Zones      []string          // Porkbun domains that accept updates.
+	TsigKeys   map[string]string // TSIG key names mapped to their base64 encoded secrets.
+	PrimaryNS  string            // MNAME of the synthesized SOA record, defaults to "localhost.".
+	Hostmaster string            // RNAME of the synthesized SOA record, defaults to "hostmaster.<zone>".
+	Timeout    time.Duration     // Time allowed for the API calls of an UPDATE, defaults to DefaultTimeout.
+}
+
+// Server handles DNS UPDATE and SOA query messages for the configured zones.
+type Server struct {
+	api     porkbun.DNSAPI
+	options Options
+	zones   map[string]bool
+
+	// mu serialises updates so prerequisites and changes are applied atomically per server.
+	mu     sync.Mutex
+	serial atomic.Uint32
+}
+
+// NewServer initializes a new UPDATE Server using the provided DNS API, usually client.Dns, and options.
+func NewServer(api porkbun.DNSAPI, options *Options) *Server {
+	s := &Server{
+		api:   api,
+		zones: make(map[string]bool),
+	}
+	s.serial.Store(uint32(time.Now().Unix()))
+
+	if options != nil {
+		s.options = *options
+	}
+	if s.options.PrimaryNS == "" {
+		s.options.PrimaryNS = "localhost."
+	}
+	if s.options.Timeout <= 0 {
+		s.options.Timeout = DefaultTimeout
+	}
+
+	for _, zone := range s.options.Zones {
+		s.zones[dns.CanonicalName(zone)] = true
+	}
+
+	keys := make(map[string]string, len(s.options.TsigKeys))
+	for name, secret := range s.options.TsigKeys {
+		keys[dns.CanonicalName(name)] = secret
+	}
+	s.options.TsigKeys = keys
+
+	return s
+}
+
+// DNSServer returns a dns.Server for the network ("udp" or "tcp") that is configured with the
+// server's TSIG keys and handler. The caller sets Addr, PacketConn or Listener and starts it.
+func (s *Server) DNSServer(network string) *dns.Server {
+	return &dns.Server{
+		Net:           network,
+		Handler:       s,
+		TsigSecret:    s.options.TsigKeys,
+		MsgAcceptFunc: acceptMsg,
+	}
+}
+
+// acceptMsg accepts queries and UPDATE messages, which the default dns.MsgAcceptFunc rejects.
+func acceptMsg(dh dns.Header) dns.MsgAcceptAction {
+	const qr = 1 << 15 // Query/Response flag
+
+	if dh.Bits&qr != 0 {
+		return dns.MsgIgnore
+	}
+
+	opcode := int(dh.Bits>>11) & 0xF
+	if opcode == dns.OpcodeUpdate {
+		if dh.Qdcount != 1 {
+			return dns.MsgReject
+		}
+		return dns.MsgAccept
+	}
+	return dns.DefaultMsgAcceptFunc(dh)
+}
+
+// ServeDNS implements the dns.Handler interface.
+func (s *Server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+
+	switch r.Opcode {
+	case dns.OpcodeQuery:
+		s.handleQuery(m, r)
+	case dns.OpcodeUpdate:
+		m.SetReply(r)
+		m.Rcode = s.handleUpdate(w, r)
+	default:
+		m.SetRcode(r, dns.RcodeNotImplemented)
+	}
+
+	// Sign the response with the same key when the request was successfully authenticated
+	if t := r.IsTsig(); t != nil && w.TsigStatus() == nil {
+		m.SetTsig(t.Hdr.Name, t.Algorithm, 300, time.Now().Unix())
+	}
+
+	_ = w.WriteMsg(m)
+}
+
+// handleQuery answers SOA queries for names inside the configured zones.
+func (s *Server) handleQuery(m *dns.Msg, r *dns.Msg) {
+	if len(r.Question) != 1 {
+		m.SetRcode(r, dns.RcodeFormatError)
+		return
+	}
+
+	q := r.Question[0]
+	zone := s.findZone(q.Name)
+	if zone == "" || q.Qclass != dns.ClassINET || q.Qtype != dns.TypeSOA {
+		m.SetRcode(r, dns.RcodeRefused)
+		return
+	}
+
+	m.SetReply(r)
+	m.Authoritative = true
+
+	soa := s.soa(zone)
+	if dns.CanonicalName(q.Name) == zone {
+		m.Answer = append(m.Answer, soa)
+	} else {
+		m.Ns = append(m.Ns, soa)
+	}
+}
+
+// handleUpdate authenticates and applies an UPDATE message, returning the response RCODE.
+func (s *Server) handleUpdate(w dns.ResponseWriter, r *dns.Msg) int {
+	if len(r.Question) != 1 || r.Question[0].Qtype != dns.TypeSOA {
+		return dns.RcodeFormatError
+	}
+
+	zone := dns.CanonicalName(r.Question[0].Name)
+	if !s.zones[zone] || r.Question[0].Qclass != dns.ClassINET {
+		return dns.RcodeNotAuth
+	}
+
+	if r.IsTsig() == nil {
+		return dns.RcodeRefused
+	}
+	if w.TsigStatus() != nil {
+		return dns.RcodeNotAuth
+	}
+
+	if err := prescan(zone, r.Ns); err != nil {
+		return rcode(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.Timeout)
+	defer cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkPrerequisites(ctx, zone, r.Answer); err != nil {
+		return rcode(err)
+	}
+
+	for _, rr := range r.Ns {
+		if err := s.applyUpdate(ctx, zone, rr); err != nil {
+			return rcode(err)
+		}
+	}
+
+	if len(r.Ns) > 0 {
+		s.serial.Add(1)
+	}
+	return dns.RcodeSuccess
+}
+
+// prescan validates the update section before any change is made (RFC 2136 section 3.4.1).
+func prescan(zone string, updates []dns.RR) error {
+	for _, rr := range updates {
+		h := rr.Header()
+		if !dns.IsSubDomain(zone, dns.CanonicalName(h.Name)) {
+			return errUpdate(dns.RcodeNotZone)
+		}
+
+		switch h.Class {
+		case dns.ClassINET:
+			if h.Rrtype == dns.TypeANY || h.Rrtype == dns.TypeSOA {
+				return errUpdate(dns.RcodeFormatError)
+			}
+			if _, ok := recordTypes[h.Rrtype]; !ok {
+				return errUpdate(dns.RcodeNotImplemented)
+			}
+		case dns.ClassANY:
+			if h.Ttl != 0 || h.Rdlength != 0 {
+				return errUpdate(dns.RcodeFormatError)
+			}
+		case dns.ClassNONE:
+			if h.Ttl != 0 || h.Rrtype == dns.TypeANY {
+				return errUpdate(dns.RcodeFormatError)
+			}
+		default:
+			return errUpdate(dns.RcodeFormatError)
+		}
+	}
+	return nil
+}
+
+// checkPrerequisites evaluates the prerequisite section (RFC 2136 section 3.2).
+func (s *Server) checkPrerequisites(ctx context.Context, zone string, prereqs []dns.RR) error {
+	if len(prereqs) == 0 {
+		return nil
+	}
+
+	records, err := s.api.GetRecords(ctx, porkbun.NormalizeName(zone), nil)
+	if err != nil {
+		return err
+	}
+
+	type rrset struct {
+		name  string
+		rtype uint16
+	}
+	required := make(map[rrset][]recordValue)
+
+	for _, rr := range prereqs {
+		h := rr.Header()
+		name := dns.CanonicalName(h.Name)
+		if h.Ttl != 0 {
+			return errUpdate(dns.RcodeFormatError)
+		}
+		if !dns.IsSubDomain(zone, name) {
+			return errUpdate(dns.RcodeNotZone)
+		}
+
+		existing := filterRecords(records.Records, name, h.Rrtype)
+
+		switch h.Class {
+		case dns.ClassANY:
+			if h.Rdlength != 0 {
+				return errUpdate(dns.RcodeFormatError)
+			}
+			if len(existing) == 0 {
+				if h.Rrtype == dns.TypeANY {
+					return errUpdate(dns.RcodeNameError)
+				}
+				return errUpdate(dns.RcodeNXRrset)
+			}
+		case dns.ClassNONE:
+			if h.Rdlength != 0 {
+				return errUpdate(dns.RcodeFormatError)
+			}
+			if len(existing) > 0 {
+				if h.Rrtype == dns.TypeANY {
+					return errUpdate(dns.RcodeYXDomain)
+				}
+				return errUpdate(dns.RcodeYXRrset)
+			}
+		case dns.ClassINET:
+			value, err := rrValue(rr)
+			if err != nil {
+				return errUpdate(dns.RcodeFormatError)
+			}
+			key := rrset{name, h.Rrtype}
+			required[key] = append(required[key], value)
+		default:
+			return errUpdate(dns.RcodeFormatError)
+		}
+	}
+
+	// Value dependent prerequisites require the RRset to match exactly
+	for key, values := range required {
+		existing := filterRecords(records.Records, key.name, key.rtype)
+		if !sameValues(existing, values) {
+			return errUpdate(dns.RcodeNXRrset)
+		}
+	}
+
+	return nil
+}
+
+// applyUpdate applies a single RR of the update section.
+func (s *Server) applyUpdate(ctx context.Context, zone string, rr dns.RR) error {
+	h := rr.Header()
+	name := dns.CanonicalName(h.Name)
+	domain := porkbun.NormalizeName(zone)
+
+	// The SOA is synthesized and the apex NS records are managed by Porkbun, so neither is touched
+	if h.Rrtype == dns.TypeSOA || (name == zone && h.Rrtype == dns.TypeNS && h.Class != dns.ClassINET) {
+		return nil
+	}
+
+	switch h.Class {
+	case dns.ClassINET:
+		value, err := rrValue(rr)
+		if err != nil {
+			return errUpdate(dns.RcodeNotImplemented)
+		}
+
+		existing, err := s.api.GetRecordsByType(ctx, domain, recordTypes[h.Rrtype], subdomainPtr(name, zone))
+		if err != nil {
+			return err
+		}
+		for _, record := range existing.Records {
+			if value.matches(record) {
+				return nil // Duplicate records are silently ignored
+			}
+		}
+
+		ttl := h.Ttl
+		if ttl < minTTL {
+			ttl = minTTL
+		}
+
+		_, err = s.api.CreateRecord(ctx, domain, &porkbun.DnsRecord{
+			Name:    porkbun.RelativeName(name, zone),
+			Type:    recordTypes[h.Rrtype],
+			Content: value.Content,
+			TTL:     strconv.FormatUint(uint64(ttl), 10),
+			Prio:    value.Prio,
+		})
+		return err
+
+	case dns.ClassANY:
+		if h.Rrtype != dns.TypeANY {
+			return s.deleteRRset(ctx, zone, name, h.Rrtype)
+		}
+
+		records, err := s.api.GetRecords(ctx, domain, nil)
+		if err != nil {
+			return err
+		}
+		deleted := make(map[uint16]bool)
+		for _, record := range filterRecords(records.Records, name, dns.TypeANY) {
+			rtype := dns.StringToType[record.Type.String()]
+			if deleted[rtype] || (name == zone && rtype == dns.TypeNS) {
+				continue
+			}
+			deleted[rtype] = true
+			if err := s.deleteRRset(ctx, zone, name, rtype); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case dns.ClassNONE:
+		value, err := rrValue(rr)
+		if err != nil {
+			return nil // Records of unsupported types cannot exist
+		}
+
+		existing, err := s.api.GetRecordsByType(ctx, domain, recordTypes[h.Rrtype], subdomainPtr(name, zone))
+		if err != nil {
+			return err
+		}
+		for _, record := range existing.Records {
+			if record.ID != nil && value.matches(record) {
+				if _, err := s.api.DeleteRecord(ctx, domain, *record.ID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	return errUpdate(dns.RcodeFormatError)
+}
+
+// deleteRRset deletes all records of the given name and type.
+func (s *Server) deleteRRset(ctx context.Context, zone, name string, rtype uint16) error {
+	recordType, ok := recordTypes[rtype]
+	if !ok {
+		return nil
+	}
+
+	domain, subdomain := porkbun.NormalizeName(zone), subdomainPtr(name, zone)
+
+	// Deleting an RRset that does not exist is not an error in RFC 2136
+	existing, err := s.api.GetRecordsByType(ctx, domain, recordType, subdomain)
+	if err != nil {
+		return err
+	}
+	if len(existing.Records) == 0 {
+		return nil
+	}
+
+	_, err = s.api.DeleteRecordByType(ctx, domain, recordType, subdomain)
+	return err
+}
+
+// soa synthesizes the SOA record of the zone.
+func (s *Server) soa(zone string) dns.RR {
+	mbox := s.options.Hostmaster
+	if mbox == "" {
+		mbox = "hostmaster." + zone
+	}
+
+	return &dns.SOA{
+		Hdr:     dns.RR_Header{Name: zone, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: soaTTL},
+		Ns:      dns.Fqdn(s.options.PrimaryNS),
+		Mbox:    dns.Fqdn(mbox),
+		Serial:  s.serial.Load(),
+		Refresh: 3600,
+		Retry:   600,
+		Expire:  604800,
+		Minttl:  minTTL,
+	}
+}
+
+// findZone returns the most specific configured zone containing the name, or an empty string.
+func (s *Server) findZone(name string) string {
+	name = dns.CanonicalName(name)
+
+	var match string
+	for zone := range s.zones {
+		if dns.IsSubDomain(zone, name) && len(zone) > len(match) {
+			match = zone
+		}
+	}
+	return match
+}
+
+// filterRecords returns the records with the given owner name and type, or all types for TypeANY.
+func filterRecords(records []porkbun.DnsRecord, name string, rtype uint16) []porkbun.DnsRecord {
+	var result []porkbun.DnsRecord
+	for _, record := range records {
+		if dns.CanonicalName(record.Name) != name {
+			continue
+		}
+		if rtype != dns.TypeANY && recordTypes[rtype] != record.Type {
+			continue
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+// sameValues reports whether the records hold exactly the given values.
+func sameValues(records []porkbun.DnsRecord, values []recordValue) bool {
+	if len(records) != len(values) {
+		return false
+	}
+
+	for _, value := range values {
+		found := false
+		for _, record := range records {
+			if value.matches(record) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// rcode maps an error to the RCODE of the UPDATE response.
+func rcode(err error) int {
+	var e errUpdate
+	if errors.As(err, &e) {
+		return int(e)
+	}
+	return dns.RcodeServerFailure
+}
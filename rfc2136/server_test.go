@@ -0,0 +1,278 @@
+package rfc2136
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+)
+
+const (
+	testKey    = "update-key."
+	testSecret = "c2VjcmV0LXNlY3JldC1zZWNyZXQtc2VjcmV0" // base64("secret-secret-secret-secret")
+)
+
+// setupServer starts the UPDATE server on a local UDP port backed by a porkbuntest.FakeDNS.
+func setupServer(t *testing.T) (string, *porkbuntest.FakeDNS) {
+	fake := porkbuntest.NewFakeDNS("example.com")
+	fake.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.A, Content: "1.2.3.4"})
+	fake.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.NS, Content: "maceio.porkbun.com"})
+	fake.AddRecord("example.com", porkbun.DnsRecord{Name: "host", Type: porkbun.A, Content: "10.0.0.1"})
+	fake.AddRecord("example.com", porkbun.DnsRecord{Name: "host", Type: porkbun.TXT, Content: "hello"})
+
+	// example.net is not managed by the fake, so updates to it fail at the API
+	server := NewServer(fake, &Options{
+		Zones:     []string{"example.com", "example.net"},
+		TsigKeys:  map[string]string{"Update-Key": testSecret},
+		PrimaryNS: "ns1.example.net",
+	})
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	started := make(chan struct{})
+	srv := server.DNSServer("udp")
+	srv.PacketConn = pc
+	srv.NotifyStartedFunc = func() { close(started) }
+	go func() { _ = srv.ActivateAndServe() }()
+	t.Cleanup(func() { _ = srv.Shutdown() })
+
+	<-started
+	return pc.LocalAddr().String(), fake
+}
+
+// find returns the records of example.com with the fully qualified name and, if set, the type.
+func find(fake *porkbuntest.FakeDNS, name string, recordType porkbun.DnsRecordType) []porkbun.DnsRecord {
+	var result []porkbun.DnsRecord
+	for _, r := range fake.Records("example.com") {
+		if r.Name == name && (recordType == "" || r.Type == recordType) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// exchange signs the message with the test key and sends it to the server.
+func exchange(t *testing.T, addr string, m *dns.Msg, secret string) *dns.Msg {
+	c := &dns.Client{Net: "udp", Timeout: 5 * time.Second}
+	if secret != "" {
+		c.TsigSecret = map[string]string{testKey: secret}
+		m.SetTsig(testKey, dns.HmacSHA256, 300, time.Now().Unix())
+	}
+
+	// Signed NOTAUTH responses are reported as ErrAuth by the client, but the message is still returned
+	resp, _, err := c.Exchange(m, addr)
+	if resp == nil {
+		t.Fatalf("no response: %v", err)
+	}
+	if secret == testSecret && resp.Rcode != dns.RcodeNotAuth {
+		assert.NoError(t, err)
+	}
+	return resp
+}
+
+func newUpdate(zone string) *dns.Msg {
+	m := new(dns.Msg)
+	m.SetUpdate(zone)
+	return m
+}
+
+func mustRR(t *testing.T, s string) dns.RR {
+	rr, err := dns.NewRR(s)
+	assert.NoError(t, err)
+	return rr
+}
+
+func TestServer_QuerySOA(t *testing.T) {
+	addr, _ := setupServer(t)
+
+	m := new(dns.Msg)
+	m.SetQuestion("example.com.", dns.TypeSOA)
+	resp := exchange(t, addr, m, "")
+
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+	assert.True(t, resp.Authoritative)
+	if assert.Len(t, resp.Answer, 1) {
+		soa := resp.Answer[0].(*dns.SOA)
+		assert.Equal(t, "example.com.", soa.Hdr.Name)
+		assert.Equal(t, "ns1.example.net.", soa.Ns)
+		assert.Equal(t, "hostmaster.example.com.", soa.Mbox)
+	}
+
+	// SOA queries for names inside the zone return the SOA in the authority section
+	m.SetQuestion("Host.Example.com.", dns.TypeSOA)
+	resp = exchange(t, addr, m, "")
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+	assert.Empty(t, resp.Answer)
+	assert.Len(t, resp.Ns, 1)
+}
+
+func TestServer_QueryRefused(t *testing.T) {
+	addr, _ := setupServer(t)
+
+	m := new(dns.Msg)
+	m.SetQuestion("example.org.", dns.TypeSOA)
+	assert.Equal(t, dns.RcodeRefused, exchange(t, addr, m, "").Rcode)
+
+	m.SetQuestion("host.example.com.", dns.TypeA)
+	assert.Equal(t, dns.RcodeRefused, exchange(t, addr, m, "").Rcode)
+}
+
+func TestServer_UpdateAdd(t *testing.T) {
+	addr, fake := setupServer(t)
+
+	m := newUpdate("example.com.")
+	m.Insert([]dns.RR{
+		mustRR(t, "new.example.com. 300 IN A 192.0.2.1"),
+		mustRR(t, "new.example.com. 3600 IN AAAA 2001:db8::1"),
+		mustRR(t, "example.com. 3600 IN MX 10 mail.example.com."),
+		mustRR(t, `new.example.com. 3600 IN TXT "v=spf1" " -all"`),
+		mustRR(t, "host.example.com. 3600 IN A 10.0.0.1"), // Duplicate, ignored
+	})
+	resp := exchange(t, addr, m, testSecret)
+
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+	assert.NotNil(t, resp.IsTsig())
+
+	a := find(fake, "new.example.com", porkbun.A)
+	if assert.Len(t, a, 1) {
+		assert.Equal(t, "192.0.2.1", a[0].Content)
+		assert.Equal(t, "600", a[0].TTL)
+	}
+	assert.Len(t, find(fake, "new.example.com", porkbun.AAAA), 1)
+
+	mx := find(fake, "example.com", porkbun.MX)
+	if assert.Len(t, mx, 1) {
+		assert.Equal(t, "mail.example.com", mx[0].Content)
+		assert.Equal(t, "10", mx[0].Prio)
+		assert.Equal(t, "3600", mx[0].TTL)
+	}
+
+	txt := find(fake, "new.example.com", porkbun.TXT)
+	if assert.Len(t, txt, 1) {
+		assert.Equal(t, "v=spf1 -all", txt[0].Content)
+	}
+
+	assert.Len(t, find(fake, "host.example.com", porkbun.A), 1)
+}
+
+func TestServer_UpdateDelete(t *testing.T) {
+	addr, fake := setupServer(t)
+
+	m := newUpdate("example.com.")
+	m.RemoveRRset([]dns.RR{mustRR(t, "host.example.com. 0 IN A 0.0.0.0")})
+	m.Remove([]dns.RR{mustRR(t, "example.com. 0 IN A 1.2.3.4")})
+	resp := exchange(t, addr, m, testSecret)
+
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+	assert.Empty(t, find(fake, "host.example.com", porkbun.A))
+	assert.Len(t, find(fake, "host.example.com", porkbun.TXT), 1)
+	assert.Empty(t, find(fake, "example.com", porkbun.A))
+}
+
+func TestServer_UpdateDeleteCase(t *testing.T) {
+	addr, fake := setupServer(t)
+	fake.AddRecord("example.com", porkbun.DnsRecord{Name: "host", Type: porkbun.TXT, Content: "Foo"})
+
+	// TXT content is case-sensitive, only the exact value is deleted
+	m := newUpdate("example.com.")
+	m.Remove([]dns.RR{mustRR(t, `host.example.com. 0 IN TXT "HELLO"`), mustRR(t, `host.example.com. 0 IN TXT "Foo"`)})
+	resp := exchange(t, addr, m, testSecret)
+
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+	if txt := find(fake, "host.example.com", porkbun.TXT); assert.Len(t, txt, 1) {
+		assert.Equal(t, "hello", txt[0].Content)
+	}
+}
+
+func TestServer_UpdateDeleteName(t *testing.T) {
+	addr, fake := setupServer(t)
+
+	m := newUpdate("example.com.")
+	m.RemoveName([]dns.RR{mustRR(t, "host.example.com. 0 IN A 0.0.0.0"), mustRR(t, "example.com. 0 IN A 0.0.0.0")})
+	resp := exchange(t, addr, m, testSecret)
+
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+	assert.Empty(t, find(fake, "host.example.com", ""))
+
+	// The apex NS records are never deleted
+	assert.Empty(t, find(fake, "example.com", porkbun.A))
+	assert.Len(t, find(fake, "example.com", porkbun.NS), 1)
+}
+
+func TestServer_UpdatePrerequisites(t *testing.T) {
+	addr, fake := setupServer(t)
+
+	tests := []struct {
+		name   string
+		prereq func(m *dns.Msg)
+		rcode  int
+	}{
+		{"name in use", func(m *dns.Msg) { m.NameUsed([]dns.RR{mustRR(t, "host.example.com. 0 IN A 0.0.0.0")}) }, dns.RcodeSuccess},
+		{"name not in use", func(m *dns.Msg) { m.NameNotUsed([]dns.RR{mustRR(t, "host.example.com. 0 IN A 0.0.0.0")}) }, dns.RcodeYXDomain},
+		{"name missing", func(m *dns.Msg) { m.NameUsed([]dns.RR{mustRR(t, "nope.example.com. 0 IN A 0.0.0.0")}) }, dns.RcodeNameError},
+		{"rrset exists", func(m *dns.Msg) { m.RRsetUsed([]dns.RR{mustRR(t, "host.example.com. 0 IN TXT \"\"")}) }, dns.RcodeSuccess},
+		{"rrset missing", func(m *dns.Msg) { m.RRsetUsed([]dns.RR{mustRR(t, "host.example.com. 0 IN AAAA ::")}) }, dns.RcodeNXRrset},
+		{"rrset not exists", func(m *dns.Msg) { m.RRsetNotUsed([]dns.RR{mustRR(t, "host.example.com. 0 IN A 0.0.0.0")}) }, dns.RcodeYXRrset},
+		{"value match", func(m *dns.Msg) { m.Used([]dns.RR{mustRR(t, "host.example.com. 0 IN A 10.0.0.1")}) }, dns.RcodeSuccess},
+		{"value mismatch", func(m *dns.Msg) { m.Used([]dns.RR{mustRR(t, "host.example.com. 0 IN A 10.0.0.2")}) }, dns.RcodeNXRrset},
+		{"outside zone", func(m *dns.Msg) { m.NameUsed([]dns.RR{mustRR(t, "example.org. 0 IN A 0.0.0.0")}) }, dns.RcodeNotZone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newUpdate("example.com.")
+			tt.prereq(m)
+			m.Insert([]dns.RR{mustRR(t, "marker.example.com. 600 IN TXT \""+tt.name+"\"")})
+
+			resp := exchange(t, addr, m, testSecret)
+			assert.Equal(t, dns.RcodeToString[tt.rcode], dns.RcodeToString[resp.Rcode])
+
+			applied := false
+			for _, r := range find(fake, "marker.example.com", porkbun.TXT) {
+				applied = applied || r.Content == tt.name
+			}
+			assert.Equal(t, tt.rcode == dns.RcodeSuccess, applied)
+		})
+	}
+}
+
+func TestServer_UpdateErrors(t *testing.T) {
+	addr, fake := setupServer(t)
+
+	// Unsigned updates are refused
+	m := newUpdate("example.com.")
+	m.Insert([]dns.RR{mustRR(t, "new.example.com. 600 IN A 192.0.2.1")})
+	assert.Equal(t, dns.RcodeRefused, exchange(t, addr, m, "").Rcode)
+
+	// Updates signed with the wrong secret are not authorized
+	m = newUpdate("example.com.")
+	m.Insert([]dns.RR{mustRR(t, "new.example.com. 600 IN A 192.0.2.1")})
+	assert.Equal(t, dns.RcodeNotAuth, exchange(t, addr, m, "d3Jvbmctc2VjcmV0").Rcode)
+
+	// Zones that are not configured are not authoritative
+	m = newUpdate("example.org.")
+	m.Insert([]dns.RR{mustRR(t, "new.example.org. 600 IN A 192.0.2.1")})
+	assert.Equal(t, dns.RcodeNotAuth, exchange(t, addr, m, testSecret).Rcode)
+
+	// Records outside the zone are rejected before any change is made
+	m = newUpdate("example.com.")
+	m.Insert([]dns.RR{mustRR(t, "new.example.com. 600 IN A 192.0.2.1"), mustRR(t, "new.example.org. 600 IN A 192.0.2.1")})
+	assert.Equal(t, dns.RcodeNotZone, exchange(t, addr, m, testSecret).Rcode)
+
+	// Unsupported record types are not implemented
+	m = newUpdate("example.com.")
+	m.Insert([]dns.RR{mustRR(t, "new.example.com. 600 IN PTR host.example.com.")})
+	assert.Equal(t, dns.RcodeNotImplemented, exchange(t, addr, m, testSecret).Rcode)
+
+	assert.Empty(t, find(fake, "new.example.com", ""))
+
+	// API failures are reported as SERVFAIL
+	m = newUpdate("example.net.")
+	m.Insert([]dns.RR{mustRR(t, "new.example.net. 600 IN A 192.0.2.1")})
+	assert.Equal(t, dns.RcodeServerFailure, exchange(t, addr, m, testSecret).Rcode)
+}
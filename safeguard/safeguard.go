@@ -0,0 +1,165 @@
+// Package safeguard previews the DNS records affected by destructive operations and flags the
+// records matching protected patterns, such as the apex MX records, so callers can ask for
+// confirmation before an operation that could cause an outage.
+//
+//	guard := safeguard.New(client.Dns, nil)
+//	preview, err := guard.PreviewByType(ctx, "example.com", porkbun.MX, nil)
+//	if err != nil {
+//		return err
+//	}
+//	if len(preview.Protected) > 0 {
+//		return errors.New("refusing to delete protected records")
+//	}
+package safeguard
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// Rule is a protected record pattern.
+type Rule struct {
+	Type porkbun.DnsRecordType // Record type, any if empty.
+	Name string                // Glob matched against the name relative to the domain, "@" for the apex. Any if empty.
+}
+
+// DefaultRules protects the apex MX records and all NS records.
+var DefaultRules = []Rule{
+	{Type: porkbun.MX, Name: "@"},
+	{Type: porkbun.NS},
+}
+
+// ParseRule parses a rule written as TYPE or TYPE:NAME, e.g. "MX:@", "NS" or "TXT:_dmarc*".
+// A type of "*" matches any type.
+func ParseRule(s string) (Rule, error) {
+	recordType, name, _ := strings.Cut(strings.TrimSpace(s), ":")
+	if recordType == "" {
+		return Rule{}, fmt.Errorf("safeguard: invalid rule %q, expected TYPE or TYPE:NAME", s)
+	}
+	if recordType == "*" {
+		recordType = ""
+	}
+	name = strings.ToLower(name)
+	if _, err := path.Match(name, ""); err != nil {
+		return Rule{}, fmt.Errorf("safeguard: invalid rule %q: %w", s, err)
+	}
+	return Rule{Type: porkbun.DnsRecordType(strings.ToUpper(recordType)), Name: name}, nil
+}
+
+// ParseRules parses a list of rules.
+func ParseRules(list []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(list))
+	for _, s := range list {
+		rule, err := ParseRule(s)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// String formats the rule as parsed by ParseRule.
+func (r Rule) String() string {
+	recordType := string(r.Type)
+	if recordType == "" {
+		recordType = "*"
+	}
+	if r.Name == "" {
+		return recordType
+	}
+	return recordType + ":" + r.Name
+}
+
+// Matches reports whether the record of the domain matches the rule.
+func (r Rule) Matches(record porkbun.DnsRecord, domain string) bool {
+	if r.Type != "" && r.Type != record.Type {
+		return false
+	}
+	if r.Name == "" {
+		return true
+	}
+	name := porkbun.RelativeName(record.Name, domain)
+	if name == "" {
+		name = "@"
+	}
+	matched, _ := path.Match(r.Name, name)
+	return matched
+}
+
+// Preview lists the records an operation would affect.
+type Preview struct {
+	Domain    string              // Domain of the records.
+	Records   []porkbun.DnsRecord // All affected records.
+	Protected []porkbun.DnsRecord // The affected records matching a protected rule.
+}
+
+// Options defines the configuration options for a Guard.
+type Options struct {
+	Rules []Rule // Protected patterns, defaults to DefaultRules. Use an empty non-nil slice to protect nothing.
+}
+
+// Guard resolves the records affected by destructive operations.
+type Guard struct {
+	dns   porkbun.DNSAPI
+	rules []Rule
+}
+
+// New creates a Guard looking up records with dns.
+func New(dns porkbun.DNSAPI, options *Options) *Guard {
+	guard := &Guard{dns: dns, rules: DefaultRules}
+	if options != nil && options.Rules != nil {
+		guard.rules = options.Rules
+	}
+	return guard
+}
+
+// Rules returns the protected patterns of the guard.
+func (g *Guard) Rules() []Rule {
+	return g.rules
+}
+
+// Protected reports whether the record of the domain matches a protected rule.
+func (g *Guard) Protected(record porkbun.DnsRecord, domain string) bool {
+	for _, rule := range g.rules {
+		if rule.Matches(record, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// PreviewRecord returns the preview of an operation on the record with the ID, such as DeleteRecord
+// or EditRecord. The preview has no records if the ID does not exist.
+func (g *Guard) PreviewRecord(ctx context.Context, domain string, id int64) (*Preview, error) {
+	resp, err := g.dns.GetRecords(ctx, domain, &id)
+	if err != nil {
+		return nil, err
+	}
+	return g.preview(domain, resp.Records), nil
+}
+
+// PreviewByType returns the preview of DeleteRecordByType or EditRecordByType with the same
+// arguments, subdomain being nil for the apex.
+func (g *Guard) PreviewByType(ctx context.Context, domain string, recordType porkbun.DnsRecordType, subdomain *string) (*Preview, error) {
+	resp, err := g.dns.GetRecordsByType(ctx, domain, recordType, subdomain)
+	if err != nil {
+		return nil, err
+	}
+	return g.preview(domain, resp.Records), nil
+}
+
+// preview classifies the records.
+func (g *Guard) preview(domain string, records []porkbun.DnsRecord) *Preview {
+	preview := &Preview{Domain: domain, Records: records}
+	for _, record := range records {
+		if g.Protected(record, domain) {
+			preview.Protected = append(preview.Protected, record)
+		}
+	}
+	return preview
+}
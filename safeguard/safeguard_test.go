@@ -0,0 +1,110 @@
+package safeguard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+)
+
+func setupDNS() *porkbuntest.FakeDNS {
+	dns := porkbuntest.NewFakeDNS("example.com")
+	dns.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.MX, Content: "mx1.example.net", Prio: "10"})
+	dns.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.MX, Content: "mx2.example.net", Prio: "20"})
+	dns.AddRecord("example.com", porkbun.DnsRecord{Name: "mail", Type: porkbun.MX, Content: "mx1.example.net", Prio: "10"})
+	dns.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1"})
+	return dns
+}
+
+func TestParseRule(t *testing.T) {
+	tests := map[string]Rule{
+		"MX:@":         {Type: porkbun.MX, Name: "@"},
+		"ns":           {Type: porkbun.NS},
+		"txt:_DMARC*":  {Type: porkbun.TXT, Name: "_dmarc*"},
+		"*:mail":       {Name: "mail"},
+		" CAA:@ ":      {Type: porkbun.CAA, Name: "@"},
+		"A:*.internal": {Type: porkbun.A, Name: "*.internal"},
+	}
+	for s, expected := range tests {
+		rule, err := ParseRule(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, rule, s)
+	}
+
+	for _, s := range []string{"", ":@", "MX:[", "MX:[a"} {
+		_, err := ParseRule(s)
+		assert.Error(t, err, s)
+	}
+
+	rules, err := ParseRules([]string{"MX:@", "NS"})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultRules, rules)
+	assert.Equal(t, "MX:@", rules[0].String())
+	assert.Equal(t, "*:mail", Rule{Name: "mail"}.String())
+}
+
+func TestRule_Matches(t *testing.T) {
+	apexMX := porkbun.DnsRecord{Name: "example.com", Type: porkbun.MX}
+	subMX := porkbun.DnsRecord{Name: "mail.Example.com", Type: porkbun.MX}
+
+	assert.True(t, Rule{Type: porkbun.MX, Name: "@"}.Matches(apexMX, "example.com"))
+	assert.False(t, Rule{Type: porkbun.MX, Name: "@"}.Matches(subMX, "example.com"))
+	assert.True(t, Rule{Type: porkbun.MX, Name: "mail"}.Matches(subMX, "example.com."))
+	assert.True(t, Rule{Type: porkbun.MX}.Matches(subMX, "example.com"))
+	assert.True(t, Rule{Name: "m*"}.Matches(subMX, "example.com"))
+	assert.False(t, Rule{Type: porkbun.TXT}.Matches(apexMX, "example.com"))
+}
+
+func TestGuard_PreviewByType(t *testing.T) {
+	guard := New(setupDNS(), nil)
+
+	preview, err := guard.PreviewByType(context.Background(), "example.com", porkbun.MX, nil)
+	assert.NoError(t, err)
+	assert.Len(t, preview.Records, 2)
+	assert.Len(t, preview.Protected, 2)
+
+	// Subdomain MX records are not protected by default
+	mail := "mail"
+	preview, err = guard.PreviewByType(context.Background(), "example.com", porkbun.MX, &mail)
+	assert.NoError(t, err)
+	assert.Len(t, preview.Records, 1)
+	assert.Empty(t, preview.Protected)
+
+	_, err = guard.PreviewByType(context.Background(), "example.org", porkbun.MX, nil)
+	assert.Error(t, err)
+}
+
+func TestGuard_PreviewRecord(t *testing.T) {
+	dns := setupDNS()
+	id, _ := dns.AddRecord("example.com", porkbun.DnsRecord{Name: "vpn", Type: porkbun.A, Content: "192.0.2.2"})
+	guard := New(dns, &Options{Rules: []Rule{{Type: porkbun.A, Name: "vpn"}}})
+
+	preview, err := guard.PreviewRecord(context.Background(), "example.com", id)
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", preview.Domain)
+	if assert.Len(t, preview.Records, 1) {
+		assert.Equal(t, "vpn.example.com", preview.Records[0].Name)
+	}
+	assert.Len(t, preview.Protected, 1)
+
+	preview, err = guard.PreviewRecord(context.Background(), "example.com", 999999)
+	assert.NoError(t, err)
+	assert.Empty(t, preview.Records)
+
+	// An empty rule list protects nothing
+	guard = New(dns, &Options{Rules: []Rule{}})
+	preview, err = guard.PreviewByType(context.Background(), "example.com", porkbun.MX, nil)
+	assert.NoError(t, err)
+	assert.Len(t, preview.Records, 2)
+	assert.Empty(t, preview.Protected)
+}
+
+func TestRule_MatchesApexNames(t *testing.T) {
+	apex := Rule{Name: "@"}
+	assert.True(t, apex.Matches(porkbun.DnsRecord{Name: "Example.com."}, "example.com"))
+	assert.True(t, apex.Matches(porkbun.DnsRecord{Name: ""}, "example.com"))
+	assert.False(t, apex.Matches(porkbun.DnsRecord{Name: "a.b.example.com"}, "example.com"))
+	assert.True(t, Rule{Name: "a.b"}.Matches(porkbun.DnsRecord{Name: "a.b.example.com"}, "example.com"))
+}
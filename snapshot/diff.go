@@ -0,0 +1,260 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// Kind is the kind of configuration a Change applies to.
+type Kind string
+
+// Constants representing the kinds of configuration.
+const (
+	KindDomain      Kind = "domain"      // A whole domain, only added to or removed from the account
+	KindNameServers Kind = "nameservers" // The name servers of a domain
+	KindRecord      Kind = "record"      // A DNS record
+	KindForward     Kind = "forward"     // A URL forward
+)
+
+// Action is what a Change does.
+type Action string
+
+// Constants representing the actions of changes.
+const (
+	Removed  Action = "removed"  // Old is removed
+	Modified Action = "modified" // Old is replaced by New
+	Added    Action = "added"    // New is added
+)
+
+// Change is a difference between two configurations of a domain.
+type Change struct {
+	Domain string `json:"domain"`        // Domain name.
+	Kind   Kind   `json:"kind"`          // Kind of configuration.
+	Action Action `json:"action"`        // What changes.
+	Old    any    `json:"old,omitempty"` // *Domain, *Record, *Forward or []string of name servers, nil if Added.
+	New    any    `json:"new,omitempty"` // *Domain, *Record, *Forward or []string of name servers, nil if Removed.
+}
+
+// String describes the change on one line.
+func (c Change) String() string {
+	return c.sign() + " " + c.Domain + " " + c.detail()
+}
+
+// sign returns the diff sign of the action: "-", "~" or "+".
+func (c Change) sign() string {
+	return map[Action]string{Removed: "-", Modified: "~", Added: "+"}[c.Action]
+}
+
+// detail describes the change without its domain.
+func (c Change) detail() string {
+	switch c.Action {
+	case Removed:
+		return fmt.Sprintf("%s %s", c.Kind, describe(c.Old))
+	case Added:
+		return fmt.Sprintf("%s %s", c.Kind, describe(c.New))
+	}
+	return fmt.Sprintf("%s %s -> %s", c.Kind, describe(c.Old), describe(c.New))
+}
+
+// describe formats a changed value.
+func describe(v any) string {
+	switch v := v.(type) {
+	case *Domain:
+		if v.Error != "" {
+			return "not captured: " + v.Error
+		}
+		return fmt.Sprintf("with %d records and %d forwards", len(v.Records), len(v.Forwards))
+	case *Record:
+		s := fmt.Sprintf("%s %s %q", displayName(v.Name), v.Type, v.Content)
+		if v.TTL != "" {
+			s += " ttl=" + v.TTL
+		}
+		if v.Prio != "" && v.Prio != "0" {
+			s += " prio=" + v.Prio
+		}
+		return s
+	case *Forward:
+		return fmt.Sprintf("%s -> %s (%s, include path %s, wildcard %s)", displayName(v.Subdomain), v.Location,
+			v.Type, v.IncludePath, v.Wildcard)
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// displayName shows the apex as "@".
+func displayName(name string) string {
+	if name == "" {
+		return "@"
+	}
+	return name
+}
+
+// DiffDomain returns the changes turning the configuration from into to, both of the same domain.
+// Records are matched by name, type and content, and modified if their TTL or priority differ;
+// notes are not compared, as the edit API cannot change them. Forwards are matched by subdomain.
+// Changes are ordered by kind, then removals, modifications and additions, the order in which they
+// can be applied.
+func DiffDomain(from, to *Domain) []Change {
+	domain := to.Domain
+	var changes []Change
+
+	if len(to.NameServers) > 0 && !equalStrings(from.NameServers, to.NameServers) {
+		changes = append(changes, Change{Domain: domain, Kind: KindNameServers, Action: Modified,
+			Old: from.NameServers, New: to.NameServers})
+	}
+
+	// Records
+	unmatched := make(map[string][]int)
+	for i, r := range from.Records {
+		unmatched[r.key()] = append(unmatched[r.key()], i)
+	}
+	var modified, added []Change
+	for i := range to.Records {
+		want := &to.Records[i]
+		candidates := unmatched[want.key()]
+		if len(candidates) == 0 {
+			added = append(added, Change{Domain: domain, Kind: KindRecord, Action: Added, New: want})
+			continue
+		}
+		have := &from.Records[candidates[0]]
+		unmatched[want.key()] = candidates[1:]
+		if !equalSetting(have.TTL, want.TTL) || !equalSetting(have.Prio, want.Prio) {
+			modified = append(modified, Change{Domain: domain, Kind: KindRecord, Action: Modified, Old: have, New: want})
+		}
+	}
+	var removed []Change
+	for i := range from.Records {
+		have := &from.Records[i]
+		for _, j := range unmatched[have.key()] {
+			if j == i {
+				removed = append(removed, Change{Domain: domain, Kind: KindRecord, Action: Removed, Old: have})
+			}
+		}
+	}
+	changes = append(changes, removed...)
+	changes = append(changes, modified...)
+	changes = append(changes, added...)
+
+	// Forwards
+	byName := make(map[string][]int)
+	for i, f := range from.Forwards {
+		byName[f.Subdomain] = append(byName[f.Subdomain], i)
+	}
+	removed, modified, added = nil, nil, nil
+	for i := range to.Forwards {
+		want := &to.Forwards[i]
+		candidates := byName[want.Subdomain]
+		if len(candidates) == 0 {
+			added = append(added, Change{Domain: domain, Kind: KindForward, Action: Added, New: want})
+			continue
+		}
+		have := &from.Forwards[candidates[0]]
+		byName[want.Subdomain] = candidates[1:]
+		if have.UrlForward != want.UrlForward {
+			modified = append(modified, Change{Domain: domain, Kind: KindForward, Action: Modified, Old: have, New: want})
+		}
+	}
+	for _, name := range sortedKeys(byName) {
+		for _, i := range byName[name] {
+			removed = append(removed, Change{Domain: domain, Kind: KindForward, Action: Removed, Old: &from.Forwards[i]})
+		}
+	}
+	changes = append(changes, removed...)
+	changes = append(changes, modified...)
+	changes = append(changes, added...)
+
+	return changes
+}
+
+// Diff compares two snapshots and returns the changes turning from into to, ordered by domain.
+// Domains that could not be captured in either snapshot are not compared but listed as skipped.
+func Diff(from, to *Snapshot) *Report {
+	report := &Report{From: from.CreatedAt, To: to.CreatedAt, Changes: []Change{}}
+
+	names := make(map[string]bool)
+	for _, d := range from.Domains {
+		names[d.Domain] = true
+	}
+	for _, d := range to.Domains {
+		names[d.Domain] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		old, cur := from.Domain(name), to.Domain(name)
+		switch {
+		case old == nil:
+			report.Changes = append(report.Changes, Change{Domain: name, Kind: KindDomain, Action: Added, New: cur})
+		case cur == nil:
+			report.Changes = append(report.Changes, Change{Domain: name, Kind: KindDomain, Action: Removed, Old: old})
+		case old.Error != "" || cur.Error != "":
+			if report.Skipped == nil {
+				report.Skipped = make(map[string]string)
+			}
+			report.Skipped[name] = cur.Error
+			if cur.Error == "" {
+				report.Skipped[name] = old.Error
+			}
+		default:
+			report.Changes = append(report.Changes, DiffDomain(old, cur)...)
+		}
+	}
+	return report
+}
+
+// DiffLive compares the snapshot with the live configuration of the domains selected by options,
+// by default all domains in the account. To compare a snapshot of some domains only, select them
+// in options, otherwise the other domains of the account are reported as added.
+func DiffLive(ctx context.Context, dns porkbun.DNSAPI, domains porkbun.DomainsAPI, from *Snapshot, options *Options) (*Report, error) {
+	var opts Options
+	if options != nil {
+		opts = *options
+	}
+
+	selected, err := from.Select(opts.Domains)
+	if err != nil {
+		return nil, err
+	}
+	live, err := Take(ctx, dns, domains, &opts)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(selected, live), nil
+}
+
+// equalSetting compares optional record settings, an empty value matching any.
+func equalSetting(a, b string) bool {
+	return a == b || a == "" || b == ""
+}
+
+// equalStrings compares two lists.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedKeys returns the keys of the map in order.
+func sortedKeys(m map[string][]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
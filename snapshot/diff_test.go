@@ -0,0 +1,141 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+func TestDiffDomain(t *testing.T) {
+	forward := func(sub, location string) Forward {
+		return Forward{UrlForward: porkbun.UrlForward{Subdomain: sub, Location: location, Type: porkbun.Temporary, IncludePath: "no", Wildcard: "no"}}
+	}
+	from := &Domain{
+		Domain:      "example.com",
+		NameServers: []string{"ns1.example.net", "ns2.example.net"},
+		Records: []Record{
+			{ID: 1, Type: porkbun.A, Content: "192.0.2.1", TTL: "600"},
+			{ID: 2, Name: "old", Type: porkbun.A, Content: "192.0.2.2", TTL: "600"},
+			{ID: 3, Name: "www", Type: porkbun.CNAME, Content: "example.com", TTL: "600"},
+			{ID: 4, Name: "www", Type: porkbun.CNAME, Content: "example.com", TTL: "600"},
+		},
+		Forwards: []Forward{forward("", "https://example.org"), forward("go", "https://example.net")},
+	}
+	to := &Domain{
+		Domain:      "example.com",
+		NameServers: []string{"ns1.example.net", "ns3.example.net"},
+		Records: []Record{
+			{Type: porkbun.A, Content: "192.0.2.1", TTL: "3600"},
+			{Name: "new", Type: porkbun.TXT, Content: "hello"},
+			{Name: "www", Type: porkbun.CNAME, Content: "example.com"},
+		},
+		Forwards: []Forward{forward("", "https://example.org/home"), forward("blog", "https://blog.example.net")},
+	}
+
+	changes := DiffDomain(from, to)
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	assert.Equal(t, []string{
+		"~ example.com nameservers ns1.example.net,ns2.example.net -> ns1.example.net,ns3.example.net",
+		`- example.com record old A "192.0.2.2" ttl=600`,
+		`- example.com record www CNAME "example.com" ttl=600`,
+		`~ example.com record @ A "192.0.2.1" ttl=600 -> @ A "192.0.2.1" ttl=3600`,
+		`+ example.com record new TXT "hello"`,
+		"- example.com forward go -> https://example.net (temporary, include path no, wildcard no)",
+		"~ example.com forward @ -> https://example.org (temporary, include path no, wildcard no) -> @ -> https://example.org/home (temporary, include path no, wildcard no)",
+		"+ example.com forward blog -> https://blog.example.net (temporary, include path no, wildcard no)",
+	}, lines)
+
+	// The changes reference the live records, whose IDs are needed to apply them
+	assert.Equal(t, int64(2), changes[1].Old.(*Record).ID)
+	assert.Equal(t, int64(4), changes[2].Old.(*Record).ID)
+	assert.Equal(t, int64(1), changes[3].Old.(*Record).ID)
+
+	assert.Empty(t, DiffDomain(from, from))
+	assert.Empty(t, DiffDomain(to, to))
+}
+
+func TestDiffDomain_EmptySettings(t *testing.T) {
+	// Missing settings and notes do not count as differences, nor do missing name servers
+	from := &Domain{Domain: "example.com", NameServers: []string{"ns1.example.net"},
+		Records: []Record{{Type: porkbun.MX, Content: "mx.example.net", TTL: "600", Prio: "10", Notes: "old"}}}
+	to := &Domain{Domain: "example.com",
+		Records: []Record{{Type: porkbun.MX, Content: "mx.example.net", Notes: "new"}}}
+	assert.Empty(t, DiffDomain(from, to))
+
+	to.Records[0].Prio = "20"
+	changes := DiffDomain(from, to)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, Modified, changes[0].Action)
+		assert.Equal(t, KindRecord, changes[0].Kind)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := &Snapshot{Version: Version, Domains: []Domain{
+		{Domain: "example.com", Records: []Record{{Name: "www", Type: porkbun.A, Content: "192.0.2.1"}}},
+		{Domain: "example.net", Error: "no API access"},
+		{Domain: "example.org"},
+	}}
+	to := &Snapshot{Version: Version, Domains: []Domain{
+		{Domain: "example.com", Records: []Record{{Name: "www", Type: porkbun.A, Content: "192.0.2.2"}}},
+		{Domain: "example.info", Records: []Record{{Type: porkbun.A, Content: "192.0.2.3"}}},
+		{Domain: "example.net"},
+	}}
+
+	report := Diff(from, to)
+	var lines []string
+	for _, c := range report.Changes {
+		lines = append(lines, c.String())
+	}
+	assert.Equal(t, []string{
+		`- example.com record www A "192.0.2.1"`,
+		`+ example.com record www A "192.0.2.2"`,
+		"+ example.info domain with 1 records and 0 forwards",
+		"- example.org domain with 0 records and 0 forwards",
+	}, lines)
+	assert.Equal(t, map[string]string{"example.net": "no API access"}, report.Skipped)
+	assert.Equal(t, []string{"example.com", "example.info", "example.org"}, report.Domains())
+	assert.False(t, report.Empty())
+
+	report = Diff(to, to)
+	assert.Empty(t, report.Changes)
+	assert.True(t, report.Empty())
+}
+
+func TestDiffLive(t *testing.T) {
+	server := setupServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	snap, err := Take(ctx, client.Dns, client.Domains, &Options{Domains: []string{"example.com"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	report, err := DiffLive(ctx, client.Dns, client.Domains, snap, &Options{Domains: []string{"example.com"}})
+	assert.NoError(t, err)
+	assert.True(t, report.Empty())
+	assert.Equal(t, snap.CreatedAt, report.From)
+
+	_, err = client.Dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Name: "new", Type: porkbun.TXT, Content: "hello"})
+	assert.NoError(t, err)
+
+	report, err = DiffLive(ctx, client.Dns, client.Domains, snap, &Options{Domains: []string{"example.com"}})
+	assert.NoError(t, err)
+	if assert.Len(t, report.Changes, 1) {
+		assert.Equal(t, `+ example.com record new TXT "hello" ttl=600`, report.Changes[0].String())
+	}
+
+	// Without a selection, the other domains of the account are new
+	report, err = DiffLive(ctx, client.Dns, client.Domains, snap, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "example.org"}, report.Domains())
+
+	_, err = DiffLive(ctx, client.Dns, client.Domains, snap, &Options{Domains: []string{"example.org"}})
+	assert.ErrorIs(t, err, ErrUnknownDomain)
+}
@@ -0,0 +1,95 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Report is the result of comparing two snapshots, or a snapshot with the live configuration.
+type Report struct {
+	From    time.Time         `json:"from"`              // Time the older snapshot was taken.
+	To      time.Time         `json:"to"`                // Time the newer snapshot was taken.
+	Changes []Change          `json:"changes"`           // Changes ordered by domain.
+	Skipped map[string]string `json:"skipped,omitempty"` // Domains not compared, with the reason they could not be captured.
+}
+
+// Domains returns the names of the domains with changes, in order.
+func (r *Report) Domains() []string {
+	var domains []string
+	for _, c := range r.Changes {
+		if len(domains) == 0 || domains[len(domains)-1] != c.Domain {
+			domains = append(domains, c.Domain)
+		}
+	}
+	return domains
+}
+
+// Empty reports whether the report has no changes and no skipped domains.
+func (r *Report) Empty() bool {
+	return len(r.Changes) == 0 && len(r.Skipped) == 0
+}
+
+// WriteText writes the report for humans, the changes grouped by domain.
+func (r *Report) WriteText(w io.Writer) error {
+	ew := &errWriter{w: w}
+
+	domain := ""
+	for _, c := range r.Changes {
+		if c.Domain != domain {
+			if domain != "" {
+				ew.printf("\n")
+			}
+			domain = c.Domain
+			ew.printf("%s\n", domain)
+		}
+		ew.printf("  %s %s\n", c.sign(), c.detail())
+	}
+	if len(r.Changes) > 0 {
+		ew.printf("\n")
+	}
+
+	if len(r.Skipped) > 0 {
+		skipped := make([]string, 0, len(r.Skipped))
+		for name := range r.Skipped {
+			skipped = append(skipped, name)
+		}
+		sort.Strings(skipped)
+
+		ew.printf("Not compared, as they could not be captured:\n")
+		for _, name := range skipped {
+			ew.printf("  %s: %s\n", name, r.Skipped[name])
+		}
+		ew.printf("\n")
+	}
+
+	if len(r.Changes) == 0 {
+		ew.printf("No changes\n")
+	} else {
+		ew.printf("%d changes in %d domains\n", len(r.Changes), len(r.Domains()))
+	}
+	return ew.err
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// errWriter keeps the first write error, so a sequence of writes can be checked once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+// printf writes formatted text unless a previous write failed.
+func (ew *errWriter) printf(format string, a ...any) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, a...)
+	}
+}
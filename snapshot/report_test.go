@@ -0,0 +1,66 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+func testReport() *Report {
+	return &Report{
+		From: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Changes: []Change{
+			{Domain: "example.com", Kind: KindRecord, Action: Removed, Old: &Record{Name: "old", Type: porkbun.A, Content: "192.0.2.1"}},
+			{Domain: "example.com", Kind: KindNameServers, Action: Modified, Old: []string{"ns1.example.net"}, New: []string{"ns2.example.net"}},
+			{Domain: "example.org", Kind: KindDomain, Action: Added, New: &Domain{Domain: "example.org"}},
+		},
+		Skipped: map[string]string{"example.net": "no API access"},
+	}
+}
+
+func TestReport_WriteText(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testReport().WriteText(&buf))
+	assert.Equal(t, `example.com
+  - record old A "192.0.2.1"
+  ~ nameservers ns1.example.net -> ns2.example.net
+
+example.org
+  + domain with 0 records and 0 forwards
+
+Not compared, as they could not be captured:
+  example.net: no API access
+
+3 changes in 2 domains
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, (&Report{}).WriteText(&buf))
+	assert.Equal(t, "No changes\n", buf.String())
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testReport().WriteJSON(&buf))
+
+	var decoded struct {
+		From    time.Time         `json:"from"`
+		Changes []json.RawMessage `json:"changes"`
+		Skipped map[string]string `json:"skipped"`
+	}
+	if !assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded)) {
+		return
+	}
+	assert.Equal(t, testReport().From, decoded.From)
+	assert.Len(t, decoded.Changes, 3)
+	assert.JSONEq(t, `{"domain":"example.com","kind":"record","action":"removed","old":{"name":"old","type":"A","content":"192.0.2.1"}}`,
+		string(decoded.Changes[0]))
+	assert.JSONEq(t, `{"domain":"example.com","kind":"nameservers","action":"modified","old":["ns1.example.net"],"new":["ns2.example.net"]}`,
+		string(decoded.Changes[1]))
+	assert.Equal(t, map[string]string{"example.net": "no API access"}, decoded.Skipped)
+}
@@ -0,0 +1,132 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+// RestoreOptions defines the configuration options for Plan and Restore.
+type RestoreOptions struct {
+	Domains []string // Domains to restore, all domains of the snapshot if empty.
+	Prune   bool     // Also remove the records and forwards that are not in the snapshot.
+	DryRun  bool     // Only plan the changes, Restore applies nothing.
+}
+
+// Plan compares the snapshot with the live configuration and returns the changes restoring it.
+// Domains that could not be captured in the snapshot are skipped.
+func Plan(ctx context.Context, dns porkbun.DNSAPI, domains porkbun.DomainsAPI, snap *Snapshot, options *RestoreOptions) ([]Change, error) {
+	var opts RestoreOptions
+	if options != nil {
+		opts = *options
+	}
+
+	selected, err := snap.Select(opts.Domains)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for i := range selected.Domains {
+		want := &selected.Domains[i]
+		if want.Error != "" {
+			continue
+		}
+
+		live, err := capture(ctx, dns, domains, want.Domain)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: %s: %w", want.Domain, err)
+		}
+
+		for _, change := range DiffDomain(live, want) {
+			if change.Action == Removed && !opts.Prune {
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// Apply makes the changes through the API, in order. It returns the number of changes applied,
+// which is less than len(changes) if an error stopped it.
+func Apply(ctx context.Context, dns porkbun.DNSAPI, domains porkbun.DomainsAPI, changes []Change) (int, error) {
+	for i, change := range changes {
+		if err := apply(ctx, dns, domains, change); err != nil {
+			return i, fmt.Errorf("snapshot: %s: %w", change, err)
+		}
+	}
+	return len(changes), nil
+}
+
+// Restore plans the changes restoring the snapshot and, unless DryRun is set, applies them. It
+// returns the planned changes.
+func Restore(ctx context.Context, dns porkbun.DNSAPI, domains porkbun.DomainsAPI, snap *Snapshot, options *RestoreOptions) ([]Change, error) {
+	changes, err := Plan(ctx, dns, domains, snap, options)
+	if err != nil {
+		return nil, err
+	}
+	if options != nil && options.DryRun {
+		return changes, nil
+	}
+
+	_, err = Apply(ctx, dns, domains, changes)
+	return changes, err
+}
+
+// apply makes a single change.
+func apply(ctx context.Context, dns porkbun.DNSAPI, domains porkbun.DomainsAPI, change Change) error {
+	domain := change.Domain
+
+	switch change.Kind {
+	case KindNameServers:
+		ns := porkbun.NameServers(change.New.([]string))
+		_, err := domains.UpdateNameServers(ctx, domain, &ns)
+		return err
+
+	case KindRecord:
+		switch change.Action {
+		case Removed:
+			_, err := dns.DeleteRecord(ctx, domain, change.Old.(*Record).ID)
+			return err
+		case Modified:
+			old, r := change.Old.(*Record), change.New.(*Record)
+			_, err := dns.EditRecord(ctx, domain, old.ID, &porkbun.EditRecord{
+				Name:    r.Name,
+				Type:    r.Type,
+				Content: r.Content,
+				TTL:     r.TTL,
+				Prio:    r.Prio,
+			})
+			return err
+		case Added:
+			r := change.New.(*Record)
+			_, err := dns.CreateRecord(ctx, domain, &porkbun.DnsRecord{
+				Name:    r.Name,
+				Type:    r.Type,
+				Content: r.Content,
+				TTL:     r.TTL,
+				Prio:    r.Prio,
+				Notes:   r.Notes,
+			})
+			return err
+		}
+
+	case KindForward:
+		// Forwards cannot be edited, a modified forward is deleted and added again
+		if change.Action == Removed || change.Action == Modified {
+			if _, err := domains.DeleteDomainUrlForward(ctx, domain, change.Old.(*Forward).ID); err != nil {
+				return err
+			}
+		}
+		if change.Action == Added || change.Action == Modified {
+			forward := change.New.(*Forward).UrlForward
+			_, err := domains.AddDomainUrlForward(ctx, domain, &forward)
+			return err
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported change %s %s", change.Kind, change.Action)
+}
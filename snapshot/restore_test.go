@@ -0,0 +1,101 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+func TestRestore(t *testing.T) {
+	server := setupServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	snap, err := Take(ctx, client.Dns, client.Domains, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Break the live configuration
+	records := server.Records("example.com")
+	_, err = client.Dns.DeleteRecord(ctx, "example.com", *records[0].ID)
+	assert.NoError(t, err)
+	_, err = client.Dns.EditRecord(ctx, "example.com", *records[1].ID, &porkbun.EditRecord{Type: porkbun.MX, Content: "mx.example.net", TTL: "900", Prio: "10"})
+	assert.NoError(t, err)
+	extra, err := client.Dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Name: "extra", Type: porkbun.TXT, Content: "stray"})
+	assert.NoError(t, err)
+	_, err = client.Domains.UpdateNameServers(ctx, "example.com", &porkbun.NameServers{"ns.example.net"})
+	assert.NoError(t, err)
+	_, err = client.Domains.DeleteDomainUrlForward(ctx, "example.org", server.Forwards("example.org")[0].Id)
+	assert.NoError(t, err)
+
+	// A dry run changes nothing and leaves the extra record without Prune
+	changes, err := Restore(ctx, client.Dns, client.Domains, snap, &RestoreOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.Len(t, changes, 4)
+	assert.Equal(t, []string{"ns.example.net"}, server.NameServers("example.com"))
+
+	// Restoring a subset only touches those domains
+	changes, err = Restore(ctx, client.Dns, client.Domains, snap, &RestoreOptions{Domains: []string{"example.org"}})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, Change{Domain: "example.org", Kind: KindForward, Action: Added, New: &snap.Domain("example.org").Forwards[0]}, changes[0])
+	}
+	assert.Len(t, server.Forwards("example.org"), 1)
+	assert.Equal(t, []string{"ns.example.net"}, server.NameServers("example.com"))
+
+	changes, err = Restore(ctx, client.Dns, client.Domains, snap, nil)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 3)
+
+	changes, err = Plan(ctx, client.Dns, client.Domains, snap, &RestoreOptions{Prune: true})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, Removed, changes[0].Action)
+		assert.Equal(t, extra.ID, changes[0].Old.(*Record).ID)
+	}
+	n, err := Apply(ctx, client.Dns, client.Domains, changes)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// The live configuration now matches the snapshot
+	changes, err = Plan(ctx, client.Dns, client.Domains, snap, &RestoreOptions{Prune: true})
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	live, err := Take(ctx, client.Dns, client.Domains, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, snap.Domain("example.com").NameServers, live.Domain("example.com").NameServers)
+	assert.Len(t, live.Domain("example.com").Records, 3)
+}
+
+func TestRestore_Errors(t *testing.T) {
+	server := setupServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	snap := &Snapshot{Version: Version, Domains: []Domain{
+		{Domain: "example.com", Records: []Record{{Name: "bad", Type: porkbun.A, Content: "not-an-ip"}}},
+		{Domain: "example.net", Error: "no API access"},
+	}}
+
+	_, err := Restore(ctx, client.Dns, client.Domains, snap, &RestoreOptions{Domains: []string{"example.org"}})
+	assert.ErrorIs(t, err, ErrUnknownDomain)
+
+	// Domains that failed to be captured are skipped
+	changes, err := Plan(ctx, client.Dns, client.Domains, snap, nil)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+
+	n, err := Apply(ctx, client.Dns, client.Domains, changes)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "+ example.com record bad A")
+	assert.Equal(t, 0, n)
+
+	// Domains missing from the account cannot be planned
+	snap.Domains = append(snap.Domains, Domain{Domain: "example.info"})
+	_, err = Plan(ctx, client.Dns, client.Domains, snap, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,324 @@
+// Package snapshot backs up the DNS records, name servers and URL forwards of the domains in a
+// Porkbun account to a versioned JSON archive, and restores them.
+//
+//	snap, err := snapshot.Take(ctx, dns, domains, nil)
+//	if err != nil {
+//		return err
+//	}
+//	err = snap.Save("porkbun-backup.json")
+//
+// Restore compares a snapshot with the live configuration and applies the differences through the
+// create and update APIs. With DryRun set it only returns the planned changes.
+//
+// Diff compares two snapshots, and DiffLive a snapshot with the live configuration, reporting the
+// domains, records, name servers and URL forwards added, removed and modified:
+//
+//	report, err := snapshot.DiffLive(ctx, dns, domains, yesterday, nil)
+//	if err != nil {
+//		return err
+//	}
+//	err = report.WriteText(os.Stdout)
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+)
+
+const (
+	// Version is the archive format version written by this package.
+	Version = 1
+	// DefaultConcurrency is the number of domains captured in parallel.
+	DefaultConcurrency = 4
+)
+
+// Errors returned when reading archives and selecting domains.
+var (
+	ErrUnsupportedVersion = errors.New("snapshot: unsupported version")
+	ErrUnknownDomain      = errors.New("snapshot: domain not in snapshot")
+)
+
+// Snapshot is the archive of an account's configuration.
+type Snapshot struct {
+	Version   int       `json:"version"`    // Archive format version.
+	CreatedAt time.Time `json:"created_at"` // Time the snapshot was taken.
+	Domains   []Domain  `json:"domains"`    // Domains sorted by name.
+}
+
+// Domain is the configuration of a single domain.
+type Domain struct {
+	Domain      string    `json:"domain"`          // Domain name, lowercase.
+	Error       string    `json:"error,omitempty"` // Why the domain could not be captured, in which case it holds no configuration.
+	NameServers []string  `json:"nameservers"`     // Name servers, lowercase and sorted.
+	Records     []Record  `json:"records"`         // DNS records, sorted.
+	Forwards    []Forward `json:"forwards"`        // URL forwards, sorted by subdomain.
+}
+
+// Record is a DNS record with its name relative to the domain.
+type Record struct {
+	ID      int64                 `json:"id,omitempty"`    // Record ID at capture time, for reference only.
+	Name    string                `json:"name"`            // Subdomain, empty for the apex.
+	Type    porkbun.DnsRecordType `json:"type"`            // Record type.
+	Content string                `json:"content"`         // Record content.
+	TTL     string                `json:"ttl,omitempty"`   // Time to live in seconds.
+	Prio    string                `json:"prio,omitempty"`  // Priority of MX and SRV records.
+	Notes   string                `json:"notes,omitempty"` // Notes.
+}
+
+// Forward is a URL forward.
+type Forward struct {
+	ID string `json:"id,omitempty"` // Forward ID at capture time, for reference only.
+	porkbun.UrlForward
+}
+
+// Read decodes an archive, rejecting versions newer than Version.
+func Read(r io.Reader) (*Snapshot, error) {
+	snap := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(snap); err != nil {
+		return nil, fmt.Errorf("snapshot: decoding: %w", err)
+	}
+	if snap.Version < 1 || snap.Version > Version {
+		return nil, fmt.Errorf("%w %d, expected at most %d", ErrUnsupportedVersion, snap.Version, Version)
+	}
+	for i := range snap.Domains {
+		snap.Domains[i].normalize()
+	}
+	return snap, nil
+}
+
+// Load reads an archive file.
+func Load(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Write encodes the snapshot as indented JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// Save writes the snapshot to a file readable only by its owner, replacing it atomically.
+func (s *Snapshot) Save(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = s.Write(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Domain returns the named domain, or nil.
+func (s *Snapshot) Domain(name string) *Domain {
+	name = porkbun.NormalizeName(name)
+	for i := range s.Domains {
+		if s.Domains[i].Domain == name {
+			return &s.Domains[i]
+		}
+	}
+	return nil
+}
+
+// Failed returns the domains that could not be captured.
+func (s *Snapshot) Failed() []Domain {
+	var failed []Domain
+	for _, d := range s.Domains {
+		if d.Error != "" {
+			failed = append(failed, d)
+		}
+	}
+	return failed
+}
+
+// Select returns a snapshot of the named domains only, or all domains if names is empty.
+func (s *Snapshot) Select(names []string) (*Snapshot, error) {
+	if len(names) == 0 {
+		return s, nil
+	}
+
+	selected := &Snapshot{Version: s.Version, CreatedAt: s.CreatedAt}
+	for _, name := range names {
+		d := s.Domain(name)
+		if d == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownDomain, name)
+		}
+		selected.Domains = append(selected.Domains, *d)
+	}
+	sort.Slice(selected.Domains, func(i, j int) bool { return selected.Domains[i].Domain < selected.Domains[j].Domain })
+	return selected, nil
+}
+
+// Options defines the configuration options for Take.
+type Options struct {
+	Domains     []string // Domains to capture, all domains in the account if empty.
+	Concurrency int      // Number of domains captured in parallel, defaults to DefaultConcurrency.
+}
+
+// Take captures the configuration of the domains. Domains that cannot be captured, for example
+// because API access is not enabled for them, are kept with their Error set; an error is only
+// returned if the domains cannot be listed or the context is cancelled.
+func Take(ctx context.Context, dns porkbun.DNSAPI, domains porkbun.DomainsAPI, options *Options) (*Snapshot, error) {
+	var opts Options
+	if options != nil {
+		opts = *options
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+
+	names := opts.Domains
+	if len(names) == 0 {
+		var err error
+		if names, err = listDomains(ctx, domains); err != nil {
+			return nil, err
+		}
+	}
+
+	snap := &Snapshot{Version: Version, CreatedAt: time.Now().UTC().Truncate(time.Second), Domains: make([]Domain, len(names))}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				d, err := capture(ctx, dns, domains, names[j])
+				if err != nil {
+					d = &Domain{Domain: porkbun.NormalizeName(names[j]), Error: err.Error()}
+				}
+				snap.Domains[j] = *d
+			}
+		}()
+	}
+
+	for i := range names {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(snap.Domains, func(i, j int) bool { return snap.Domains[i].Domain < snap.Domains[j].Domain })
+	return snap, nil
+}
+
+// listDomains returns the names of all domains in the account.
+func listDomains(ctx context.Context, domains porkbun.DomainsAPI) ([]string, error) {
+	all, err := domains.ListAllDomains(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: listing domains: %w", err)
+	}
+
+	names := make([]string, len(all))
+	for i, d := range all {
+		names[i] = d.Domain
+	}
+	return names, nil
+}
+
+// capture retrieves the live configuration of a domain.
+func capture(ctx context.Context, dns porkbun.DNSAPI, domains porkbun.DomainsAPI, name string) (*Domain, error) {
+	d := &Domain{Domain: porkbun.NormalizeName(name)}
+
+	records, err := dns.GetRecords(ctx, d.Domain, nil)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving records: %w", err)
+	}
+	for _, r := range records.Records {
+		record := Record{
+			Name:    porkbun.RelativeName(r.Name, d.Domain),
+			Type:    r.Type,
+			Content: r.Content,
+			TTL:     r.TTL,
+			Prio:    r.Prio,
+			Notes:   r.Notes,
+		}
+		if r.ID != nil {
+			record.ID = *r.ID
+		}
+		d.Records = append(d.Records, record)
+	}
+
+	ns, err := domains.GetNameServers(ctx, d.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving name servers: %w", err)
+	}
+	d.NameServers = ns.NS
+
+	forwards, err := domains.GetDomainURLForwarding(ctx, d.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving URL forwards: %w", err)
+	}
+	for _, f := range forwards.Forwards {
+		d.Forwards = append(d.Forwards, Forward{ID: f.Id, UrlForward: f.UrlForward})
+	}
+
+	d.normalize()
+	return d, nil
+}
+
+// normalize puts the domain in canonical form, so archives of unchanged configurations are identical.
+func (d *Domain) normalize() {
+	d.Domain = porkbun.NormalizeName(d.Domain)
+
+	if d.NameServers == nil {
+		d.NameServers = []string{}
+	}
+	for i, ns := range d.NameServers {
+		d.NameServers[i] = porkbun.NormalizeName(ns)
+	}
+	sort.Strings(d.NameServers)
+
+	if d.Records == nil {
+		d.Records = []Record{}
+	}
+	for i := range d.Records {
+		d.Records[i].Name = porkbun.NormalizeName(d.Records[i].Name)
+	}
+	sort.SliceStable(d.Records, func(i, j int) bool { return d.Records[i].key() < d.Records[j].key() })
+
+	if d.Forwards == nil {
+		d.Forwards = []Forward{}
+	}
+	for i := range d.Forwards {
+		d.Forwards[i].Subdomain = porkbun.NormalizeName(d.Forwards[i].Subdomain)
+	}
+	sort.SliceStable(d.Forwards, func(i, j int) bool { return d.Forwards[i].Subdomain < d.Forwards[j].Subdomain })
+}
+
+// key identifies the record independently of its ID and settings.
+func (r Record) key() string {
+	return r.Name + "\x00" + string(r.Type) + "\x00" + r.Content
+}
@@ -0,0 +1,145 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+)
+
+// setupServer returns a server with two configured domains.
+func setupServer(t *testing.T) *porkbuntest.Server {
+	server := porkbuntest.NewServer(nil)
+	t.Cleanup(server.Close)
+
+	server.AddDomain(porkbun.Domain{Domain: "example.com"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1", TTL: "3600"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.MX, Content: "mx.example.net", Prio: "10", Notes: "primary"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Type: porkbun.A, Content: "192.0.2.1"})
+
+	server.AddDomain(porkbun.Domain{Domain: "example.org"})
+	_, err := server.Client().Domains.AddDomainUrlForward(context.Background(), "example.org", &porkbun.UrlForward{
+		Subdomain: "www", Location: "https://example.com", Type: porkbun.Permanent, IncludePath: "yes", Wildcard: "no",
+	})
+	assert.NoError(t, err)
+	return server
+}
+
+func TestTake(t *testing.T) {
+	server := setupServer(t)
+	client := server.Client()
+
+	snap, err := Take(context.Background(), client.Dns, client.Domains, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Version, snap.Version)
+	assert.False(t, snap.CreatedAt.IsZero())
+	assert.Empty(t, snap.Failed())
+
+	if assert.Len(t, snap.Domains, 2) {
+		com := snap.Domains[0]
+		assert.Equal(t, "example.com", com.Domain)
+		assert.Equal(t, server.NameServers("example.com"), com.NameServers)
+		assert.Empty(t, com.Forwards)
+		if assert.Len(t, com.Records, 3) {
+			// Sorted by name, type and content, with names relative to the domain
+			assert.Equal(t, "", com.Records[0].Name)
+			assert.Equal(t, porkbun.A, com.Records[0].Type)
+			assert.Equal(t, porkbun.MX, com.Records[1].Type)
+			assert.Equal(t, "primary", com.Records[1].Notes)
+			assert.Equal(t, "www", com.Records[2].Name)
+			assert.Equal(t, "3600", com.Records[2].TTL)
+			assert.NotZero(t, com.Records[2].ID)
+		}
+
+		org := snap.Domains[1]
+		assert.Empty(t, org.Records)
+		if assert.Len(t, org.Forwards, 1) {
+			assert.Equal(t, "https://example.com", org.Forwards[0].Location)
+			assert.NotEmpty(t, org.Forwards[0].ID)
+		}
+	}
+}
+
+func TestTake_Domains(t *testing.T) {
+	server := setupServer(t)
+	client := server.Client()
+
+	snap, err := Take(context.Background(), client.Dns, client.Domains, &Options{Domains: []string{"Example.org", "example.net"}, Concurrency: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, snap.Domains, 2) {
+		// Domains that cannot be captured are kept with an error
+		assert.Equal(t, "example.net", snap.Domains[0].Domain)
+		assert.Contains(t, snap.Domains[0].Error, "retrieving records")
+		assert.Equal(t, "example.org", snap.Domains[1].Domain)
+	}
+	assert.Len(t, snap.Failed(), 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Take(ctx, client.Dns, client.Domains, nil)
+	assert.Error(t, err)
+}
+
+func TestWriteRead(t *testing.T) {
+	client := setupServer(t).Client()
+	snap, err := Take(context.Background(), client.Dns, client.Domains, nil)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, snap.Write(&buf))
+	assert.Contains(t, buf.String(), `"version": 1`)
+	assert.Contains(t, buf.String(), `"includePath": "yes"`)
+
+	read, err := Read(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, snap, read)
+
+	path := filepath.Join(t.TempDir(), "backup.json")
+	assert.NoError(t, snap.Save(path))
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, snap, loaded)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestRead_Invalid(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"version": 2, "domains": []}`))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	_, err = Read(strings.NewReader(`{"domains": []}`))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	_, err = Read(strings.NewReader(`[`))
+	assert.Error(t, err)
+
+	// Hand-written archives are normalized
+	snap, err := Read(strings.NewReader(`{"version": 1, "domains": [{"domain": "Example.COM.", "nameservers": ["NS2.example.net", "ns1.example.net"]}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns1.example.net", "ns2.example.net"}, snap.Domain("example.com").NameServers)
+	assert.Equal(t, []Record{}, snap.Domain("example.com").Records)
+}
+
+func TestSelect(t *testing.T) {
+	snap := &Snapshot{Version: Version, Domains: []Domain{{Domain: "example.com"}, {Domain: "example.org"}}}
+
+	selected, err := snap.Select([]string{"EXAMPLE.org"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Domain{{Domain: "example.org"}}, selected.Domains)
+
+	selected, err = snap.Select(nil)
+	assert.NoError(t, err)
+	assert.Same(t, snap, selected)
+
+	_, err = snap.Select([]string{"example.net"})
+	assert.ErrorIs(t, err, ErrUnknownDomain)
+	assert.Nil(t, snap.Domain("example.net"))
+}
@@ -0,0 +1,164 @@
+package porkbun
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Errors returned when an SSL bundle cannot be parsed.
+var (
+	ErrNoCertificate  = errors.New("ssl bundle contains no certificate")
+	ErrNoPrivateKey   = errors.New("ssl bundle contains no private key")
+	ErrKeyMismatch    = errors.New("private key does not match any certificate in the ssl bundle")
+	ErrUnsupportedKey = errors.New("unsupported private key type")
+)
+
+// Certificates parses the certificate chain, returning the leaf certificate first followed by the
+// rest of the chain. The leaf is the certificate matching the private key.
+func (r *SslRetrieveResponse) Certificates() ([]*x509.Certificate, error) {
+	certs, _, err := r.parseBundle()
+	return certs, err
+}
+
+// Leaf returns the parsed leaf certificate of the bundle.
+func (r *SslRetrieveResponse) Leaf() (*x509.Certificate, error) {
+	certs, err := r.Certificates()
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// Chain returns the parsed intermediate certificates of the bundle, excluding the leaf.
+func (r *SslRetrieveResponse) Chain() ([]*x509.Certificate, error) {
+	certs, err := r.Certificates()
+	if err != nil {
+		return nil, err
+	}
+	return certs[1:], nil
+}
+
+// PrivateKey parses the private key of the bundle. PKCS #8, PKCS #1 and SEC 1 (EC) keys are supported,
+// and the returned key is an *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey.
+func (r *SslRetrieveResponse) PrivateKey() (crypto.PrivateKey, error) {
+	rest := []byte(r.Privatekey)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, ErrNoPrivateKey
+		}
+
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parsing private key: %w", err)
+			}
+			switch key.(type) {
+			case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+				return key, nil
+			}
+			return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+		case "RSA PRIVATE KEY":
+			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parsing private key: %w", err)
+			}
+			return key, nil
+		case "EC PRIVATE KEY":
+			key, err := x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parsing private key: %w", err)
+			}
+			return key, nil
+		}
+	}
+}
+
+// TLSCertificate returns the bundle as a tls.Certificate, ready to be used in a tls.Config.
+// The certificate chain starts with the leaf, and Leaf is populated.
+func (r *SslRetrieveResponse) TLSCertificate() (tls.Certificate, error) {
+	certs, key, err := r.parseBundle()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	cert := tls.Certificate{
+		PrivateKey: key,
+		Leaf:       certs[0],
+	}
+	for _, c := range certs {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+
+	return cert, nil
+}
+
+// parseBundle parses the certificates and private key, ordering the certificates leaf first.
+func (r *SslRetrieveResponse) parseBundle() ([]*x509.Certificate, crypto.PrivateKey, error) {
+	certs, err := parseCertificates(r.Certificatechain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := r.PrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, cert := range certs {
+		if publicKeyMatches(cert, key) {
+			// Move the leaf to the front, keeping the order of the remaining chain
+			return append([]*x509.Certificate{cert}, append(certs[:i:i], certs[i+1:]...)...), key, nil
+		}
+	}
+
+	return nil, nil, ErrKeyMismatch
+}
+
+// parseCertificates decodes all PEM encoded certificates in the data, in order.
+func parseCertificates(data string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificate %d: %w", len(certs)+1, err)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, ErrNoCertificate
+	}
+	return certs, nil
+}
+
+// publicKeyMatches reports whether the certificate's public key belongs to the private key.
+func publicKeyMatches(cert *x509.Certificate, key crypto.PrivateKey) bool {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return false
+	}
+
+	public, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && public.Equal(cert.PublicKey)
+}
@@ -0,0 +1,150 @@
+package porkbun
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go/internal/testcert"
+)
+
+func testBundle(t *testing.T) (*testcert.Bundle, *SslRetrieveResponse) {
+	bundle, err := testcert.Generate([]string{"example.com", "www.example.com"}, time.Now().Add(-time.Hour), time.Now().Add(90*24*time.Hour))
+	assert.NoError(t, err)
+
+	return bundle, &SslRetrieveResponse{
+		BaseResponse:     BaseResponse{Status: "SUCCESS"},
+		Certificatechain: bundle.CertificateChain,
+		Privatekey:       bundle.PrivateKey,
+		Publickey:        bundle.PublicKey,
+	}
+}
+
+func TestSslRetrieveResponse_Certificates(t *testing.T) {
+	bundle, resp := testBundle(t)
+
+	certs, err := resp.Certificates()
+	assert.NoError(t, err)
+	assert.Len(t, certs, 2)
+	assert.Equal(t, bundle.Leaf.Raw, certs[0].Raw)
+	assert.Equal(t, "Test Intermediate CA", certs[1].Subject.CommonName)
+
+	leaf, err := resp.Leaf()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "www.example.com"}, leaf.DNSNames)
+
+	chain, err := resp.Chain()
+	assert.NoError(t, err)
+	assert.Len(t, chain, 1)
+	assert.Equal(t, certs[1].Raw, chain[0].Raw)
+}
+
+func TestSslRetrieveResponse_CertificatesLeafNotFirst(t *testing.T) {
+	bundle, resp := testBundle(t)
+
+	// Put the intermediate before the leaf
+	parts := strings.SplitAfter(resp.Certificatechain, "-----END CERTIFICATE-----\n")
+	resp.Certificatechain = parts[1] + parts[0]
+
+	leaf, err := resp.Leaf()
+	assert.NoError(t, err)
+	assert.Equal(t, bundle.Leaf.Raw, leaf.Raw)
+}
+
+func TestSslRetrieveResponse_PrivateKey(t *testing.T) {
+	_, resp := testBundle(t)
+
+	key, err := resp.PrivateKey()
+	assert.NoError(t, err)
+	assert.IsType(t, &ecdsa.PrivateKey{}, key)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	resp.Privatekey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	key, err = resp.PrivateKey()
+	assert.NoError(t, err)
+	assert.IsType(t, &rsa.PrivateKey{}, key)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	assert.NoError(t, err)
+	resp.Privatekey = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}))
+	key, err = resp.PrivateKey()
+	assert.NoError(t, err)
+	assert.IsType(t, &ecdsa.PrivateKey{}, key)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	assert.NoError(t, err)
+	resp.Privatekey = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDER}))
+	key, err = resp.PrivateKey()
+	assert.NoError(t, err)
+	assert.IsType(t, ed25519.PrivateKey{}, key)
+}
+
+func TestSslRetrieveResponse_TLSCertificate(t *testing.T) {
+	bundle, resp := testBundle(t)
+
+	cert, err := resp.TLSCertificate()
+	assert.NoError(t, err)
+	assert.Len(t, cert.Certificate, 2)
+	assert.Equal(t, bundle.Leaf.Raw, cert.Certificate[0])
+	assert.Equal(t, bundle.Leaf.Raw, cert.Leaf.Raw)
+
+	// The result must be equivalent to what crypto/tls builds from the same PEM data
+	expected, err := tls.X509KeyPair([]byte(bundle.CertificateChain), []byte(bundle.PrivateKey))
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Certificate, cert.Certificate)
+}
+
+func TestSslRetrieveResponse_Malformed(t *testing.T) {
+	_, valid := testBundle(t)
+	other, _ := testBundle(t)
+
+	tests := []struct {
+		name   string
+		modify func(r *SslRetrieveResponse)
+		err    error
+		msg    string
+	}{
+		{"no certificate", func(r *SslRetrieveResponse) { r.Certificatechain = "" }, ErrNoCertificate, ""},
+		{"placeholder fixture", func(r *SslRetrieveResponse) {
+			r.Certificatechain = "----BEGIN CERTIFICATE-----\n...-----END CERTIFICATE-----\n"
+		}, ErrNoCertificate, ""},
+		{"no private key", func(r *SslRetrieveResponse) { r.Privatekey = "not a key" }, ErrNoPrivateKey, ""},
+		{"mismatched key", func(r *SslRetrieveResponse) { r.Privatekey = other.PrivateKey }, ErrKeyMismatch, ""},
+		{"corrupt certificate", func(r *SslRetrieveResponse) {
+			r.Certificatechain = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")}))
+		}, nil, "parsing certificate 1"},
+		{"corrupt key", func(r *SslRetrieveResponse) {
+			r.Privatekey = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("garbage")}))
+		}, nil, "parsing private key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := *valid
+			tt.modify(&resp)
+
+			_, err := resp.TLSCertificate()
+			assert.Error(t, err)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			}
+			if tt.msg != "" {
+				assert.Contains(t, err.Error(), tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Package sslexport converts Porkbun SSL bundles into formats consumed by other systems,
+// such as PKCS #12 keystores and Kubernetes TLS Secrets.
+package sslexport
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// ErrEmptyPassword is returned when a PKCS #12 file is requested without a password.
+var ErrEmptyPassword = errors.New("sslexport: a PKCS #12 password is required")
+
+// PKCS12 encodes the bundle as a password protected PKCS #12 file holding the private key,
+// the leaf certificate and the intermediate chain. It uses AES-256 and SHA-256, which is
+// supported by Java 8u301 and later and OpenSSL 1.1.1 and later.
+func PKCS12(resp *porkbun.SslRetrieveResponse, password string) ([]byte, error) {
+	return encodePKCS12(pkcs12.Modern, resp, password)
+}
+
+// PKCS12Legacy is like PKCS12 but uses the legacy 3DES encryption, for older Java and OpenSSL versions.
+func PKCS12Legacy(resp *porkbun.SslRetrieveResponse, password string) ([]byte, error) {
+	return encodePKCS12(pkcs12.LegacyDES, resp, password)
+}
+
+// encodePKCS12 encodes the bundle using the given encoder.
+func encodePKCS12(encoder *pkcs12.Encoder, resp *porkbun.SslRetrieveResponse, password string) ([]byte, error) {
+	if password == "" {
+		return nil, ErrEmptyPassword
+	}
+
+	certs, err := resp.Certificates()
+	if err != nil {
+		return nil, fmt.Errorf("sslexport: %w", err)
+	}
+	key, err := resp.PrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("sslexport: %w", err)
+	}
+
+	data, err := encoder.Encode(key, certs[0], certs[1:], password)
+	if err != nil {
+		return nil, fmt.Errorf("sslexport: encoding PKCS #12: %w", err)
+	}
+	return data, nil
+}
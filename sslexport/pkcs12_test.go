@@ -0,0 +1,59 @@
+package sslexport
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/internal/testcert"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func generate(t *testing.T) (*porkbun.SslRetrieveResponse, *testcert.Bundle) {
+	bundle, err := testcert.Generate([]string{"example.com", "*.example.com"}, time.Now(), time.Now().Add(90*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &porkbun.SslRetrieveResponse{
+		Certificatechain: bundle.CertificateChain,
+		Privatekey:       bundle.PrivateKey,
+		Publickey:        bundle.PublicKey,
+	}, bundle
+}
+
+func TestPKCS12(t *testing.T) {
+	resp, bundle := generate(t)
+
+	for name, encode := range map[string]func(*porkbun.SslRetrieveResponse, string) ([]byte, error){
+		"modern": PKCS12,
+		"legacy": PKCS12Legacy,
+	} {
+		data, err := encode(resp, "changeit")
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+
+		key, leaf, chain, err := pkcs12.DecodeChain(data, "changeit")
+		if assert.NoError(t, err, name) {
+			assert.True(t, leaf.Equal(bundle.Leaf), name)
+			assert.Len(t, chain, 1, name)
+			assert.Equal(t, "Test Intermediate CA", chain[0].Subject.CommonName, name)
+			assert.True(t, key.(*ecdsa.PrivateKey).PublicKey.Equal(leaf.PublicKey), name)
+		}
+
+		_, _, _, err = pkcs12.DecodeChain(data, "wrong")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestPKCS12Errors(t *testing.T) {
+	resp, _ := generate(t)
+
+	_, err := PKCS12(resp, "")
+	assert.ErrorIs(t, err, ErrEmptyPassword)
+
+	_, err = PKCS12(&porkbun.SslRetrieveResponse{Privatekey: resp.Privatekey}, "changeit")
+	assert.ErrorIs(t, err, porkbun.ErrNoCertificate)
+}
@@ -0,0 +1,98 @@
+package sslexport
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"gopkg.in/yaml.v3"
+)
+
+// SecretTypeTLS is the Kubernetes Secret type for TLS certificates.
+const SecretTypeTLS = "kubernetes.io/tls"
+
+// Keys of the Secret data.
+const (
+	TLSCertKey = "tls.crt"
+	TLSKeyKey  = "tls.key"
+)
+
+// SecretOptions defines the metadata of the generated Secret.
+type SecretOptions struct {
+	Name        string            // Name of the Secret, required.
+	Namespace   string            // Namespace of the Secret, omitted if empty.
+	Labels      map[string]string // Labels added to the Secret.
+	Annotations map[string]string // Annotations added to the Secret.
+}
+
+// ObjectMeta is the subset of the Kubernetes object metadata used by Secret.
+type ObjectMeta struct {
+	Name        string            `json:"name" yaml:"name"`
+	Namespace   string            `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// Secret is a Kubernetes Secret manifest. Data values are base64 encoded, as in the Kubernetes API.
+type Secret struct {
+	APIVersion string            `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string            `json:"kind" yaml:"kind"`
+	Metadata   ObjectMeta        `json:"metadata" yaml:"metadata"`
+	Type       string            `json:"type" yaml:"type"`
+	Data       map[string]string `json:"data" yaml:"data"`
+}
+
+// NewSecret builds a kubernetes.io/tls Secret from the bundle. tls.crt holds the leaf certificate
+// followed by the intermediate chain, and tls.key holds the private key.
+func NewSecret(resp *porkbun.SslRetrieveResponse, options *SecretOptions) (*Secret, error) {
+	if options == nil || options.Name == "" {
+		return nil, fmt.Errorf("sslexport: a Secret name is required")
+	}
+
+	certs, err := resp.Certificates()
+	if err != nil {
+		return nil, fmt.Errorf("sslexport: %w", err)
+	}
+	if _, err := resp.PrivateKey(); err != nil {
+		return nil, fmt.Errorf("sslexport: %w", err)
+	}
+
+	var chain []byte
+	for _, cert := range certs {
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	key := strings.TrimSpace(resp.Privatekey) + "\n"
+
+	return &Secret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: ObjectMeta{
+			Name:        options.Name,
+			Namespace:   options.Namespace,
+			Labels:      options.Labels,
+			Annotations: options.Annotations,
+		},
+		Type: SecretTypeTLS,
+		Data: map[string]string{
+			TLSCertKey: base64.StdEncoding.EncodeToString(chain),
+			TLSKeyKey:  base64.StdEncoding.EncodeToString([]byte(key)),
+		},
+	}, nil
+}
+
+// JSON returns the Secret as an indented JSON manifest.
+func (s *Secret) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// YAML returns the Secret as a YAML manifest, suitable for kubectl apply.
+func (s *Secret) YAML() ([]byte, error) {
+	return yaml.Marshal(s)
+}
@@ -0,0 +1,58 @@
+package sslexport
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"gopkg.in/yaml.v3"
+)
+
+func TestNewSecret(t *testing.T) {
+	resp, bundle := generate(t)
+
+	secret, err := NewSecret(resp, &SecretOptions{
+		Name:      "example-com-tls",
+		Namespace: "web",
+		Labels:    map[string]string{"app": "web"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "v1", secret.APIVersion)
+	assert.Equal(t, "Secret", secret.Kind)
+	assert.Equal(t, SecretTypeTLS, secret.Type)
+
+	crt, _ := base64.StdEncoding.DecodeString(secret.Data[TLSCertKey])
+	key, _ := base64.StdEncoding.DecodeString(secret.Data[TLSKeyKey])
+	assert.Equal(t, strings.ReplaceAll(bundle.CertificateChain, "\n\n", "\n"), string(crt))
+	assert.Equal(t, bundle.PrivateKey, string(key))
+
+	data, err := secret.YAML()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "apiVersion: v1\nkind: Secret\nmetadata:\n    name: example-com-tls\n    namespace: web\n"))
+	var fromYAML Secret
+	assert.NoError(t, yaml.Unmarshal(data, &fromYAML))
+	assert.Equal(t, *secret, fromYAML)
+
+	data, err = secret.JSON()
+	assert.NoError(t, err)
+	var fromJSON map[string]any
+	assert.NoError(t, json.Unmarshal(data, &fromJSON))
+	assert.Equal(t, "kubernetes.io/tls", fromJSON["type"])
+	assert.Equal(t, map[string]any{"name": "example-com-tls", "namespace": "web", "labels": map[string]any{"app": "web"}}, fromJSON["metadata"])
+}
+
+func TestNewSecretErrors(t *testing.T) {
+	resp, _ := generate(t)
+
+	_, err := NewSecret(resp, nil)
+	assert.Error(t, err)
+
+	_, err = NewSecret(&porkbun.SslRetrieveResponse{Certificatechain: resp.Certificatechain}, &SecretOptions{Name: "tls"})
+	assert.ErrorIs(t, err, porkbun.ErrNoPrivateKey)
+}
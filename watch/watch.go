@@ -0,0 +1,343 @@
+// Package watch polls a Porkbun account and emits an event for every change to its domains, DNS
+// records, name servers and URL forwards, such as edits made in the Porkbun web UI.
+//
+//	w := watch.NewWatcher(client.Dns, client.Domains, &watch.Options{Interval: time.Minute})
+//	err := w.Run(ctx, func(e watch.Event) {
+//		log.Println(e)
+//	})
+//
+// The first poll records the current state and emits no events. Each later poll is compared with
+// the previous one.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/snapshot"
+)
+
+const (
+	// DefaultInterval is the default time between two successful polls.
+	DefaultInterval = 5 * time.Minute
+	// DefaultMinBackoff is the default wait after the first failed poll.
+	DefaultMinBackoff = 30 * time.Second
+	// DefaultMaxBackoff is the default upper limit of the wait between failed polls.
+	DefaultMaxBackoff = 30 * time.Minute
+)
+
+// EventType is the kind of change an Event reports.
+type EventType string
+
+// Constants representing the event types.
+const (
+	DomainAdded        EventType = "domain_added"        // A domain appeared in the account
+	DomainRemoved      EventType = "domain_removed"      // A domain left the account
+	DomainModified     EventType = "domain_modified"     // The status, expiry, lock, privacy or auto-renewal of a domain changed
+	NameServersChanged EventType = "nameservers_changed" // The name servers of a domain changed
+	RecordAdded        EventType = "record_added"        // A DNS record was created
+	RecordRemoved      EventType = "record_removed"      // A DNS record was deleted
+	RecordModified     EventType = "record_modified"     // The TTL or priority of a DNS record changed
+	ForwardAdded       EventType = "forward_added"       // A URL forward was added
+	ForwardRemoved     EventType = "forward_removed"     // A URL forward was deleted
+	ForwardModified    EventType = "forward_modified"    // A URL forward was replaced
+)
+
+// changeTypes maps the event types of configuration changes to the snapshot changes they report.
+var changeTypes = map[EventType]struct {
+	kind   snapshot.Kind
+	action snapshot.Action
+}{
+	NameServersChanged: {snapshot.KindNameServers, snapshot.Modified},
+	RecordAdded:        {snapshot.KindRecord, snapshot.Added},
+	RecordRemoved:      {snapshot.KindRecord, snapshot.Removed},
+	RecordModified:     {snapshot.KindRecord, snapshot.Modified},
+	ForwardAdded:       {snapshot.KindForward, snapshot.Added},
+	ForwardRemoved:     {snapshot.KindForward, snapshot.Removed},
+	ForwardModified:    {snapshot.KindForward, snapshot.Modified},
+}
+
+// Event is a change detected by a poll. Old and New depend on the type: *porkbun.Domain for domain
+// events, []string for name servers, *snapshot.Record for records and *snapshot.Forward for URL
+// forwards. Old is nil for additions and New is nil for removals.
+type Event struct {
+	Type   EventType `json:"type"`          // What changed.
+	Domain string    `json:"domain"`        // Domain name.
+	Time   time.Time `json:"time"`          // Time of the poll that detected the change.
+	Old    any       `json:"old,omitempty"` // Value before the change.
+	New    any       `json:"new,omitempty"` // Value after the change.
+}
+
+// String describes the event on one line, in the form of snapshot.Change.
+func (e Event) String() string {
+	if t, ok := changeTypes[e.Type]; ok {
+		return snapshot.Change{Domain: e.Domain, Kind: t.kind, Action: t.action, Old: e.Old, New: e.New}.String()
+	}
+
+	switch e.Type {
+	case DomainAdded:
+		return "+ " + e.Domain + " domain"
+	case DomainRemoved:
+		return "- " + e.Domain + " domain"
+	case DomainModified:
+		old, okOld := e.Old.(*porkbun.Domain)
+		cur, okNew := e.New.(*porkbun.Domain)
+		if okOld && okNew {
+			return "~ " + e.Domain + " domain " + strings.Join(domainChanges(old, cur), ", ")
+		}
+	}
+	return fmt.Sprintf("%s %s", e.Type, e.Domain)
+}
+
+// Options defines the configuration options for the Watcher.
+type Options struct {
+	Domains     []string      // Domains to watch, all domains in the account if empty.
+	Interval    time.Duration // Time between successful polls, defaults to DefaultInterval.
+	MinBackoff  time.Duration // First wait after a failure, defaults to DefaultMinBackoff.
+	MaxBackoff  time.Duration // Upper limit of the wait after failures, defaults to DefaultMaxBackoff.
+	Concurrency int           // Number of domains polled in parallel, defaults to snapshot.DefaultConcurrency.
+	Logger      *log.Logger   // Logger for poll errors, discarded if nil.
+}
+
+// Watcher polls the account and reports the changes between polls. It is not safe for concurrent use.
+type Watcher struct {
+	dnsAPI     porkbun.DNSAPI
+	domainsAPI porkbun.DomainsAPI
+	options    Options
+
+	domains map[string]porkbun.Domain // Domains of the previous poll
+	last    *snapshot.Snapshot        // Configuration of the previous poll, nil before the first one
+
+	// after is used to wait between polls and is replaced in tests.
+	after func(time.Duration) <-chan time.Time
+}
+
+// NewWatcher initializes a new Watcher using the provided DNS and domains APIs, usually client.Dns
+// and client.Domains, and options.
+func NewWatcher(dns porkbun.DNSAPI, domains porkbun.DomainsAPI, options *Options) *Watcher {
+	w := &Watcher{
+		dnsAPI:     dns,
+		domainsAPI: domains,
+		after:      time.After,
+	}
+
+	if options != nil {
+		w.options = *options
+	}
+	if w.options.Interval <= 0 {
+		w.options.Interval = DefaultInterval
+	}
+	if w.options.MinBackoff <= 0 {
+		w.options.MinBackoff = DefaultMinBackoff
+	}
+	if w.options.MaxBackoff < w.options.MinBackoff {
+		w.options.MaxBackoff = DefaultMaxBackoff
+		if w.options.MaxBackoff < w.options.MinBackoff {
+			w.options.MaxBackoff = w.options.MinBackoff
+		}
+	}
+	if w.options.Logger == nil {
+		w.options.Logger = log.New(io.Discard, "", 0)
+	}
+
+	return w
+}
+
+// Poll retrieves the current state and returns the changes since the previous poll, none on the
+// first one. Domains whose configuration cannot be retrieved keep their previous state, so they
+// are compared again on the next poll, and are reported in the returned error along with any
+// events.
+func (w *Watcher) Poll(ctx context.Context) ([]Event, error) {
+	domains, err := w.listDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(domains))
+	current := make(map[string]porkbun.Domain, len(domains))
+	for i, d := range domains {
+		names[i] = d.Domain
+		current[d.Domain] = d
+	}
+
+	snap := &snapshot.Snapshot{Version: snapshot.Version, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	if len(names) > 0 {
+		// With no names, Take would capture all domains of the account
+		snap, err = snapshot.Take(ctx, w.dnsAPI, w.domainsAPI, &snapshot.Options{Domains: names, Concurrency: w.options.Concurrency})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for i := range snap.Domains {
+		d := &snap.Domains[i]
+		if d.Error == "" {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", d.Domain, d.Error))
+		if w.last != nil {
+			if prev := w.last.Domain(d.Domain); prev != nil {
+				*d = *prev
+			}
+		}
+	}
+
+	var events []Event
+	if w.last != nil {
+		events = w.compare(snap, current)
+	}
+	w.last, w.domains = snap, current
+
+	return events, errors.Join(errs...)
+}
+
+// compare returns the events between the previous poll and the current one.
+func (w *Watcher) compare(snap *snapshot.Snapshot, domains map[string]porkbun.Domain) []Event {
+	var events []Event
+	for _, c := range snapshot.Diff(w.last, snap).Changes {
+		event := Event{Domain: c.Domain, Time: snap.CreatedAt, Old: c.Old, New: c.New}
+
+		switch {
+		case c.Kind == snapshot.KindDomain && c.Action == snapshot.Added:
+			d := domains[c.Domain]
+			event.Type, event.Old, event.New = DomainAdded, nil, &d
+		case c.Kind == snapshot.KindDomain && c.Action == snapshot.Removed:
+			d := w.domains[c.Domain]
+			event.Type, event.Old, event.New = DomainRemoved, &d, nil
+		default:
+			event.Type = eventType(c)
+		}
+		events = append(events, event)
+	}
+
+	// Details of the domains present in both polls
+	for _, d := range snap.Domains {
+		old, ok := w.domains[d.Domain]
+		cur := domains[d.Domain]
+		if !ok || len(domainChanges(&old, &cur)) == 0 {
+			continue
+		}
+		events = append(events, Event{Type: DomainModified, Domain: d.Domain, Time: snap.CreatedAt, Old: &old, New: &cur})
+	}
+
+	return events
+}
+
+// eventType returns the event type of a configuration change.
+func eventType(c snapshot.Change) EventType {
+	for t, change := range changeTypes {
+		if change.kind == c.Kind && change.action == c.Action {
+			return t
+		}
+	}
+	return EventType(string(c.Kind) + "_" + string(c.Action))
+}
+
+// domainChanges describes the differences between the details of a domain.
+func domainChanges(old, cur *porkbun.Domain) []string {
+	var changes []string
+	if old.Status != cur.Status {
+		changes = append(changes, fmt.Sprintf("status %s -> %s", old.Status, cur.Status))
+	}
+	if !old.ExpireDate.Equal(cur.ExpireDate) {
+		changes = append(changes, fmt.Sprintf("expires %s -> %s", old.ExpireDate.Format(time.DateOnly), cur.ExpireDate.Format(time.DateOnly)))
+	}
+	if old.SecurityLock != cur.SecurityLock {
+		changes = append(changes, fmt.Sprintf("security lock %t -> %t", old.SecurityLock, cur.SecurityLock))
+	}
+	if old.WhoisPrivacy != cur.WhoisPrivacy {
+		changes = append(changes, fmt.Sprintf("whois privacy %t -> %t", old.WhoisPrivacy, cur.WhoisPrivacy))
+	}
+	if old.AutoRenew != cur.AutoRenew {
+		changes = append(changes, fmt.Sprintf("auto-renew %t -> %t", old.AutoRenew, cur.AutoRenew))
+	}
+	return changes
+}
+
+// listDomains returns the watched domains of the account.
+func (w *Watcher) listDomains(ctx context.Context) ([]porkbun.Domain, error) {
+	watched := make(map[string]bool, len(w.options.Domains))
+	for _, name := range w.options.Domains {
+		watched[porkbun.NormalizeName(name)] = true
+	}
+
+	all, err := w.domainsAPI.ListAllDomains(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing domains: %w", err)
+	}
+
+	var domains []porkbun.Domain
+	for _, d := range all {
+		d.Domain = strings.ToLower(d.Domain)
+		if len(watched) == 0 || watched[d.Domain] {
+			domains = append(domains, d)
+		}
+	}
+	return domains, nil
+}
+
+// Run polls until the context is cancelled and calls handler for every event, in order. Successful
+// polls are repeated after Interval, failed polls are retried with an exponential backoff.
+func (w *Watcher) Run(ctx context.Context, handler func(Event)) error {
+	failures := 0
+
+	for {
+		events, err := w.Poll(ctx)
+		for _, event := range events {
+			handler(event)
+		}
+
+		wait := w.options.Interval
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			wait = w.backoff(failures)
+			failures++
+			w.options.Logger.Printf("poll failed, retrying in %s: %v", wait, err)
+		} else {
+			failures = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.after(wait):
+		}
+	}
+}
+
+// Events runs the watcher in a goroutine and returns a channel receiving its events. The channel
+// is closed once the context is cancelled.
+func (w *Watcher) Events(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		_ = w.Run(ctx, func(e Event) {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return events
+}
+
+// backoff returns the wait after the given number of consecutive previous failures.
+func (w *Watcher) backoff(failures int) time.Duration {
+	wait := w.options.MinBackoff
+	for i := 0; i < failures; i++ {
+		wait *= 2
+		if wait >= w.options.MaxBackoff {
+			return w.options.MaxBackoff
+		}
+	}
+	return wait
+}
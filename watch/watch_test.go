@@ -0,0 +1,234 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tuzzmaniandevil/porkbun-go"
+	"github.com/tuzzmaniandevil/porkbun-go/porkbuntest"
+	"github.com/tuzzmaniandevil/porkbun-go/snapshot"
+)
+
+func setupServer(t *testing.T) *porkbuntest.Server {
+	server := porkbuntest.NewServer(nil)
+	t.Cleanup(server.Close)
+
+	server.AddDomain(porkbun.Domain{Domain: "example.com"})
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1", TTL: "600"})
+	server.AddDomain(porkbun.Domain{Domain: "example.org"})
+	return server
+}
+
+// eventStrings returns the descriptions of the events.
+func eventStrings(events []Event) []string {
+	var lines []string
+	for _, e := range events {
+		lines = append(lines, e.String())
+	}
+	return lines
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	server := setupServer(t)
+	client := server.Client()
+	ctx := context.Background()
+	w := NewWatcher(client.Dns, client.Domains, nil)
+
+	// The first poll only records the state
+	events, err := w.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	events, err = w.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	// Change everything
+	www := server.Records("example.com")[0]
+	_, err = client.Dns.EditRecord(ctx, "example.com", *www.ID, &porkbun.EditRecord{Name: "www", Type: porkbun.A, Content: "192.0.2.1", TTL: "3600"})
+	assert.NoError(t, err)
+	_, err = client.Dns.CreateRecord(ctx, "example.com", &porkbun.DnsRecord{Type: porkbun.TXT, Content: "hello"})
+	assert.NoError(t, err)
+	_, err = client.Domains.UpdateNameServers(ctx, "example.com", &porkbun.NameServers{"ns1.example.net"})
+	assert.NoError(t, err)
+	_, err = client.Domains.AddDomainUrlForward(ctx, "example.com", &porkbun.UrlForward{Location: "https://example.net", Type: porkbun.Permanent, IncludePath: "no", Wildcard: "no"})
+	assert.NoError(t, err)
+	assert.True(t, server.UpdateDomain(porkbun.Domain{Domain: "example.com", Status: "EXPIRED", AutoRenew: true}))
+	assert.True(t, server.RemoveDomain("example.org"))
+	server.AddDomain(porkbun.Domain{Domain: "example.net"})
+
+	events, err = w.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"~ example.com nameservers curitiba.ns.porkbun.com,fortaleza.ns.porkbun.com,maceio.ns.porkbun.com,salvador.ns.porkbun.com -> ns1.example.net",
+		`~ example.com record www A "192.0.2.1" ttl=600 -> www A "192.0.2.1" ttl=3600`,
+		`+ example.com record @ TXT "hello" ttl=600`,
+		"+ example.com forward @ -> https://example.net (permanent, include path no, wildcard no)",
+		"+ example.net domain",
+		"- example.org domain",
+		"~ example.com domain status ACTIVE -> EXPIRED, auto-renew false -> true",
+	}, eventStrings(events))
+
+	types := make([]EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+		assert.Equal(t, events[0].Time, e.Time)
+	}
+	assert.Equal(t, []EventType{NameServersChanged, RecordModified, RecordAdded, ForwardAdded, DomainAdded, DomainRemoved, DomainModified}, types)
+	assert.Equal(t, int64(*www.ID), events[1].Old.(*snapshot.Record).ID)
+	assert.Equal(t, "example.org", events[5].Old.(*porkbun.Domain).Domain)
+	assert.Equal(t, "EXPIRED", events[6].New.(*porkbun.Domain).Status)
+
+	events, err = w.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestWatcher_PollDomains(t *testing.T) {
+	server := setupServer(t)
+	client := server.Client()
+	ctx := context.Background()
+	w := NewWatcher(client.Dns, client.Domains, &Options{Domains: []string{"Example.org."}})
+
+	_, err := w.Poll(ctx)
+	assert.NoError(t, err)
+
+	server.AddRecord("example.com", porkbun.DnsRecord{Name: "ignored", Type: porkbun.A, Content: "192.0.2.2"})
+	server.AddRecord("example.org", porkbun.DnsRecord{Name: "watched", Type: porkbun.A, Content: "192.0.2.3"})
+	server.AddDomain(porkbun.Domain{Domain: "example.net"})
+
+	events, err := w.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`+ example.org record watched A "192.0.2.3" ttl=600`}, eventStrings(events))
+
+	// A removed watched domain is reported, and none is then left to poll
+	server.RemoveDomain("example.org")
+	events, err = w.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"- example.org domain"}, eventStrings(events))
+
+	events, err = w.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestWatcher_PollErrors(t *testing.T) {
+	server := setupServer(t)
+	ctx := context.Background()
+
+	// Wrong credentials fail the listing
+	client := porkbun.NewClient(&porkbun.Options{ApiKey: "pk1_wrong", SecretApiKey: "sk1_wrong", BaseURL: server.URL})
+	w := NewWatcher(client.Dns, client.Domains, nil)
+	_, err := w.Poll(ctx)
+	assert.Error(t, err)
+	assert.Nil(t, w.last)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	client = server.Client()
+	w = NewWatcher(client.Dns, client.Domains, nil)
+	_, err = w.Poll(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestWatcher_Run(t *testing.T) {
+	server := setupServer(t)
+
+	// The first requests fail with a server error
+	failures := 2
+	failing := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		server.ServeHTTP(rw, r)
+	}))
+	t.Cleanup(failing.Close)
+
+	var logs bytes.Buffer
+	client := porkbun.NewClient(&porkbun.Options{
+		ApiKey:       porkbuntest.DefaultApiKey,
+		SecretApiKey: porkbuntest.DefaultSecretApiKey,
+		BaseURL:      failing.URL,
+	})
+	w := NewWatcher(client.Dns, client.Domains, &Options{
+		Interval:   time.Minute,
+		MinBackoff: time.Second,
+		MaxBackoff: 4 * time.Second,
+		Logger:     log.New(&logs, "", 0),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	w.after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		switch len(waits) {
+		case 3:
+			server.AddRecord("example.com", porkbun.DnsRecord{Name: "new", Type: porkbun.A, Content: "192.0.2.2"})
+		case 5:
+			cancel()
+		}
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+
+	var events []Event
+	err := w.Run(ctx, func(e Event) { events = append(events, e) })
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// Two failures back off exponentially, then successes wait for the interval
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, time.Minute, time.Minute, time.Minute}, waits)
+	assert.Equal(t, []string{`+ example.com record new A "192.0.2.2" ttl=600`}, eventStrings(events))
+	assert.Contains(t, logs.String(), "poll failed, retrying in 1s")
+}
+
+func TestWatcher_Events(t *testing.T) {
+	server := setupServer(t)
+	client := server.Client()
+	w := NewWatcher(client.Dns, client.Domains, nil)
+
+	polled := make(chan struct{})
+	w.after = func(d time.Duration) <-chan time.Time {
+		polled <- struct{}{}
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := w.Events(ctx)
+
+	<-polled
+	server.AddRecord("example.org", porkbun.DnsRecord{Type: porkbun.MX, Content: "mx.example.net", Prio: "10"})
+
+	// The event is delivered before the watcher waits again
+	e := <-events
+	assert.Equal(t, RecordAdded, e.Type)
+	assert.Equal(t, "example.org", e.Domain)
+
+	cancel()
+	<-polled
+	for range events {
+	}
+}
+
+func TestWatcher_Backoff(t *testing.T) {
+	w := NewWatcher(nil, nil, &Options{MinBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	assert.Equal(t, time.Second, w.backoff(0))
+	assert.Equal(t, 4*time.Second, w.backoff(2))
+	assert.Equal(t, 5*time.Second, w.backoff(3))
+
+	w = NewWatcher(nil, nil, nil)
+	assert.Equal(t, DefaultInterval, w.options.Interval)
+	assert.Equal(t, DefaultMinBackoff, w.backoff(0))
+	assert.Equal(t, DefaultMaxBackoff, w.backoff(100))
+}